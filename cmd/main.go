@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/AtoyanMikhail/SubscribtionAggregation/docs" // swagger docs
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/handlers"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/metrics"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository/postgres"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/server"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/tracing"
 )
 
 // @title Subscription Management API
@@ -21,46 +29,135 @@ import (
 
 // @schemes http https
 
-func main() {
-	// Initialize logger
-	logger.Initialize(os.Stdout)
-	log := logger.Global()
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT as "Bearer <token>". The token subject is the user ID.
 
+func main() {
 	// Load configuration
 	cfg, err := config.GetConfig("configs/app/config_local.yaml")
 	if err != nil {
-		log.Fatal("failed to load configuration", logger.Error(err))
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %s\n", err)
+		os.Exit(1)
 	}
 
-	fmt.Println(cfg)
+	// Initialize logger
+	if err := logger.Setup(cfg.Logging); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logger: %s\n", err)
+		os.Exit(1)
+	}
+	log := logger.Global()
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal("failed to initialize tracing", logger.Error(err))
+	}
 
 	// Connect to database
 	db, err := postgres.NewPostgresConnection(&cfg.Database)
 	if err != nil {
 		log.Fatal("failed to connect to database", logger.Error(err))
 	}
-	defer db.Close()
 
-	// Initialize repository
-	subscriptionRepo := postgres.NewSubscriptionsRepository(db)
+	// Initialize metrics
+	appMetrics := metrics.New()
+	appMetrics.RegisterDBStats(db.DB, cfg.Database.DBName)
+	appMetrics.RegisterSubscriptionStats(postgres.NewStatsRepository(db))
+
+	// Initialize repositories
+	subscriptionRepo := metrics.InstrumentSubscriptionsRepository(postgres.NewSubscriptionsRepository(db), appMetrics)
+	servicesRepo := postgres.NewServicesRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
+	exchangeRatesRepo := postgres.NewExchangeRatesRepository(db)
+	healthRepo := postgres.NewHealthRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
 
 	// Run migrations
 	if err := subscriptionRepo.RunMigrations("migrations"); err != nil {
 		log.Fatal("failed to run migrations", logger.Error(err))
 	}
+	migrationVersion, err := postgres.LatestMigrationVersion("migrations")
+	if err != nil {
+		log.Fatal("failed to read migrations", logger.Error(err))
+	}
+
+	// Initialize services
+	subscriptionService := metrics.InstrumentSubscriptionService(
+		tracing.InstrumentSubscriptionService(service.NewSubscriptionService(subscriptionRepo, servicesRepo)),
+		appMetrics)
+	catalogService := service.NewCatalogService(servicesRepo)
+	auditService := service.NewAuditService(auditRepo)
+	exchangeRateService := service.NewExchangeRateService(exchangeRatesRepo)
+	healthService := service.NewHealthService(healthRepo, migrationVersion, cfg.Server.ReadinessTimeout)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.Lease)
 
-	// Initialize service
-	subscriptionService := service.NewSubscriptionService(subscriptionRepo)
+	// Initialize authentication
+	var verifier *auth.Verifier
+	if cfg.Auth.Enabled {
+		verifier, err = auth.NewVerifier(cfg.Auth)
+		if err != nil {
+			log.Fatal("failed to initialize authentication", logger.Error(err))
+		}
+	} else {
+		log.Warn("authentication is disabled, API requests are not checked")
+	}
 
 	// Setup router
-	router := handlers.SetupRouter(subscriptionService)
+	router := handlers.SetupRouter(subscriptionService, catalogService, auditService, exchangeRateService, healthService, idempotencyService, appMetrics, verifier, cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Delete expired idempotency keys in the background until shutdown
+	cleanupCtx, stopCleanup := context.WithCancel(ctx)
+	cleanupDone := make(chan struct{})
+	go func() {
+		defer close(cleanupDone)
+		deleteExpiredIdempotencyKeys(cleanupCtx, idempotencyService, cfg.Idempotency.CleanupInterval)
+	}()
+
+	// Start server, shutting down on SIGINT or SIGTERM. The cleanup is stopped before the
+	// database is closed, so that a running deletion doesn't use a closed connection.
+	srv := server.New(cfg.Server, router)
+	srv.OnShutdown("idempotency cleanup", func(ctx context.Context) error {
+		stopCleanup()
+		select {
+		case <-cleanupDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	srv.OnShutdown("tracing", shutdownTracing)
+	srv.OnShutdown("database", func(context.Context) error {
+		return subscriptionRepo.Close()
+	})
+
+	if err := srv.Run(ctx); err != nil {
+		log.Error("server stopped with error", logger.Error(err))
+		_ = log.Sync()
+		os.Exit(1)
+	}
+
+	log.Info("server stopped")
+	_ = log.Sync()
+}
 
-	// Start server
-	serverAddr := cfg.Server.Host + ":" + cfg.Server.Port
-	log.Info("starting HTTP server",
-		logger.String("address", serverAddr))
+// deleteExpiredIdempotencyKeys periodically removes the expired responses of idempotent requests
+// until the context is cancelled
+func deleteExpiredIdempotencyKeys(ctx context.Context, idempotencyService service.IdempotencyService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	if err := router.Run(serverAddr); err != nil {
-		log.Fatal("failed to start server", logger.Error(err))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Failures are logged by the service, the next tick retries
+			_ = idempotencyService.DeleteExpired(ctx)
+		}
 	}
 }
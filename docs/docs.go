@@ -15,9 +15,49 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/subscriptions": {
-            "post": {
-                "description": "Create a new subscription for a user",
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the current level of the application logger",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get log level",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.LogLevelResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the level of the application logger without a restart. The change is not persisted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,83 +65,111 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Create a new subscription",
+                "summary": "Set log level",
                 "parameters": [
                     {
-                        "description": "Subscription data",
-                        "name": "subscription",
+                        "description": "New log level",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/CreateSubscriptionRequest"
+                            "$ref": "#/definitions/internal_handlers.LogLevelRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/SubscriptionResponse"
+                            "$ref": "#/definitions/internal_handlers.LogLevelResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subscriptions/cost": {
+        "/api/v1/admin/audit": {
             "get": {
-                "description": "Calculate total cost of chosen subscriptions for a user within a specified period using query parameters",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List recorded subscription changes of all users, newest first. Pass next_before_id of the response as before_id to get the next page. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "audit"
                 ],
-                "summary": "Calculate total subscription cost (query params)",
+                "summary": "List audit log entries",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
+                        "description": "Owner of the subscriptions",
                         "name": "user_id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start date in MM-YYYY format",
-                        "name": "start_date",
-                        "in": "query",
-                        "required": true
+                        "description": "Token subject that made the change",
+                        "name": "actor",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date in MM-YYYY format",
-                        "name": "end_date",
-                        "in": "query",
-                        "required": true
+                        "description": "create, update, delete or price_change",
+                        "name": "operation",
+                        "in": "query"
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "Service names to filter (comma-separated)",
-                        "name": "service_names",
+                        "type": "string",
+                        "description": "Changes made on or after this date (YYYY-MM-DD or MM-YYYY)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Changes made on or before this date (YYYY-MM-DD or MM-YYYY)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "next_before_id of the previous page",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 50)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -109,122 +177,215 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/CostResponse"
+                            "$ref": "#/definitions/ListAuditEntriesResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subscriptions/user/{user_id}": {
+        "/api/v1/admin/exchange-rates": {
             "get": {
-                "description": "Get all subscriptions for a specific user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the exchange rates cost reports convert with, ordered by currency pair",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
+                ],
+                "summary": "List exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ListExchangeRatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/exchange-rates/{base}/{quote}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Insert or replace how many units of the quote currency one unit of the base currency is worth. Cost reports converting from the quote to the base currency use the inverse rate unless that pair has a rate of its own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
                 ],
-                "summary": "Get all user subscriptions",
+                "summary": "Set an exchange rate",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "User ID",
-                        "name": "user_id",
+                        "example": "USD",
+                        "description": "Base currency, ISO 4217",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Quote currency, ISO 4217",
+                        "name": "quote",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Rate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SetExchangeRateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ListSubscriptionsResponse"
+                            "$ref": "#/definitions/ExchangeRateResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/subscriptions/{user_id}/{subscription_id}": {
+        "/api/v1/services": {
             "get": {
-                "description": "Get a specific subscription for a user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List services catalog entries ordered by name",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "services"
                 ],
-                "summary": "Get a subscription by ID",
+                "summary": "List catalog services",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "subscription_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only services of this category",
+                        "name": "category",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/SubscriptionResponse"
+                            "$ref": "#/definitions/ListServicesResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update an existing subscription for a user",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a services catalog entry with a canonical name, aliases, a category and a default price. Names and aliases are unique ignoring case.",
                 "consumes": [
                     "application/json"
                 ],
@@ -232,85 +393,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "services"
                 ],
-                "summary": "Update a subscription",
+                "summary": "Create a catalog service",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "subscription_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated subscription data",
-                        "name": "subscription",
+                        "description": "Service data",
+                        "name": "service",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/UpdateSubscriptionRequest"
+                            "$ref": "#/definitions/CreateServiceRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/SubscriptionResponse"
+                            "$ref": "#/definitions/ServiceResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a specific subscription for a user",
+            }
+        },
+        "/api/v1/services/resolve": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Find the catalog entry whose canonical name or alias matches the given name, ignoring case",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "services"
                 ],
-                "summary": "Delete a subscription",
+                "summary": "Resolve a service name",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "subscription_id",
-                        "in": "path",
+                        "description": "Service name or alias",
+                        "name": "name",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -318,150 +475,2225 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/SuccessResponse"
+                            "$ref": "#/definitions/ServiceResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/ProblemResponse"
                         }
                     }
                 }
             }
         },
-        "/health": {
+        "/api/v1/services/{service_id}": {
             "get": {
-                "description": "Check if the service is running",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a services catalog entry with its aliases",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "services"
                 ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/SuccessResponse"
-                        }
+                "summary": "Get a catalog service by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "service_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ServiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a services catalog entry. The given aliases replace the existing ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Update a catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "service_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated service data",
+                        "name": "service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ServiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a services catalog entry. Linked subscriptions keep their service name and lose the link.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Delete a catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "service_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new subscription for a user. Requests with an Idempotency-Key header may be retried: the response of the first request with the key is replayed with the Idempotent-Replayed header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create a new subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription data",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateSubscriptionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries safe, up to 255 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is still being processed",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "The body of a request with an Idempotency-Key is larger than 64 KiB",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was already used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/cost": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Calculate total cost of chosen subscriptions for a user within a specified period using query parameters. With format, or an Accept header, of csv, xlsx or ndjson the report is downloaded as a file: the breakdown, the price segments, the groups and time series of grouped reports and the totals are separate tables in CSV and separate sheets in XLSX, and NDJSON rows name their table in the table field. CSV numbers and dates follow the locale.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Calculate total subscription cost (query params)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in YYYY-MM-DD or MM-YYYY format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in YYYY-MM-DD or MM-YYYY format (inclusive)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Service names to filter (comma-separated)",
+                        "name": "service_names",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency to convert the report into (default RUB)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Proration mode: charge_date (default), whole_months or daily_prorated",
+                        "name": "proration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grouping of subtotals: service, month or month,service. Grouped reports include a zero-filled monthly time series",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Response format, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale of CSV numbers and dates such as ru or en-GB, overrides Accept-Language; ISO 8601 dates by default",
+                        "name": "locale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CostResponse"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "Name of the downloaded file, for csv, xlsx and ndjson"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create subscriptions from a CSV file with a header row or from a JSON array of subscriptions, up to 1000 rows. Every row is validated like a created subscription. An atomic import creates the rows in one transaction and only if all of them are valid, a best_effort import creates every valid row. With dry_run the rows are only validated. The report lists the created ID or the errors of every row; 422 is returned with the report when no row was created.",
+                "consumes": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Import subscriptions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "atomic (default) or best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the rows",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Service=service_name,Cost=price",
+                        "description": "Comma-separated header=field pairs naming the field of CSV columns whose header isn't a field name",
+                        "name": "mapping",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV with a header row or a JSON array of CreateSubscriptionRequest objects",
+                        "name": "subscriptions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dry run report",
+                        "schema": {
+                            "$ref": "#/definitions/ImportReportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ImportReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "The body is larger than 5 MiB or has more than 1000 rows",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "The body is neither CSV nor JSON",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No row was created",
+                        "schema": {
+                            "$ref": "#/definitions/ImportReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/user/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List a user's subscriptions with keyset pagination, sorting and filters. Pass next_cursor of the response as cursor to get the next page; a cursor is only valid with the same sort_by and order. With format, or an Accept header, of csv, xlsx or ndjson every subscription matching the filters is downloaded as a file instead, regardless of limit and cursor. CSV numbers and dates follow the locale.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List user subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-100 (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort key: price, name, start_date (default) or end_date",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: asc or desc (default)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "active (started and not ended) or ended as of today",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name prefix, case-insensitive",
+                        "name": "service_name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price, inclusive",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price, inclusive",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active on or after this date (YYYY-MM-DD or MM-YYYY)",
+                        "name": "active_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active on or before this date (YYYY-MM-DD or MM-YYYY)",
+                        "name": "active_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Response format, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale of CSV numbers and dates such as ru or en-GB, overrides Accept-Language; ISO 8601 dates by default",
+                        "name": "locale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ListSubscriptionsResponse"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "Name of the downloaded file, for csv, xlsx and ndjson"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/user/{user_id}/trials-ending": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the user's subscriptions whose free trial ends between today and today plus the window, ordered by trial end date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscriptions with ending trials",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Look-ahead window in days (30d) or weeks (2w), default 30d",
+                        "name": "within",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ListSubscriptionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/{user_id}/{subscription_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a specific subscription for a user. The ETag header carries the version of the subscription; with If-None-Match set to it, 304 is returned while the subscription is unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get a subscription by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "The cached copy is up to date"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing subscription for a user. With If-Match set to the ETag of the subscription, the update is applied only if nobody changed the subscription since.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Update a subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the subscription must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated subscription data",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The subscription was changed since the ETag was read",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a specific subscription for a user. With If-Match set to the ETag of the subscription, it is deleted only if nobody changed it since.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete a subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the subscription must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The subscription was changed since the ETag was read",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change only the fields present in a JSON merge patch (RFC 7396). Null clears a field: optional fields are removed, currency and billing_cycle are reset to their defaults. The patched subscription is validated as a whole, like with PUT. If-Match works as with PUT.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Partially update a subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the subscription must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PatchSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The subscription was changed since the ETag was read",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "The body is not a merge patch",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/{user_id}/{subscription_id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every recorded change of a subscription, oldest first, with the subscription state before and after each change. The history is kept after the subscription is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/{user_id}/{subscription_id}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every price of a subscription ordered by effective date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionPricesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Append a price to the subscription's price history, effective from the given date. An existing entry for the same date is replaced. A change of the current price is a new version of the subscription, sent as the ETag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Add a subscription price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price change",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddSubscriptionPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/SubscriptionPricesResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the service is running. Dependencies are not checked, see /livez and /readyz for probes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Check that the process is running and able to serve HTTP. Dependencies are not checked, so a database outage doesn't get the process restarted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LivenessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Ping the database and check that the schema is at the expected migration version. Responds with 503 and the status of each component when a dependency is not ready.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "AddSubscriptionPriceRequest": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "type": "string",
+                    "example": "2025-09-01"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 499
+                }
+            }
+        },
+        "AuditEntryResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "admin-user"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-08-15T10:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "new_data": {
+                    "description": "Absent for deletions",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/SubscriptionResponse"
+                        }
+                    ]
+                },
+                "old_data": {
+                    "description": "Absent for creations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/SubscriptionResponse"
+                        }
+                    ]
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "3f2a9c0e5b7d41a8c6e1f0d2b4a69788"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "BuildInfoResponse": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string",
+                    "example": "3797ee4"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.24.4"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2025-08-15T10:00:00Z"
+                },
+                "uptime_seconds": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1.2.0"
+                }
+            }
+        },
+        "CostGroup": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "subscriptions_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total_cost": {
+                    "type": "number",
+                    "example": 400
+                }
+            }
+        },
+        "CostPoint": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "total_cost": {
+                    "type": "number",
+                    "example": 400
+                }
+            }
+        },
+        "CostResponse": {
+            "type": "object",
+            "properties": {
+                "breakdown": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/SubscriptionCostBreakdown"
+                    }
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "group_by": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CostGroup"
+                    }
+                },
+                "proration": {
+                    "type": "string",
+                    "example": "charge_date"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "time_series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CostPoint"
+                    }
+                },
+                "total_cost": {
+                    "type": "number",
+                    "example": 4800
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "CostSegment": {
+            "type": "object",
+            "properties": {
+                "charges_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-03-31"
+                },
+                "total_cost": {
+                    "type": "number",
+                    "example": 30
+                }
+            }
+        },
+        "CreateServiceRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Яндекс Плюс",
+                        "Yandex Music"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "default_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "example": 399
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                }
+            }
+        },
+        "CreateSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "billing_cycle": {
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly",
+                        "custom"
+                    ],
+                    "example": "monthly"
+                },
+                "billing_interval_months": {
+                    "description": "Only for custom cycles",
+                    "type": "integer",
+                    "example": 6
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "service_id": {
+                    "description": "Catalog entry, takes precedence over service_name",
+                    "type": "integer",
+                    "example": 1
+                },
+                "service_name": {
+                    "description": "Resolved through catalog aliases",
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-07-20"
+                },
+                "trial_end_date": {
+                    "description": "Last free day",
+                    "type": "string",
+                    "example": "2025-08-19"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "DatabaseStatusResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "database is unreachable"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "status": {
+                    "description": "up or down",
+                    "type": "string",
+                    "example": "up"
+                }
+            }
+        },
+        "ExchangeRateResponse": {
+            "type": "object",
+            "properties": {
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "quote_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 80.5
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
+                }
+            }
+        },
+        "FieldErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Failed rule",
+                    "type": "string",
+                    "example": "uuid4"
+                },
+                "field": {
+                    "description": "JSON or query parameter name, empty for the whole body",
+                    "type": "string",
+                    "example": "user_id"
+                },
+                "message": {
+                    "description": "Human-readable description",
+                    "type": "string",
+                    "example": "must be a valid UUID"
+                },
+                "param": {
+                    "description": "Parameter of the rule, e.g. the minimum for min",
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "ImportReportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Subscriptions created",
+                    "type": "integer",
+                    "example": 2
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "failed": {
+                    "description": "Rows that failed",
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ImportRowResponse"
+                    }
+                },
+                "total": {
+                    "description": "Rows in the import",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "ImportRowResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Error code of a failed row",
+                    "type": "string",
+                    "example": "VALIDATION_FAILED"
+                },
+                "error": {
+                    "description": "Why the row failed",
+                    "type": "string",
+                    "example": "validation failed: price: must be 0 or greater"
+                },
+                "fields": {
+                    "description": "Invalid fields of the row",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/FieldErrorResponse"
+                    }
+                },
+                "id": {
+                    "description": "ID of the created subscription",
+                    "type": "integer",
+                    "example": 42
+                },
+                "row": {
+                    "description": "1-based, not counting the CSV header",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "valid rows weren't created because of a dry run or of failed rows in an atomic import",
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "valid",
+                        "failed"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "ListAuditEntriesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AuditEntryResponse"
+                    }
+                },
+                "next_before_id": {
+                    "description": "Pass as before_id to get the next page",
+                    "type": "integer",
+                    "example": 71
+                }
+            }
+        },
+        "ListExchangeRatesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "exchange_rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ExchangeRateResponse"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "CostResponse": {
+        },
+        "ListServicesResponse": {
             "type": "object",
             "properties": {
-                "breakdown": {
+                "count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "services": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/SubscriptionCostBreakdown"
+                        "$ref": "#/definitions/ServiceResponse"
                     }
+                }
+            }
+        },
+        "ListSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Subscriptions in this response",
+                    "type": "integer",
+                    "example": 5
                 },
-                "end_date": {
+                "next_cursor": {
+                    "description": "Pass as cursor to get the next page",
                     "type": "string",
-                    "example": "12-2025"
+                    "example": "eyJzIjoic3Rh..."
                 },
-                "start_date": {
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/SubscriptionResponse"
+                    }
+                },
+                "total_count": {
+                    "description": "All subscriptions matching the filters",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "LivenessResponse": {
+            "type": "object",
+            "properties": {
+                "build": {
+                    "$ref": "#/definitions/BuildInfoResponse"
+                },
+                "status": {
                     "type": "string",
-                    "example": "01-2025"
+                    "example": "alive"
+                }
+            }
+        },
+        "MigrationsStatusResponse": {
+            "type": "object",
+            "properties": {
+                "dirty": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "total_cost": {
+                "error": {
+                    "type": "string",
+                    "example": "schema is at version 7, expected 8"
+                },
+                "expected_version": {
                     "type": "integer",
-                    "example": 4800
+                    "example": 8
                 },
-                "user_id": {
+                "status": {
+                    "description": "up or down",
                     "type": "string",
-                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                    "example": "up"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 8
                 }
             }
         },
-        "CreateSubscriptionRequest": {
+        "PatchSubscriptionRequest": {
             "type": "object",
-            "required": [
-                "price",
-                "service_name",
-                "start_date",
-                "user_id"
-            ],
             "properties": {
+                "billing_cycle": {
+                    "description": "null resets to monthly",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly",
+                        "custom"
+                    ],
+                    "example": "monthly"
+                },
+                "billing_interval_months": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "currency": {
+                    "description": "null resets to RUB",
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
+                    "description": "null removes the end date",
                     "type": "string",
                     "example": "12-2025"
                 },
                 "price": {
                     "type": "integer",
-                    "minimum": 0,
-                    "example": 400
+                    "example": 799
+                },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
                 },
                 "service_name": {
+                    "description": "Unlinks the catalog entry unless service_id is set too",
                     "type": "string",
-                    "example": "Yandex Plus"
+                    "example": "Netflix Premium"
                 },
                 "start_date": {
                     "type": "string",
                     "example": "07-2025"
                 },
-                "user_id": {
+                "trial_end_date": {
                     "type": "string",
-                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                    "example": "2025-08-19"
                 }
             }
         },
-        "ErrorResponse": {
+        "ProblemResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "code": {
+                    "description": "Stable error code",
+                    "type": "string",
+                    "example": "VALIDATION_FAILED"
+                },
+                "detail": {
+                    "description": "Explanation of this occurrence",
+                    "type": "string",
+                    "example": "user_id: must be a valid UUID"
+                },
+                "fields": {
+                    "description": "Invalid fields of a request that failed validation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/FieldErrorResponse"
+                    }
+                },
+                "instance": {
+                    "description": "Request path",
+                    "type": "string",
+                    "example": "/api/v1/subscriptions"
+                },
+                "request_id": {
+                    "description": "ID of the request for support",
+                    "type": "string",
+                    "example": "3f1c9a1e-2b7d-4c55-9a8e-4d0f1b2c3d4e"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "description": "Short summary of the problem type",
                     "type": "string",
                     "example": "validation failed"
                 },
-                "message": {
+                "type": {
+                    "description": "Identifies the kind of problem",
                     "type": "string",
-                    "example": "invalid user ID format"
+                    "example": "/problems/validation-failed"
                 }
             }
         },
-        "ListSubscriptionsResponse": {
+        "ReadinessComponents": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer",
-                    "example": 5
+                "database": {
+                    "$ref": "#/definitions/DatabaseStatusResponse"
                 },
-                "subscriptions": {
+                "migrations": {
+                    "$ref": "#/definitions/MigrationsStatusResponse"
+                }
+            }
+        },
+        "ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "build": {
+                    "$ref": "#/definitions/BuildInfoResponse"
+                },
+                "components": {
+                    "$ref": "#/definitions/ReadinessComponents"
+                },
+                "status": {
+                    "description": "ready or not_ready",
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "ServiceResponse": {
+            "type": "object",
+            "properties": {
+                "aliases": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/SubscriptionResponse"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "Яндекс Плюс",
+                        "Yandex Music"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
+                },
+                "default_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "example": 399
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
+                }
+            }
+        },
+        "SetExchangeRateRequest": {
+            "type": "object",
+            "properties": {
+                "rate": {
+                    "description": "Units of the quote currency one unit of the base currency is worth",
+                    "type": "number",
+                    "example": 80.5
                 }
             }
         },
         "SubscriptionCostBreakdown": {
             "type": "object",
             "properties": {
-                "monthly_price": {
+                "billing_cycle": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "billing_interval_months": {
                     "type": "integer",
-                    "example": 599
+                    "example": 6
+                },
+                "charges_count": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "converted_cost": {
+                    "type": "number",
+                    "example": 4830
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "exchange_rate": {
+                    "type": "number",
+                    "example": 80.5
                 },
                 "months_count": {
                     "type": "integer",
                     "example": 6
                 },
+                "price": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CostSegment"
+                    }
+                },
                 "service_name": {
                     "type": "string",
                     "example": "Netflix"
@@ -471,14 +2703,85 @@ const docTemplate = `{
                     "example": 1
                 },
                 "total_cost": {
+                    "type": "number",
+                    "example": 60
+                },
+                "trial_charges_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "trial_end_date": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "trial_months_count": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "SubscriptionHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AuditEntryResponse"
+                    }
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "SubscriptionPriceResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-08-15T10:00:00Z"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "2025-09-01"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 499
+                }
+            }
+        },
+        "SubscriptionPricesResponse": {
+            "type": "object",
+            "properties": {
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/SubscriptionPriceResponse"
+                    }
+                },
+                "subscription_id": {
                     "type": "integer",
-                    "example": 3594
+                    "example": 1
                 }
             }
         },
         "SubscriptionResponse": {
             "type": "object",
             "properties": {
+                "billing_cycle": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "billing_interval_months": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
                     "type": "string",
                     "example": "2025-12-31T23:59:59Z"
@@ -491,6 +2794,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 400
                 },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "service_name": {
                     "type": "string",
                     "example": "Yandex Plus"
@@ -499,9 +2806,18 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-07-01T00:00:00Z"
                 },
+                "trial_end_date": {
+                    "type": "string",
+                    "example": "2025-08-19T00:00:00Z"
+                },
                 "user_id": {
                     "type": "string",
                     "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "version": {
+                    "description": "Incremented by every update, also sent as the ETag",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
@@ -514,32 +2830,121 @@ const docTemplate = `{
                 }
             }
         },
+        "UpdateServiceRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Replaces existing aliases",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Яндекс Плюс",
+                        "Yandex Music"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "default_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "example": 399
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                }
+            }
+        },
         "UpdateSubscriptionRequest": {
             "type": "object",
-            "required": [
-                "price",
-                "service_name",
-                "start_date"
-            ],
             "properties": {
+                "billing_cycle": {
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly",
+                        "custom"
+                    ],
+                    "example": "monthly"
+                },
+                "billing_interval_months": {
+                    "description": "Only for custom cycles",
+                    "type": "integer",
+                    "example": 6
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
                     "type": "string",
                     "example": "12-2025"
                 },
                 "price": {
                     "type": "integer",
-                    "minimum": 0,
                     "example": 599
                 },
+                "service_id": {
+                    "description": "Catalog entry, takes precedence over service_name",
+                    "type": "integer",
+                    "example": 1
+                },
                 "service_name": {
+                    "description": "Resolved through catalog aliases",
                     "type": "string",
                     "example": "Netflix Premium"
                 },
                 "start_date": {
                     "type": "string",
                     "example": "07-2025"
+                },
+                "trial_end_date": {
+                    "description": "Last free day",
+                    "type": "string",
+                    "example": "2025-08-19"
+                }
+            }
+        },
+        "internal_handlers.LogLevelRequest": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ],
+                    "example": "debug"
                 }
             }
+        },
+        "internal_handlers.LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "info"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "JWT as \"Bearer \u003ctoken\u003e\". The token subject is the user ID.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
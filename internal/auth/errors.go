@@ -0,0 +1,16 @@
+package auth
+
+import "errors"
+
+var (
+	// Token errors
+	ErrMissingToken   = errors.New("missing bearer token")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrMissingSubject = errors.New("token has no subject")
+
+	// Key loading errors
+	ErrNoKeys       = errors.New("no token verification keys configured")
+	ErrInvalidKey   = errors.New("invalid token verification key")
+	ErrInvalidJWKS  = errors.New("invalid JWKS file")
+	ErrUnknownKeyID = errors.New("unknown key ID")
+)
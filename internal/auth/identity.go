@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Subject string   // User ID the token was issued to
+	Roles   []string // Roles granted by the token
+	Admin   bool     // Admins may access every user's data
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the caller's identity
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller's identity, if the request was authenticated
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// CanAccessUser reports whether the caller may access the data of the given user. User IDs are UUIDs
+// and compare ignoring case.
+func (i Identity) CanAccessUser(userID string) bool {
+	return i.Admin || strings.EqualFold(i.Subject, userID)
+}
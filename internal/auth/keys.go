@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keySet holds the keys tokens may be signed with. Keys from a JWKS file are selected by the token's kid,
+// the configured secret and public key are used for tokens without a matching kid.
+type keySet struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	hmacByKID  map[string][]byte
+	rsaByKID   map[string]*rsa.PublicKey
+}
+
+// jwk is one key of a JWKS document. Only RSA and symmetric signing keys are supported.
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	K       string `json:"k"`
+}
+
+func (k *keySet) empty() bool {
+	return k.hmacSecret == nil && k.rsaKey == nil && len(k.hmacByKID) == 0 && len(k.rsaByKID) == 0
+}
+
+// loadRSAPublicKey reads a PEM encoded RSA public key or certificate
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	return key, nil
+}
+
+// loadJWKS reads the signing keys of a local JWKS file into the key set
+func (k *keySet) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJWKS, err)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJWKS, err)
+	}
+
+	k.hmacByKID = make(map[string][]byte)
+	k.rsaByKID = make(map[string]*rsa.PublicKey)
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.KeyType {
+		case "RSA":
+			publicKey, err := key.rsaPublicKey()
+			if err != nil {
+				return fmt.Errorf("%w: key %q: %v", ErrInvalidJWKS, key.KeyID, err)
+			}
+			k.rsaByKID[key.KeyID] = publicKey
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil || len(secret) == 0 {
+				return fmt.Errorf("%w: key %q: invalid secret", ErrInvalidJWKS, key.KeyID)
+			}
+			k.hmacByKID[key.KeyID] = secret
+		}
+	}
+
+	return nil
+}
+
+func (key jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil || len(e) == 0 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// keyFunc picks the verification key of a token by its algorithm and kid
+func (k *keySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if secret, ok := k.hmacByKID[kid]; ok {
+			return secret, nil
+		}
+		if k.hmacSecret != nil {
+			return k.hmacSecret, nil
+		}
+	case jwt.SigningMethodRS256.Alg():
+		if publicKey, ok := k.rsaByKID[kid]; ok {
+			return publicKey, nil
+		}
+		if k.rsaKey != nil {
+			return k.rsaKey, nil
+		}
+	}
+
+	return nil, ErrUnknownKeyID
+}
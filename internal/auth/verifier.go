@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clockSkew is the leeway allowed when checking token expiry and not-before times
+const clockSkew = 30 * time.Second
+
+// Verifier validates HS256 and RS256 bearer tokens and extracts the caller's identity
+type Verifier struct {
+	keys      *keySet
+	parser    *jwt.Parser
+	adminRole string
+}
+
+// claims are the token claims the service relies on. Roles may be given as a list or as a single role.
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	Role  string   `json:"role,omitempty"`
+}
+
+// NewVerifier creates a token verifier with the keys from the auth configuration
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	keys := &keySet{}
+	if cfg.HMACSecret != "" {
+		keys.hmacSecret = []byte(cfg.HMACSecret)
+	}
+	if cfg.RSAPublicKeyFile != "" {
+		publicKey, err := loadRSAPublicKey(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys.rsaKey = publicKey
+	}
+	if cfg.JWKSFile != "" {
+		if err := keys.loadJWKS(cfg.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+	if keys.empty() {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{
+		keys:      keys,
+		parser:    jwt.NewParser(options...),
+		adminRole: cfg.AdminRole,
+	}, nil
+}
+
+// Verify checks the token's signature and claims and returns the identity it was issued to
+func (v *Verifier) Verify(tokenString string) (Identity, error) {
+	if tokenString == "" {
+		return Identity{}, ErrMissingToken
+	}
+
+	var tokenClaims claims
+	if _, err := v.parser.ParseWithClaims(tokenString, &tokenClaims, v.keys.keyFunc); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if tokenClaims.Subject == "" {
+		return Identity{}, ErrMissingSubject
+	}
+
+	roles := tokenClaims.Roles
+	if tokenClaims.Role != "" {
+		roles = append(roles, tokenClaims.Role)
+	}
+
+	return Identity{
+		Subject: tokenClaims.Subject,
+		Roles:   roles,
+		Admin:   v.adminRole != "" && slices.Contains(roles, v.adminRole),
+	}, nil
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecret  = "test-secret"
+	testSubject = "550e8400-e29b-41d4-a716-446655440000"
+)
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": testSubject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{HMACSecret: testSecret, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+
+	noSubject := validClaims()
+	delete(noSubject, "sub")
+
+	admin := validClaims()
+	admin["roles"] = []string{"reader", "admin"}
+
+	singleRole := validClaims()
+	singleRole["role"] = "admin"
+
+	tests := []struct {
+		name  string
+		token string
+		admin bool
+		err   error
+	}{
+		{name: "valid token", token: signHS256(t, testSecret, validClaims())},
+		{name: "admin roles", token: signHS256(t, testSecret, admin), admin: true},
+		{name: "admin role", token: signHS256(t, testSecret, singleRole), admin: true},
+		{name: "missing token", token: "", err: ErrMissingToken},
+		{name: "wrong secret", token: signHS256(t, "other-secret", validClaims()), err: ErrInvalidToken},
+		{name: "expired", token: signHS256(t, testSecret, expired), err: ErrInvalidToken},
+		{name: "no expiry", token: signHS256(t, testSecret, noExpiry), err: ErrInvalidToken},
+		{name: "no subject", token: signHS256(t, testSecret, noSubject), err: ErrMissingSubject},
+		{name: "malformed", token: "not.a.token", err: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := verifier.Verify(tt.token)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testSubject, identity.Subject)
+			assert.Equal(t, tt.admin, identity.Admin)
+		})
+	}
+}
+
+func TestVerifier_RejectsUnsignedTokens(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{HMACSecret: testSecret, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_IssuerAndAudience(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{HMACSecret: testSecret, Issuer: "https://auth.example.com", Audience: "subscriptions", AdminRole: "admin"})
+	require.NoError(t, err)
+
+	claims := validClaims()
+	claims["iss"] = "https://auth.example.com"
+	claims["aud"] = "subscriptions"
+	_, err = verifier.Verify(signHS256(t, testSecret, claims))
+	assert.NoError(t, err)
+
+	claims["aud"] = "billing"
+	_, err = verifier.Verify(signHS256(t, testSecret, claims))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_RS256FromJWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	verifier, err := NewVerifier(config.AuthConfig{JWKSFile: path, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(privateKey)
+		require.NoError(t, err)
+		return signed
+	}
+
+	identity, err := verifier.Verify(sign("key-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, testSubject, identity.Subject)
+
+	_, err = verifier.Verify(sign("key-2"))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// HS256 tokens can't be verified without a symmetric key
+	_, err = verifier.Verify(signHS256(t, testSecret, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewVerifier_NoKeys(t *testing.T) {
+	_, err := NewVerifier(config.AuthConfig{AdminRole: "admin"})
+	assert.ErrorIs(t, err, ErrNoKeys)
+}
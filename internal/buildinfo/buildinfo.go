@@ -0,0 +1,54 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Version and Commit are set at build time:
+//
+//	go build -ldflags "-X github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo.Version=v1.2.0 \
+//	  -X github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+var startTime = time.Now()
+
+// Info describes the running build
+type Info struct {
+	Version   string
+	Commit    string
+	GoVersion string
+	StartedAt time.Time
+	Uptime    time.Duration
+}
+
+// Get returns the build info. Without a commit set at build time the VCS revision recorded by
+// the Go toolchain is used, if any.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    commit(),
+		GoVersion: runtime.Version(),
+		StartedAt: startTime,
+		Uptime:    time.Since(startTime),
+	}
+}
+
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"sync"
+	"time"
 )
 
 var (
@@ -10,13 +11,26 @@ var (
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server" envPrefix:"SERVER_" validate:"required"`
-	Database DatabaseConfig `yaml:"database" envPrefix:"DB_" validate:"required"`
+	Server      ServerConfig      `yaml:"server" envPrefix:"SERVER_" validate:"required"`
+	Database    DatabaseConfig    `yaml:"database" envPrefix:"DB_" validate:"required"`
+	Auth        AuthConfig        `yaml:"auth" envPrefix:"AUTH_"`
+	Tracing     TracingConfig     `yaml:"tracing" envPrefix:"TRACING_"`
+	AccessLog   AccessLogConfig   `yaml:"access_log" envPrefix:"ACCESS_LOG_"`
+	Logging     LoggingConfig     `yaml:"logging" envPrefix:"LOG_"`
+	Errors      ErrorsConfig      `yaml:"errors" envPrefix:"ERRORS_"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" envPrefix:"IDEMPOTENCY_"`
 }
 
 type ServerConfig struct {
 	Port         string   `yaml:"port" env:"PORT" validate:"required,numeric"`
 	Host         string   `yaml:"host" env:"HOST" validate:"required,hostname|ip"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" validate:"min=0"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"READ_HEADER_TIMEOUT" validate:"min=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" validate:"min=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" validate:"min=0"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"min=0"`  // Deadline for draining connections on shutdown
+	ReadinessTimeout  time.Duration `yaml:"readiness_timeout" env:"READINESS_TIMEOUT" validate:"gt=0"` // Deadline for the dependency checks of /readyz
 }
 
 type DatabaseConfig struct {
@@ -27,3 +41,74 @@ type DatabaseConfig struct {
 	DBName   string `yaml:"db_name" env:"NAME" validate:"required"`
 	SSLMode  string `yaml:"ssl_mode" env:"SSL_MODE" validate:"required,oneof=disable require verify-ca verify-full"`
 }
+
+// AuthConfig configures JWT authentication. Tokens are verified with the HMAC secret (HS256),
+// the RSA public key (RS256) or the keys of a local JWKS file, whichever are set.
+type AuthConfig struct {
+	Enabled          bool   `yaml:"enabled" env:"ENABLED"`
+	HMACSecret       string `yaml:"hmac_secret" env:"HMAC_SECRET" validate:"required_if=Enabled true JWKSFile '' RSAPublicKeyFile ''"`
+	RSAPublicKeyFile string `yaml:"rsa_public_key_file" env:"RSA_PUBLIC_KEY_FILE" validate:"omitempty,file"` // PEM encoded
+	JWKSFile         string `yaml:"jwks_file" env:"JWKS_FILE" validate:"omitempty,file"`
+	Issuer           string `yaml:"issuer" env:"ISSUER"`     // Checked when set
+	Audience         string `yaml:"audience" env:"AUDIENCE"` // Checked when set
+	AdminRole        string `yaml:"admin_role" env:"ADMIN_ROLE" validate:"required"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. Spans are exported over OTLP/HTTP, printed to
+// stdout or dropped, while incoming W3C trace context is honoured in every case.
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"EXPORTER" validate:"oneof=none stdout otlp"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTLP_ENDPOINT" validate:"required_if=Exporter otlp"` // host:port of the collector
+	OTLPInsecure bool    `yaml:"otlp_insecure" env:"OTLP_INSECURE"`                                      // Plain HTTP instead of HTTPS
+	ServiceName  string  `yaml:"service_name" env:"SERVICE_NAME" validate:"required"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" validate:"min=0,max=1"` // Share of new traces to record
+}
+
+// AccessLogConfig configures the access log. Successful responses may be sampled, while failed
+// and slow requests are always logged. Headers and JSON bodies are logged only when enabled,
+// with the listed headers and body fields redacted.
+type AccessLogConfig struct {
+	Enabled           bool          `yaml:"enabled" env:"ENABLED"`
+	SuccessSampleRate float64       `yaml:"success_sample_rate" env:"SUCCESS_SAMPLE_RATE" validate:"min=0,max=1"` // Share of 2xx responses to log
+	SlowThreshold     time.Duration `yaml:"slow_threshold" env:"SLOW_THRESHOLD" validate:"min=0"`                 // Slower requests are logged as warnings, 0 disables
+	SkipPaths         []string      `yaml:"skip_paths" env:"SKIP_PATHS"`                                          // Never logged, e.g. probes and metrics
+	LogHeaders        bool          `yaml:"log_headers" env:"LOG_HEADERS"`
+	RedactHeaders     []string      `yaml:"redact_headers" env:"REDACT_HEADERS"`
+	LogBody           bool          `yaml:"log_body" env:"LOG_BODY"`
+	MaxBodySize       int           `yaml:"max_body_size" env:"MAX_BODY_SIZE" validate:"min=0"` // Larger request bodies are not logged
+	RedactBodyFields  []string      `yaml:"redact_body_fields" env:"REDACT_BODY_FIELDS"`        // JSON keys at any depth
+}
+
+// LoggingConfig configures the application logger
+type LoggingConfig struct {
+	Level           string        `yaml:"level" env:"LEVEL" validate:"oneof=debug info warn error"`
+	Encoding        string        `yaml:"encoding" env:"ENCODING" validate:"oneof=json console"`
+	Outputs         []string      `yaml:"outputs" env:"OUTPUTS" validate:"min=1,dive,oneof=stdout stderr file"`
+	File            LogFileConfig `yaml:"file" envPrefix:"FILE_"`
+	Caller          bool          `yaml:"caller" env:"CALLER"`                                                           // Adds the file:line of the call
+	StacktraceLevel string        `yaml:"stacktrace_level" env:"STACKTRACE_LEVEL" validate:"omitempty,oneof=warn error"` // Empty disables stack traces
+}
+
+// LogFileConfig configures the log file output. The file is rotated when it grows over the maximum
+// size or when the rotation interval passes, whichever comes first.
+type LogFileConfig struct {
+	Path             string        `yaml:"path" env:"PATH"`
+	MaxSizeMB        int           `yaml:"max_size_mb" env:"MAX_SIZE_MB" validate:"min=0"`
+	RotationInterval time.Duration `yaml:"rotation_interval" env:"ROTATION_INTERVAL" validate:"min=0"` // 0 rotates by size only
+	MaxAgeDays       int           `yaml:"max_age_days" env:"MAX_AGE_DAYS" validate:"min=0"`           // Older rotated files are removed, 0 keeps them
+	MaxBackups       int           `yaml:"max_backups" env:"MAX_BACKUPS" validate:"min=0"`             // 0 keeps all rotated files
+	Compress         bool          `yaml:"compress" env:"COMPRESS"`
+}
+
+// ErrorsConfig configures error responses. Clients may still request either format with the
+// Accept header while they migrate to problem details.
+type ErrorsConfig struct {
+	Format string `yaml:"format" env:"FORMAT" validate:"oneof=problem legacy"` // RFC 7807 problem details or the legacy ErrorResponse
+}
+
+// IdempotencyConfig configures idempotency keys of subscription creation
+type IdempotencyConfig struct {
+	TTL             time.Duration `yaml:"ttl" env:"TTL" validate:"gt=0"`                           // How long responses are replayed to retries
+	Lease           time.Duration `yaml:"lease" env:"LEASE" validate:"gt=0"`                       // How long a request being processed holds its key
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"CLEANUP_INTERVAL" validate:"gt=0"` // How often expired responses are deleted
+}
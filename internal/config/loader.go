@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"log"
 
@@ -36,6 +37,13 @@ func setDefaults(cfg *Config) {
 	cfg.Server = ServerConfig{
 		Port: "8080",
 		Host: "0.0.0.0",
+
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownTimeout:   20 * time.Second,
+		ReadinessTimeout:  time.Second,
 	}
 
 	cfg.Database = DatabaseConfig{
@@ -46,6 +54,44 @@ func setDefaults(cfg *Config) {
 		DBName:   "subscription_aggregator",
 		SSLMode:  "disable",
 	}
+	cfg.Auth = AuthConfig{
+		Enabled:   true,
+		AdminRole: "admin",
+	}
+	cfg.Tracing = TracingConfig{
+		Exporter:    "none",
+		ServiceName: "subscription-aggregator",
+		SampleRatio: 1,
+	}
+	cfg.AccessLog = AccessLogConfig{
+		Enabled:           true,
+		SuccessSampleRate: 1,
+		SlowThreshold:     time.Second,
+		SkipPaths:         []string{"/health", "/livez", "/readyz", "/metrics"},
+		RedactHeaders:     []string{"Authorization", "Cookie", "X-Api-Key"},
+		MaxBodySize:       4096,
+		RedactBodyFields:  []string{"password", "token", "secret"},
+	}
+	cfg.Logging = LoggingConfig{
+		Level:    "info",
+		Encoding: "json",
+		Outputs:  []string{"stdout"},
+		File: LogFileConfig{
+			Path:       "logs/app.log",
+			MaxSizeMB:  100,
+			MaxAgeDays: 7,
+			MaxBackups: 5,
+		},
+		Caller: true,
+	}
+	cfg.Errors = ErrorsConfig{
+		Format: "problem",
+	}
+	cfg.Idempotency = IdempotencyConfig{
+		TTL:             24 * time.Hour,
+		Lease:           time.Minute,
+		CleanupInterval: time.Hour,
+	}
 }
 
 func loadFromYAML(path string, cfg *Config) error {
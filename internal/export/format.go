@@ -0,0 +1,64 @@
+package export
+
+import (
+	"mime"
+	"strings"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	FormatJSON   Format = "json" // The regular API response, not written by the export writers
+	FormatCSV    Format = "csv"
+	FormatXLSX   Format = "xlsx"
+	FormatNDJSON Format = "ndjson"
+)
+
+// Formats lists the formats a format parameter may name
+var Formats = []Format{FormatJSON, FormatCSV, FormatXLSX, FormatNDJSON}
+
+var mediaTypes = map[Format]string{
+	FormatJSON:   "application/json",
+	FormatCSV:    "text/csv",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// MediaType returns the media type of the format
+func (f Format) MediaType() string {
+	return mediaTypes[f]
+}
+
+// Negotiate chooses the format of a response. The format parameter takes precedence over the
+// Accept header, whose first media type of a file format is used. ok is false for an unknown
+// format parameter.
+func Negotiate(param, accept string) (format Format, ok bool) {
+	if param != "" {
+		for _, f := range Formats {
+			if strings.EqualFold(param, string(f)) {
+				return f, true
+			}
+		}
+		return "", false
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		for f, t := range mediaTypes {
+			if f != FormatJSON && mediaType == t {
+				return f, true
+			}
+		}
+	}
+	return FormatJSON, true
+}
+
+// ContentDisposition returns a Content-Disposition header value that saves the response as a file
+// with the name and the extension of the format
+func ContentDisposition(name string, format Format) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": name + "." + string(format)})
+}
@@ -0,0 +1,51 @@
+package export
+
+import (
+	"strings"
+)
+
+// Locale controls how CSV exports write numbers and dates, so that spreadsheets of the locale
+// open them without an import wizard
+type Locale struct {
+	Separator  rune   // Field separator
+	Decimal    string // Decimal separator
+	DateLayout string
+}
+
+// DefaultLocale writes ISO 8601 dates, the format the API accepts
+var DefaultLocale = Locale{Separator: ',', Decimal: ".", DateLayout: "2006-01-02"}
+
+// locales maps lowercase language tags to their locale. Tags with a region fall back to their
+// language.
+var locales = map[string]Locale{
+	"en":    {Separator: ',', Decimal: ".", DateLayout: "01/02/2006"},
+	"en-gb": {Separator: ',', Decimal: ".", DateLayout: "02/01/2006"},
+	"ru":    {Separator: ';', Decimal: ",", DateLayout: "02.01.2006"},
+	"de":    {Separator: ';', Decimal: ",", DateLayout: "02.01.2006"},
+	"fr":    {Separator: ';', Decimal: ",", DateLayout: "02/01/2006"},
+}
+
+// LookupLocale returns the locale of a language tag such as ru or en-GB
+func LookupLocale(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if locale, ok := locales[tag]; ok {
+		return locale, true
+	}
+	if language, _, ok := strings.Cut(tag, "-"); ok {
+		locale, ok := locales[language]
+		return locale, ok
+	}
+	return Locale{}, false
+}
+
+// AcceptLocale returns the locale of the first supported language of an Accept-Language header,
+// or DefaultLocale if there is none
+func AcceptLocale(header string) Locale {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if locale, ok := LookupLocale(tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writer writes tables of rows to a file. Rows are written as they come, so that exports can be
+// streamed. Values may be nil, strings, ints, float64 or times, which are written as dates.
+type Writer interface {
+	// Sheet starts a table. CSV separates tables with an empty line, XLSX writes them to separate
+	// worksheets and NDJSON writes every row as an object keyed by the column names, with the name
+	// of the table in the "table" field.
+	Sheet(name string, columns ...string) error
+	Row(values ...any) error
+	// Close completes the file, without closing the underlying writer
+	Close() error
+}
+
+// NewWriter creates a writer of the format. The locale only applies to CSV.
+func NewWriter(w io.Writer, format Format, locale Locale) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, locale), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("export format %q has no writer", format)
+	}
+}
+
+// utf8BOM makes spreadsheets detect the encoding of CSV files
+const utf8BOM = "\ufeff"
+
+// formulaPrefixes are the first characters of cells that spreadsheets evaluate as formulas
+const formulaPrefixes = "=+-@"
+
+type csvWriter struct {
+	w       *csv.Writer
+	locale  Locale
+	started bool
+}
+
+func newCSVWriter(w io.Writer, locale Locale) *csvWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = locale.Separator
+	return &csvWriter{w: writer, locale: locale}
+}
+
+func (w *csvWriter) Sheet(_ string, columns ...string) error {
+	if !w.started {
+		w.started = true
+		columns = append([]string{utf8BOM + columns[0]}, columns[1:]...)
+	} else if err := w.w.Write(nil); err != nil {
+		return err
+	}
+	return w.w.Write(columns)
+}
+
+func (w *csvWriter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = escapeFormula(v)
+		case int:
+			record[i] = strconv.Itoa(v)
+		case float64:
+			record[i] = strings.Replace(strconv.FormatFloat(v, 'f', -1, 64), ".", w.locale.Decimal, 1)
+		case time.Time:
+			record[i] = v.Format(w.locale.DateLayout)
+		default:
+			return fmt.Errorf("unsupported export value %T", value)
+		}
+	}
+	return w.w.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// escapeFormula prefixes a string that starts like a formula with a quote, which spreadsheets show
+// as text, so that values entered by users don't run as formulas when the file is opened
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	table   []byte // Encoded name of the table
+	columns []string
+}
+
+func (w *ndjsonWriter) Sheet(name string, columns ...string) error {
+	table, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	w.table = table
+	w.columns = columns
+	return nil
+}
+
+// Row writes the table and the values in the order of the columns, which encoding a map wouldn't
+// keep
+func (w *ndjsonWriter) Row(values ...any) error {
+	w.w.WriteString(`{"table":`)
+	w.w.Write(w.table)
+	for i, value := range values {
+		w.w.WriteByte(',')
+		if t, ok := value.(time.Time); ok {
+			value = t.Format("2006-01-02")
+		}
+		key, err := json.Marshal(w.columns[i])
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		w.w.Write(key)
+		w.w.WriteByte(':')
+		w.w.Write(encoded)
+	}
+	_, err := w.w.WriteString("}\n")
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDate = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func writeTestExport(t *testing.T, format Format, locale Locale) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, locale)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Sheet("Breakdown", "service_name", "price", "start_date", "end_date"))
+	require.NoError(t, w.Row("Netflix, Premium", 599, testDate, nil))
+	require.NoError(t, w.Row("=HYPERLINK(\"http://example.com\")", -1, testDate, nil))
+	require.NoError(t, w.Sheet("Totals", "total_cost"))
+	require.NoError(t, w.Row(1198.5))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	data := writeTestExport(t, FormatCSV, DefaultLocale)
+
+	// Strings that start like formulas are escaped, negative numbers are not
+	assert.Equal(t, "\ufeffservice_name,price,start_date,end_date\n"+
+		"\"Netflix, Premium\",599,2025-03-01,\n"+
+		"\"'=HYPERLINK(\"\"http://example.com\"\")\",-1,2025-03-01,\n"+
+		"\n"+
+		"total_cost\n"+
+		"1198.5\n", string(data))
+}
+
+func TestCSVWriter_Locale(t *testing.T) {
+	locale, ok := LookupLocale("ru-RU")
+	require.True(t, ok)
+
+	data := writeTestExport(t, FormatCSV, locale)
+
+	assert.Equal(t, "\ufeffservice_name;price;start_date;end_date\n"+
+		"Netflix, Premium;599;01.03.2025;\n"+
+		"\"'=HYPERLINK(\"\"http://example.com\"\")\";-1;01.03.2025;\n"+
+		"\n"+
+		"total_cost\n"+
+		"1198,5\n", string(data))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	data := writeTestExport(t, FormatNDJSON, DefaultLocale)
+
+	assert.Equal(t, `{"table":"Breakdown","service_name":"Netflix, Premium","price":599,"start_date":"2025-03-01","end_date":null}`+"\n"+
+		`{"table":"Breakdown","service_name":"=HYPERLINK(\"http://example.com\")","price":-1,"start_date":"2025-03-01","end_date":null}`+"\n"+
+		`{"table":"Totals","total_cost":1198.5}`+"\n", string(data))
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := writeTestExport(t, FormatXLSX, DefaultLocale)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[file.Name] = string(content)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "_rels/.rels")
+	assert.Contains(t, parts, "xl/styles.xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Breakdown" sheetId="1" r:id="rId1"/><sheet name="Totals" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, parts["xl/_rels/workbook.xml.rels"], `Target="worksheets/sheet2.xml"`)
+
+	breakdown := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, breakdown, `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">service_name</t></is></c>`)
+	assert.Contains(t, breakdown, `<c r="A2" s="0" t="inlineStr"><is><t xml:space="preserve">Netflix, Premium</t></is></c><c r="B2" s="0"><v>599</v></c><c r="C2" s="2"><v>45717</v></c></row>`)
+	// Inline strings are never evaluated, so they are written as they are
+	assert.Contains(t, breakdown, `<c r="A3" s="0" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://example.com&#34;)</t></is></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet2.xml"], `<c r="A2" s="0"><v>1198.5</v></c>`)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumn(0))
+	assert.Equal(t, "Z", xlsxColumn(25))
+	assert.Equal(t, "AA", xlsxColumn(26))
+	assert.Equal(t, "AZ", xlsxColumn(51))
+	assert.Equal(t, "BA", xlsxColumn(52))
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		param, accept string
+		format        Format
+		ok            bool
+	}{
+		{"", "", FormatJSON, true},
+		{"", "*/*", FormatJSON, true},
+		{"", "text/csv; charset=utf-8", FormatCSV, true},
+		{"", "application/json, application/x-ndjson", FormatNDJSON, true},
+		{"XLSX", "text/csv", FormatXLSX, true},
+		{"json", "text/csv", FormatJSON, true},
+		{"pdf", "", "", false},
+	}
+	for _, tt := range tests {
+		format, ok := Negotiate(tt.param, tt.accept)
+		assert.Equal(t, tt.ok, ok, "param %q, accept %q", tt.param, tt.accept)
+		assert.Equal(t, tt.format, format, "param %q, accept %q", tt.param, tt.accept)
+	}
+}
+
+func TestAcceptLocale(t *testing.T) {
+	assert.Equal(t, DefaultLocale, AcceptLocale(""))
+	assert.Equal(t, DefaultLocale, AcceptLocale("ja-JP"))
+	assert.Equal(t, locales["ru"], AcceptLocale("ja, ru-RU;q=0.9, en;q=0.8"))
+	assert.Equal(t, locales["en-gb"], AcceptLocale("en-GB"))
+	assert.Equal(t, locales["en"], AcceptLocale("en-US"))
+}
+
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t, `attachment; filename=subscriptions-2025-03-01.csv`, ContentDisposition("subscriptions-2025-03-01", FormatCSV))
+}
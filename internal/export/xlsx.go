@@ -0,0 +1,185 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxEpoch is day zero of spreadsheet dates
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Cell styles of styles.xml
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleHeader  = 1
+	xlsxStyleDate    = 2
+)
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// xlsxWriter writes a minimal Office Open XML workbook. Worksheets are written to the zip archive
+// as their rows come, the workbook parts that list them once it is closed.
+type xlsxWriter struct {
+	zip    *zip.Writer
+	sheet  *bufio.Writer // Worksheet being written, nil before the first one
+	sheets []string
+	row    int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w)}
+}
+
+func (w *xlsxWriter) Sheet(name string, columns ...string) error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	w.sheets = append(w.sheets, name)
+	part, err := w.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.sheets)))
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(part)
+	w.row = 0
+	w.sheet.WriteString(xlsxHeader)
+	w.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	// The header row stays visible while scrolling
+	w.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" state="frozen"/></sheetView></sheetViews>`)
+	w.sheet.WriteString(`<sheetData>`)
+
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		values[i] = column
+	}
+	return w.writeRow(xlsxStyleHeader, values)
+}
+
+func (w *xlsxWriter) Row(values ...any) error {
+	return w.writeRow(xlsxStyleDefault, values)
+}
+
+func (w *xlsxWriter) writeRow(style int, values []any) error {
+	w.row++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.row)
+	for i, value := range values {
+		ref := xlsxColumn(i) + strconv.Itoa(w.row)
+		switch v := value.(type) {
+		case nil:
+		case string:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			if err := xml.EscapeText(w.sheet, []byte(v)); err != nil {
+				return err
+			}
+			w.sheet.WriteString(`</t></is></c>`)
+		case int:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+		case float64:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		case time.Time:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%d</v></c>`, ref, xlsxStyleDate, xlsxDate(v))
+		default:
+			return fmt.Errorf("unsupported export value %T", value)
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (w *xlsxWriter) endSheet() error {
+	if w.sheet == nil {
+		return nil
+	}
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	err := w.sheet.Flush()
+	w.sheet = nil
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xlsxHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(xlsxHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+
+	for i, name := range w.sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlAttr(name), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		writer, err := w.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, part.content); err != nil {
+			return err
+		}
+	}
+	return w.zip.Close()
+}
+
+// xlsxStyles defines the cell styles: the default, bold headers and dates (built-in format 14)
+const xlsxStyles = xlsxHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xlsxColumn returns the letters of a zero-based column index: A, B, ..., Z, AA, AB, ...
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxDate returns the spreadsheet serial number of the day of t
+func xlsxDate(t time.Time) int {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(xlsxEpoch).Hours() / 24)
+}
+
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler creates a new audit log handler
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetSubscriptionHistory retrieves the change history of a subscription
+// @Summary Get subscription history
+// @Description Get every recorded change of a subscription, oldest first, with the subscription state before and after each change. The history is kept after the subscription is deleted.
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path string true "User ID" format(uuid)
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {object} SubscriptionHistoryResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/{user_id}/{subscription_id}/history [get]
+func (h *AuditHandler) GetSubscriptionHistory(c *gin.Context) {
+	userID := c.Param("user_id")
+	subscriptionIDStr := c.Param("subscription_id")
+
+	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
+		return
+	}
+
+	entries, err := h.auditService.GetSubscriptionHistory(c.Request.Context(), userID, subscriptionID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SubscriptionHistoryResponse{
+		SubscriptionID: subscriptionID,
+		Entries:        AuditEntriesToResponse(entries),
+	})
+}
+
+// ListAuditEntries retrieves a page of the audit log
+// @Summary List audit log entries
+// @Description List recorded subscription changes of all users, newest first. Pass next_before_id of the response as before_id to get the next page. Requires the admin role.
+// @Tags audit
+// @Produce json
+// @Param user_id query string false "Owner of the subscriptions" format(uuid)
+// @Param subscription_id query int false "Subscription ID"
+// @Param actor query string false "Token subject that made the change"
+// @Param operation query string false "create, update, delete or price_change"
+// @Param from query string false "Changes made on or after this date (YYYY-MM-DD or MM-YYYY)"
+// @Param to query string false "Changes made on or before this date (YYYY-MM-DD or MM-YYYY)"
+// @Param before_id query int false "next_before_id of the previous page"
+// @Param limit query int false "Page size, 1-500 (default 50)"
+// @Success 200 {object} ListAuditEntriesResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/audit [get]
+func (h *AuditHandler) ListAuditEntries(c *gin.Context) {
+	var req ListAuditEntriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind list audit entries query", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	page, err := h.auditService.ListAuditEntries(c.Request.Context(), req.ToServiceRequest())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditPageToResponse(page))
+}
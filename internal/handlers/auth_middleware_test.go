@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAuthSecret = "test-secret"
+	testUserID     = "550e8400-e29b-41d4-a716-446655440000"
+	testOtherUser  = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+)
+
+func signTestToken(t *testing.T, secret, subject string, roles ...string) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	if len(roles) > 0 {
+		claims["roles"] = roles
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+// newAuthTestRouter mounts routes that take the user_id from the path, the query and the body, and
+// an admin route, behind AuthMiddleware unless verifier is nil
+func newAuthTestRouter(verifier *auth.Verifier) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	if verifier != nil {
+		router.Use(AuthMiddleware(verifier))
+	}
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	router.GET("/users/:user_id", ok)
+	router.GET("/subscriptions", ok)
+	router.POST("/subscriptions", func(c *gin.Context) {
+		var req struct {
+			UserID string `json:"user_id"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		if !authorizeUser(c, req.UserID) {
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	router.PUT("/admin/exchange-rates", RequireAdmin(), ok)
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: testAuthSecret, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	userToken := signTestToken(t, testAuthSecret, testUserID)
+	adminToken := signTestToken(t, testAuthSecret, testOtherUser, "admin")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		status int
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/users/" + testUserID, status: http.StatusUnauthorized},
+		{name: "token signed with another secret", method: http.MethodGet, path: "/users/" + testUserID, token: signTestToken(t, "other-secret", testUserID), status: http.StatusUnauthorized},
+		{name: "malformed token", method: http.MethodGet, path: "/users/" + testUserID, token: "not-a-jwt", status: http.StatusUnauthorized},
+		{name: "own user_id in path", method: http.MethodGet, path: "/users/" + strings.ToUpper(testUserID), token: userToken, status: http.StatusOK},
+		{name: "foreign user_id in path", method: http.MethodGet, path: "/users/" + testOtherUser, token: userToken, status: http.StatusForbidden},
+		{name: "own user_id in query", method: http.MethodGet, path: "/subscriptions?user_id=" + testUserID, token: userToken, status: http.StatusOK},
+		{name: "foreign user_id in query", method: http.MethodGet, path: "/subscriptions?user_id=" + testOtherUser, token: userToken, status: http.StatusForbidden},
+		{name: "own user_id in body", method: http.MethodPost, path: "/subscriptions", body: `{"user_id":"` + testUserID + `"}`, token: userToken, status: http.StatusOK},
+		{name: "foreign user_id in body", method: http.MethodPost, path: "/subscriptions", body: `{"user_id":"` + testOtherUser + `"}`, token: userToken, status: http.StatusForbidden},
+		{name: "admin with foreign user_id in path", method: http.MethodGet, path: "/users/" + testUserID, token: adminToken, status: http.StatusOK},
+		{name: "admin with foreign user_id in query", method: http.MethodGet, path: "/subscriptions?user_id=" + testUserID, token: adminToken, status: http.StatusOK},
+		{name: "admin with foreign user_id in body", method: http.MethodPost, path: "/subscriptions", body: `{"user_id":"` + testUserID + `"}`, token: adminToken, status: http.StatusOK},
+		{name: "admin route without the admin role", method: http.MethodPut, path: "/admin/exchange-rates", token: userToken, status: http.StatusForbidden},
+		{name: "admin route with the admin role", method: http.MethodPut, path: "/admin/exchange-rates", token: adminToken, status: http.StatusOK},
+	}
+
+	router := newAuthTestRouter(verifier)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="api"`, w.Header().Get("WWW-Authenticate"))
+			}
+			if code, ok := map[int]service.ErrorCode{
+				http.StatusUnauthorized: service.CodeUnauthorized,
+				http.StatusForbidden:    service.CodeForbidden,
+			}[tt.status]; ok {
+				var problem ProblemResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, string(code), problem.Code)
+			}
+		})
+	}
+}
+
+func TestAuthDisabled(t *testing.T) {
+	router := newAuthTestRouter(nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{name: "user route", method: http.MethodGet, path: "/users/" + testOtherUser, status: http.StatusOK},
+		{name: "admin route", method: http.MethodPut, path: "/admin/exchange-rates", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogHandler struct {
+	catalogService service.CatalogService
+}
+
+// NewCatalogHandler creates a new services catalog handler
+func NewCatalogHandler(catalogService service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+	}
+}
+
+// CreateService creates a new catalog entry
+// @Summary Create a catalog service
+// @Description Create a services catalog entry with a canonical name, aliases, a category and a default price. Names and aliases are unique ignoring case.
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body CreateServiceRequest true "Service data"
+// @Success 201 {object} ServiceResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 409 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/services [post]
+func (h *CatalogHandler) CreateService(c *gin.Context) {
+	var req CreateServiceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind create service request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	svc, err := h.catalogService.CreateService(c.Request.Context(), req.ToServiceRequest())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ServiceToResponse(svc))
+}
+
+// GetService retrieves a catalog entry
+// @Summary Get a catalog service by ID
+// @Description Get a services catalog entry with its aliases
+// @Tags services
+// @Produce json
+// @Param service_id path int true "Service ID"
+// @Success 200 {object} ServiceResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/services/{service_id} [get]
+func (h *CatalogHandler) GetService(c *gin.Context) {
+	serviceID, ok := parseServiceID(c)
+	if !ok {
+		return
+	}
+
+	svc, err := h.catalogService.GetService(c.Request.Context(), serviceID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ServiceToResponse(svc))
+}
+
+// ListServices retrieves the catalog
+// @Summary List catalog services
+// @Description List services catalog entries ordered by name
+// @Tags services
+// @Produce json
+// @Param category query string false "Only services of this category"
+// @Success 200 {object} ListServicesResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/services [get]
+func (h *CatalogHandler) ListServices(c *gin.Context) {
+	services, err := h.catalogService.ListServices(c.Request.Context(), c.Query("category"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ServicesToResponse(services))
+}
+
+// ResolveService finds a catalog entry by name or alias
+// @Summary Resolve a service name
+// @Description Find the catalog entry whose canonical name or alias matches the given name, ignoring case
+// @Tags services
+// @Produce json
+// @Param name query string true "Service name or alias"
+// @Success 200 {object} ServiceResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/services/resolve [get]
+func (h *CatalogHandler) ResolveService(c *gin.Context) {
+	svc, err := h.catalogService.ResolveService(c.Request.Context(), c.Query("name"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ServiceToResponse(svc))
+}
+
+// UpdateService updates a catalog entry
+// @Summary Update a catalog service
+// @Description Update a services catalog entry. The given aliases replace the existing ones.
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service_id path int true "Service ID"
+// @Param service body UpdateServiceRequest true "Updated service data"
+// @Success 200 {object} ServiceResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 409 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/services/{service_id} [put]
+func (h *CatalogHandler) UpdateService(c *gin.Context) {
+	serviceID, ok := parseServiceID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind update service request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	svc, err := h.catalogService.UpdateService(c.Request.Context(), serviceID, req.ToServiceRequest())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ServiceToResponse(svc))
+}
+
+// DeleteService deletes a catalog entry
+// @Summary Delete a catalog service
+// @Description Delete a services catalog entry. Linked subscriptions keep their service name and lose the link.
+// @Tags services
+// @Produce json
+// @Param service_id path int true "Service ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/services/{service_id} [delete]
+func (h *CatalogHandler) DeleteService(c *gin.Context) {
+	serviceID, ok := parseServiceID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.catalogService.DeleteService(c.Request.Context(), serviceID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "service deleted successfully",
+	})
+}
+
+// parseServiceID reads the service_id path parameter, writing a 400 response when it isn't an integer
+func parseServiceID(c *gin.Context) (int, bool) {
+	serviceID, err := strconv.Atoi(c.Param("service_id"))
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("invalid service ID", logger.Error(err))
+		respondError(c, service.ErrInvalidServiceID)
+		return 0, false
+	}
+	return serviceID, true
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/requestid"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ProblemMediaType is the media type of RFC 7807 problem details
+	ProblemMediaType = "application/problem+json"
+	// MergePatchMediaType is the media type of RFC 7396 JSON merge patches
+	MergePatchMediaType = "application/merge-patch+json"
+	// CSVMediaType is the media type of CSV imports
+	CSVMediaType = "text/csv"
+	// LegacyErrorMediaType requests errors in the ErrorResponse format when sent in Accept
+	LegacyErrorMediaType = "application/vnd.subscription-aggregator.legacy-error+json"
+
+	ErrorFormatProblem = "problem"
+	ErrorFormatLegacy  = "legacy"
+
+	// problemTypePrefix is prepended to the kebab-cased error code to form the problem type
+	problemTypePrefix = "/problems/"
+
+	errorFormatKey = "error_format"
+)
+
+// apiError is an error response independent of its format
+type apiError struct {
+	status int
+	code   service.ErrorCode
+	title  string // Constant per code
+	detail string // Explanation of this occurrence
+	fields []validation.FieldError
+}
+
+// serviceErrors maps the service errors to their responses. An empty detail is replaced with the error
+// message.
+var serviceErrors = []struct {
+	err    error
+	status int
+	title  string
+	detail string
+}{
+	{service.ErrSubscriptionNotFound, http.StatusNotFound, "subscription not found", "the requested subscription does not exist"},
+	{service.ErrInvalidUserID, http.StatusBadRequest, "invalid user ID", "user ID must be a valid UUID"},
+	{service.ErrInvalidSubscriptionID, http.StatusBadRequest, "invalid subscription ID", "subscription ID must be a positive integer"},
+	{service.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition failed", "the subscription was changed since its ETag was read, fetch it again and retry"},
+	{service.ErrInvalidServiceName, http.StatusBadRequest, "invalid service name", "service name cannot be empty"},
+	{service.ErrInvalidPrice, http.StatusBadRequest, "invalid price", "price must be greater than or equal to zero"},
+	{service.ErrInvalidDateFormat, http.StatusBadRequest, "invalid date format", "date must be in YYYY-MM-DD or MM-YYYY format"},
+	{service.ErrEndDateBeforeStart, http.StatusBadRequest, "invalid date range", "end date must be after start date"},
+	{service.ErrInvalidDateRange, http.StatusBadRequest, "invalid date range", "end date must be after start date"},
+	{service.ErrTrialEndBeforeStart, http.StatusBadRequest, "invalid trial end date", "trial end date must not be before start date"},
+	{service.ErrInvalidDayWindow, http.StatusBadRequest, "invalid window", "window must be a number of days such as 30d or weeks such as 2w, up to a year"},
+	{service.ErrInvalidPriceRange, http.StatusBadRequest, "invalid price range", "min_price must not be greater than max_price"},
+	{service.ErrInvalidCursor, http.StatusBadRequest, "invalid cursor", "cursor must be the next_cursor of a list with the same sort_by and order"},
+	{service.ErrInvalidGroupBy, http.StatusBadRequest, "invalid group_by", "group_by must be service, month or month,service"},
+	{service.ErrServiceNotFound, http.StatusNotFound, "service not found", "the requested service does not exist in the catalog"},
+	{service.ErrServiceAlreadyExists, http.StatusConflict, "service already exists", "another service already uses this name or alias"},
+	{service.ErrInvalidServiceID, http.StatusBadRequest, "invalid service ID", "service ID must be a positive integer"},
+	{service.ErrExchangeRateNotFound, http.StatusUnprocessableEntity, "exchange rate not found", ""},
+	{service.ErrInvalidIdempotencyKey, http.StatusBadRequest, "invalid idempotency key", "Idempotency-Key must be 1 to 255 printable ASCII characters"},
+	{service.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency key reused", "the Idempotency-Key was already used with a different request"},
+	{service.ErrIdempotencyKeyInProgress, http.StatusConflict, "idempotency key in use", "a request with this Idempotency-Key is still being processed, retry later"},
+	{service.ErrInvalidLogLevel, http.StatusBadRequest, "invalid log level", "level must be debug, info, warn or error"},
+	{service.ErrInvalidImport, http.StatusBadRequest, "invalid import", ""},
+	{service.ErrImportTooLarge, http.StatusRequestEntityTooLarge, "import too large", ""},
+	{service.ErrConflict, http.StatusConflict, "conflict", "the resource was changed concurrently, retry the request"},
+	{service.ErrConstraintViolation, http.StatusConflict, "constraint violation", "the change conflicts with existing data"},
+	{service.ErrUnavailable, http.StatusServiceUnavailable, "service unavailable", "the storage is temporarily unavailable, retry later"},
+	{service.ErrTimeout, http.StatusGatewayTimeout, "timeout", "the storage did not respond in time"},
+}
+
+// ErrorFormatMiddleware selects the format of error responses. The Accept header chooses between
+// problem details and the legacy ErrorResponse, falling back to the configured default.
+func ErrorFormatMiddleware(defaultFormat string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := defaultFormat
+		accept := c.GetHeader("Accept")
+		switch {
+		case strings.Contains(accept, LegacyErrorMediaType):
+			format = ErrorFormatLegacy
+		case strings.Contains(accept, ProblemMediaType):
+			format = ErrorFormatProblem
+		}
+		c.Set(errorFormatKey, format)
+
+		c.Next()
+	}
+}
+
+// handleError handles service errors and maps them to appropriate HTTP responses
+func handleError(c *gin.Context, err error) {
+	logger.FromContext(c.Request.Context()).Error("handler error", logger.Error(err))
+
+	respondError(c, err)
+}
+
+// respondError responds with the status and code of a service error
+func respondError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrValidation) {
+		respondValidationError(c, err)
+		return
+	}
+
+	for _, e := range serviceErrors {
+		if !errors.Is(err, e.err) {
+			continue
+		}
+		detail := e.detail
+		if detail == "" {
+			detail = err.Error()
+		}
+		if e.status == http.StatusServiceUnavailable {
+			c.Header("Retry-After", "5")
+		}
+		writeError(c, apiError{status: e.status, code: service.ErrorCodeOf(e.err), title: e.title, detail: detail})
+		return
+	}
+
+	writeError(c, apiError{
+		status: http.StatusInternalServerError,
+		code:   service.CodeInternal,
+		title:  "internal server error",
+		detail: "an unexpected error occurred",
+	})
+}
+
+// respondValidationError responds with 400 and the invalid fields of a request that failed binding
+// or validation
+func respondValidationError(c *gin.Context, err error) {
+	err = validation.FromError(err)
+	writeError(c, apiError{
+		status: http.StatusBadRequest,
+		code:   service.CodeValidationFailed,
+		title:  "validation failed",
+		detail: err.Error(),
+		fields: validation.Fields(err),
+	})
+}
+
+// writeError aborts the request with an error response in the format selected by
+// ErrorFormatMiddleware, problem details by default
+func writeError(c *gin.Context, e apiError) {
+	if c.GetString(errorFormatKey) == ErrorFormatLegacy {
+		c.AbortWithStatusJSON(e.status, ErrorResponse{
+			Error:   e.title,
+			Message: e.detail,
+			Code:    string(e.code),
+			Fields:  FieldErrorsToResponse(e.fields),
+		})
+		return
+	}
+
+	c.Header("Content-Type", ProblemMediaType)
+	c.AbortWithStatusJSON(e.status, ProblemResponse{
+		Type:      problemType(e.code),
+		Title:     e.title,
+		Status:    e.status,
+		Detail:    e.detail,
+		Instance:  c.Request.URL.Path,
+		Code:      string(e.code),
+		RequestID: requestid.FromContext(c.Request.Context()),
+		Fields:    FieldErrorsToResponse(e.fields),
+	})
+}
+
+// RouteNotFound responds with 404 to requests for unknown routes
+func RouteNotFound(c *gin.Context) {
+	writeError(c, apiError{
+		status: http.StatusNotFound,
+		code:   service.CodeRouteNotFound,
+		title:  "route not found",
+		detail: "no route matches " + c.Request.Method + " " + c.Request.URL.Path,
+	})
+}
+
+// respondUnsupportedMediaType responds with 415 to a request body of a media type the endpoint
+// doesn't accept
+func respondUnsupportedMediaType(c *gin.Context, detail string) {
+	writeError(c, apiError{
+		status: http.StatusUnsupportedMediaType,
+		code:   service.CodeUnsupportedMediaType,
+		title:  "unsupported media type",
+		detail: detail,
+	})
+}
+
+func respondRequestTooLarge(c *gin.Context, detail string) {
+	writeError(c, apiError{
+		status: http.StatusRequestEntityTooLarge,
+		code:   service.CodeRequestTooLarge,
+		title:  "request too large",
+		detail: detail,
+	})
+}
+
+// problemType returns the problem type URI of an error code, e.g. /problems/subscription-not-found
+func problemType(code service.ErrorCode) string {
+	return problemTypePrefix + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
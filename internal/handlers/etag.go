@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// subscriptionETag returns the entity tag of a subscription version, e.g. "3"
+func subscriptionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion returns the subscription version an If-Match header requires, 0 if the header is
+// absent or "*". ok is false when the header can't match any version: a weak tag, a tag that isn't
+// a version or a list of several tags.
+func ifMatchVersion(header string) (version int, ok bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false
+	}
+	version, err = strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// noneMatch reports whether an If-None-Match header matches the entity tag. Tags are compared
+// weakly, as RFC 9110 requires for If-None-Match.
+func noneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateHandler struct {
+	exchangeRateService service.ExchangeRateService
+}
+
+// NewExchangeRateHandler creates a new exchange rates handler
+func NewExchangeRateHandler(exchangeRateService service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// ListExchangeRates retrieves the stored exchange rates
+// @Summary List exchange rates
+// @Description List the exchange rates cost reports convert with, ordered by currency pair
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ListExchangeRatesResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/exchange-rates [get]
+func (h *ExchangeRateHandler) ListExchangeRates(c *gin.Context) {
+	rates, err := h.exchangeRateService.ListExchangeRates(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRatesToResponse(rates))
+}
+
+// SetExchangeRate stores the rate of a currency pair
+// @Summary Set an exchange rate
+// @Description Insert or replace how many units of the quote currency one unit of the base currency is worth. Cost reports converting from the quote to the base currency use the inverse rate unless that pair has a rate of its own.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param base path string true "Base currency, ISO 4217" example(USD)
+// @Param quote path string true "Quote currency, ISO 4217" example(RUB)
+// @Param request body SetExchangeRateRequest true "Rate"
+// @Success 200 {object} ExchangeRateResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/exchange-rates/{base}/{quote} [put]
+func (h *ExchangeRateHandler) SetExchangeRate(c *gin.Context) {
+	var req SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind exchange rate request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	rate, err := h.exchangeRateService.SetExchangeRate(c.Request.Context(), &service.SetExchangeRateRequest{
+		BaseCurrency:  c.Param("base"),
+		QuoteCurrency: c.Param("quote"),
+		Rate:          req.Rate,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRateToResponse(rate))
+}
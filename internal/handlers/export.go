@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/export"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// exportPageSize is the page size in which subscription exports are read
+const exportPageSize = 100
+
+// subscriptionExportColumns are named like the fields of CreateSubscriptionRequest
+var subscriptionExportColumns = []string{"id", "service_name", "service_id", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date"}
+
+// negotiateExport chooses the format of a response from the format parameter or the Accept header,
+// and the CSV locale from the locale parameter or the Accept-Language header. Unknown parameter
+// values are rejected with 400.
+func negotiateExport(c *gin.Context, formatParam, localeParam string) (export.Format, export.Locale, bool) {
+	c.Writer.Header().Add("Vary", "Accept, Accept-Language")
+
+	format, ok := export.Negotiate(formatParam, c.GetHeader("Accept"))
+	if !ok {
+		formats := make([]string, len(export.Formats))
+		for i, f := range export.Formats {
+			formats[i] = string(f)
+		}
+		respondValidationError(c, &validation.Error{Fields: []validation.FieldError{{
+			Field:   "format",
+			Code:    "oneof",
+			Message: "must be one of: " + strings.Join(formats, ", "),
+			Param:   strings.Join(formats, " "),
+		}}})
+		return "", export.Locale{}, false
+	}
+
+	if localeParam == "" {
+		return format, export.AcceptLocale(c.GetHeader("Accept-Language")), true
+	}
+	locale, ok := export.LookupLocale(localeParam)
+	if !ok {
+		respondValidationError(c, &validation.Error{Fields: []validation.FieldError{{
+			Field:   "locale",
+			Code:    "locale",
+			Message: "must be a supported language tag such as en, en-GB, ru, de or fr",
+		}}})
+		return "", export.Locale{}, false
+	}
+	return format, locale, true
+}
+
+// startExport sends the headers of a file download and returns the writer of its body
+func startExport(c *gin.Context, format export.Format, locale export.Locale, filename string) (export.Writer, error) {
+	w, err := export.NewWriter(c.Writer, format, locale)
+	if err != nil {
+		return nil, err
+	}
+	c.Header("Content-Type", format.MediaType())
+	c.Header("Content-Disposition", export.ContentDisposition(filename, format))
+	return w, nil
+}
+
+func subscriptionExportRow(sub *repository.Subscription) []any {
+	return []any{
+		sub.ID,
+		sub.ServiceName,
+		optionalInt(sub.ServiceID),
+		sub.Price,
+		sub.Currency,
+		sub.BillingCycle,
+		optionalInt(sub.BillingIntervalMonths),
+		sub.UserID,
+		sub.StartDate,
+		optionalDate(sub.EndDate),
+		optionalDate(sub.TrialEndDate),
+	}
+}
+
+// writeCostExport writes the breakdown, the price segments of the subscriptions, the groups and time
+// series of grouped reports and the totals of a cost report as separate tables
+func writeCostExport(w export.Writer, cost *service.CostResponse) error {
+	err := w.Sheet("Breakdown", "subscription_id", "service_name", "currency", "billing_cycle", "billing_interval_months", "price",
+		"charges_count", "months_count", "trial_end_date", "trial_charges_count", "trial_months_count", "total_cost", "exchange_rate", "converted_cost")
+	if err != nil {
+		return err
+	}
+	for _, item := range cost.Breakdown {
+		var interval any
+		if item.BillingIntervalMonths != 0 {
+			interval = item.BillingIntervalMonths
+		}
+		err := w.Row(item.SubscriptionID, item.ServiceName, item.Currency, item.BillingCycle, interval, item.Price,
+			item.ChargesCount, item.MonthsCount, isoDate(item.TrialEndDate), item.TrialChargesCount, item.TrialMonthsCount, item.TotalCost, item.ExchangeRate, item.ConvertedCost)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.Sheet("Segments", "subscription_id", "price", "from", "to", "charges_count", "total_cost"); err != nil {
+		return err
+	}
+	for _, item := range cost.Breakdown {
+		for _, segment := range item.Segments {
+			if err := w.Row(item.SubscriptionID, segment.Price, isoDate(segment.From), isoDate(segment.To), segment.ChargesCount, segment.TotalCost); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cost.Groups != nil {
+		if err := w.Sheet("Groups", "service_name", "month", "subscriptions_count", "total_cost"); err != nil {
+			return err
+		}
+		for _, group := range cost.Groups {
+			if err := w.Row(group.ServiceName, group.Month, group.SubscriptionsCount, group.TotalCost); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cost.TimeSeries != nil {
+		if err := w.Sheet("TimeSeries", "month", "total_cost"); err != nil {
+			return err
+		}
+		for _, point := range cost.TimeSeries {
+			if err := w.Row(point.Month, point.TotalCost); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The period is written as dates, so that it follows the locale like the other tables
+	var startDate, endDate any = cost.StartDate, cost.EndDate
+	if t, err := service.ParseStartDate(cost.StartDate); err == nil {
+		startDate = t
+	}
+	if t, err := service.ParseEndDate(cost.EndDate); err == nil {
+		endDate = t
+	}
+	if err := w.Sheet("Totals", "user_id", "start_date", "end_date", "currency", "proration", "total_cost"); err != nil {
+		return err
+	}
+	return w.Row(cost.UserID, startDate, endDate, cost.Currency, cost.Proration, cost.TotalCost)
+}
+
+// isoDate returns the date of a YYYY-MM-DD string, or nil if there is none
+func isoDate(s string) any {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+func optionalInt(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func optionalDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/export"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type SubscriptionHandler struct {
@@ -24,48 +29,168 @@ func NewSubscriptionHandler(subscriptionService service.SubscriptionService) *Su
 
 // CreateSubscription creates a new subscription
 // @Summary Create a new subscription
-// @Description Create a new subscription for a user
+// @Description Create a new subscription for a user. Requests with an Idempotency-Key header may be retried: the response of the first request with the key is replayed with the Idempotent-Replayed header.
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param subscription body CreateSubscriptionRequest true "Subscription data"
+// @Param Idempotency-Key header string false "Client-chosen key that makes retries safe, up to 255 characters"
 // @Success 201 {object} SubscriptionResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 201 {string} ETag "Version of the subscription"
+// @Failure 400 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Failure 409 {object} ProblemResponse "A request with the same Idempotency-Key is still being processed"
+// @Failure 413 {object} ProblemResponse "The body of a request with an Idempotency-Key is larger than 64 KiB"
+// @Failure 422 {object} ProblemResponse "The Idempotency-Key was already used with a different request"
+// @Security BearerAuth
 // @Router /api/v1/subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	var req CreateSubscriptionRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Global().Error("failed to bind create subscription request", logger.Error(err))
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "validation failed",
-			Message: err.Error(),
-		})
+		logger.FromContext(c.Request.Context()).Error("failed to bind create subscription request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	// A missing user_id is reported by the validation in the service
+	if req.UserID != "" && !authorizeUser(c, req.UserID) {
 		return
 	}
 
 	subscription, err := h.subscriptionService.CreateSubscription(c.Request.Context(), req.ToServiceRequest())
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
 	response := SubscriptionToResponse(subscription)
+	c.Header("ETag", subscriptionETag(subscription.Version))
 	c.JSON(http.StatusCreated, response)
 }
 
+// maxImportBodySize limits the size of an import body
+const maxImportBodySize = 5 << 20
+
+// ImportSubscriptions creates subscriptions from a CSV or JSON file
+// @Summary Import subscriptions
+// @Description Create subscriptions from a CSV file with a header row or from a JSON array of subscriptions, up to 1000 rows. Every row is validated like a created subscription. An atomic import creates the rows in one transaction and only if all of them are valid, a best_effort import creates every valid row. With dry_run the rows are only validated. The report lists the created ID or the errors of every row; 422 is returned with the report when no row was created.
+// @Tags subscriptions
+// @Accept text/csv
+// @Accept json
+// @Produce json
+// @Param mode query string false "atomic (default) or best_effort" Enums(atomic, best_effort)
+// @Param dry_run query bool false "Only validate the rows"
+// @Param mapping query string false "Comma-separated header=field pairs naming the field of CSV columns whose header isn't a field name" example(Service=service_name,Cost=price)
+// @Param subscriptions body string true "CSV with a header row or a JSON array of CreateSubscriptionRequest objects"
+// @Success 200 {object} ImportReportResponse "Dry run report"
+// @Success 201 {object} ImportReportResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 413 {object} ProblemResponse "The body is larger than 5 MiB or has more than 1000 rows"
+// @Failure 415 {object} ProblemResponse "The body is neither CSV nor JSON"
+// @Failure 422 {object} ImportReportResponse "No row was created"
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/import [post]
+func (h *SubscriptionHandler) ImportSubscriptions(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	var format string
+	switch c.ContentType() {
+	case CSVMediaType:
+		format = service.ImportFormatCSV
+	case binding.MIMEJSON:
+		format = service.ImportFormatJSON
+	default:
+		respondUnsupportedMediaType(c, "the body must be sent as "+CSVMediaType+" or "+binding.MIMEJSON)
+		return
+	}
+
+	var req ImportSubscriptionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Error("failed to bind import subscriptions query", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	mapping, err := parseImportMapping(req.Mapping)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize))
+	if err != nil {
+		log.Error("failed to read import body", logger.Error(err))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, fmt.Errorf("%w: the body must not exceed %d bytes", service.ErrImportTooLarge, maxImportBodySize))
+			return
+		}
+		respondValidationError(c, err)
+		return
+	}
+
+	serviceReq := req.ToServiceRequest(format, mapping, data)
+	// Callers other than admins may only import their own subscriptions
+	if identity, ok := auth.IdentityFromContext(c.Request.Context()); ok && !identity.Admin {
+		serviceReq.UserID = identity.Subject
+	}
+
+	report, err := h.subscriptionService.ImportSubscriptions(c.Request.Context(), serviceReq)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case report.DryRun:
+		status = http.StatusOK
+	case report.Created == 0:
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, ImportReportToResponse(report))
+}
+
+// parseImportMapping parses the comma-separated header=field pairs of the mapping parameter
+func parseImportMapping(param string) (map[string]string, error) {
+	if param == "" {
+		return nil, nil
+	}
+
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(param, ",") {
+		header, field, ok := strings.Cut(pair, "=")
+		header, field = strings.TrimSpace(header), strings.TrimSpace(field)
+		if !ok || header == "" || field == "" {
+			return nil, fmt.Errorf("%w: mapping must be comma-separated header=field pairs", service.ErrInvalidImport)
+		}
+		mapping[header] = field
+	}
+	return mapping, nil
+}
+
 // GetSubscription retrieves a specific subscription
 // @Summary Get a subscription by ID
-// @Description Get a specific subscription for a user
+// @Description Get a specific subscription for a user. The ETag header carries the version of the subscription; with If-None-Match set to it, 304 is returned while the subscription is unchanged.
 // @Tags subscriptions
 // @Produce json
 // @Param user_id path string true "User ID" format(uuid)
 // @Param subscription_id path int true "Subscription ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} SubscriptionResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 200 {string} ETag "Version of the subscription"
+// @Success 304 "The cached copy is up to date"
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
 // @Router /api/v1/subscriptions/{user_id}/{subscription_id} [get]
 func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 	userID := c.Param("user_id")
@@ -73,17 +198,21 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 
 	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
 	if err != nil {
-		logger.Global().Error("invalid subscription ID", logger.Error(err))
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid subscription ID",
-			Message: "subscription ID must be a valid integer",
-		})
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
 		return
 	}
 
 	subscription, err := h.subscriptionService.GetSubscription(c.Request.Context(), userID, subscriptionID)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
+		return
+	}
+
+	etag := subscriptionETag(subscription.Version)
+	c.Header("ETag", etag)
+	if noneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -93,17 +222,23 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 
 // UpdateSubscription updates an existing subscription
 // @Summary Update a subscription
-// @Description Update an existing subscription for a user
+// @Description Update an existing subscription for a user. With If-Match set to the ETag of the subscription, the update is applied only if nobody changed the subscription since.
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param user_id path string true "User ID" format(uuid)
 // @Param subscription_id path int true "Subscription ID"
+// @Param If-Match header string false "ETag the subscription must still have"
 // @Param subscription body UpdateSubscriptionRequest true "Updated subscription data"
 // @Success 200 {object} SubscriptionResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 412 {object} ProblemResponse "The subscription was changed since the ETag was read"
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
 // @Router /api/v1/subscriptions/{user_id}/{subscription_id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	userID := c.Param("user_id")
@@ -111,45 +246,115 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 
 	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
 	if err != nil {
-		logger.Global().Error("invalid subscription ID", logger.Error(err))
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid subscription ID",
-			Message: "subscription ID must be a valid integer",
-		})
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
+		return
+	}
+
+	version, ok := ifMatchVersion(c.GetHeader("If-Match"))
+	if !ok {
+		respondError(c, service.ErrPreconditionFailed)
 		return
 	}
 
 	var req UpdateSubscriptionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Global().Error("failed to bind update subscription request", logger.Error(err))
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "validation failed",
-			Message: err.Error(),
-		})
+		logger.FromContext(c.Request.Context()).Error("failed to bind update subscription request", logger.Error(err))
+		respondValidationError(c, err)
 		return
 	}
 
-	subscription, err := h.subscriptionService.UpdateSubscription(c.Request.Context(), userID, subscriptionID, req.ToServiceRequest())
+	subscription, err := h.subscriptionService.UpdateSubscription(c.Request.Context(), userID, subscriptionID, req.ToServiceRequest(), version)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
 	response := SubscriptionToResponse(subscription)
+	c.Header("ETag", subscriptionETag(subscription.Version))
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchSubscription partially updates a subscription
+// @Summary Partially update a subscription
+// @Description Change only the fields present in a JSON merge patch (RFC 7396). Null clears a field: optional fields are removed, currency and billing_cycle are reset to their defaults. The patched subscription is validated as a whole, like with PUT. If-Match works as with PUT.
+// @Tags subscriptions
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID" format(uuid)
+// @Param subscription_id path int true "Subscription ID"
+// @Param If-Match header string false "ETag the subscription must still have"
+// @Param subscription body PatchSubscriptionRequest true "Fields to change"
+// @Success 200 {object} SubscriptionResponse
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 412 {object} ProblemResponse "The subscription was changed since the ETag was read"
+// @Failure 415 {object} ProblemResponse "The body is not a merge patch"
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/{user_id}/{subscription_id} [patch]
+func (h *SubscriptionHandler) PatchSubscription(c *gin.Context) {
+	userID := c.Param("user_id")
+	subscriptionIDStr := c.Param("subscription_id")
+
+	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
+		return
+	}
+
+	// Plain JSON is accepted too, as many clients send patches with it
+	if contentType := c.ContentType(); contentType != MergePatchMediaType && contentType != binding.MIMEJSON {
+		c.Header("Accept-Patch", MergePatchMediaType)
+		respondUnsupportedMediaType(c, "the body must be a JSON merge patch sent as "+MergePatchMediaType)
+		return
+	}
+
+	version, ok := ifMatchVersion(c.GetHeader("If-Match"))
+	if !ok {
+		respondError(c, service.ErrPreconditionFailed)
+		return
+	}
+
+	var req PatchSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind patch subscription request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.PatchSubscription(c.Request.Context(), userID, subscriptionID, req.ToServiceRequest(), version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	response := SubscriptionToResponse(subscription)
+	c.Header("ETag", subscriptionETag(subscription.Version))
 	c.JSON(http.StatusOK, response)
 }
 
 // DeleteSubscription deletes a subscription
 // @Summary Delete a subscription
-// @Description Delete a specific subscription for a user
+// @Description Delete a specific subscription for a user. With If-Match set to the ETag of the subscription, it is deleted only if nobody changed it since.
 // @Tags subscriptions
 // @Produce json
 // @Param user_id path string true "User ID" format(uuid)
 // @Param subscription_id path int true "Subscription ID"
+// @Param If-Match header string false "ETag the subscription must still have"
 // @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 412 {object} ProblemResponse "The subscription was changed since the ETag was read"
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
 // @Router /api/v1/subscriptions/{user_id}/{subscription_id} [delete]
 func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 	userID := c.Param("user_id")
@@ -157,17 +362,20 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 
 	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
 	if err != nil {
-		logger.Global().Error("invalid subscription ID", logger.Error(err))
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid subscription ID",
-			Message: "subscription ID must be a valid integer",
-		})
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
+		return
+	}
+
+	version, ok := ifMatchVersion(c.GetHeader("If-Match"))
+	if !ok {
+		respondError(c, service.ErrPreconditionFailed)
 		return
 	}
 
-	err = h.subscriptionService.DeleteSubscription(c.Request.Context(), userID, subscriptionID)
+	err = h.subscriptionService.DeleteSubscription(c.Request.Context(), userID, subscriptionID, version)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
@@ -176,22 +384,129 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 	})
 }
 
-// GetUserSubscriptions retrieves all subscriptions for a user
-// @Summary Get all user subscriptions
-// @Description Get all subscriptions for a specific user
+// GetUserSubscriptions retrieves a page of subscriptions for a user
+// @Summary List user subscriptions
+// @Description List a user's subscriptions with keyset pagination, sorting and filters. Pass next_cursor of the response as cursor to get the next page; a cursor is only valid with the same sort_by and order. With format, or an Accept header, of csv, xlsx or ndjson every subscription matching the filters is downloaded as a file instead, regardless of limit and cursor. CSV numbers and dates follow the locale.
 // @Tags subscriptions
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
 // @Param user_id path string true "User ID" format(uuid)
+// @Param limit query int false "Page size, 1-100 (default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort_by query string false "Sort key: price, name, start_date (default) or end_date"
+// @Param order query string false "Sort order: asc or desc (default)"
+// @Param status query string false "active (started and not ended) or ended as of today"
+// @Param service_name_prefix query string false "Service name prefix, case-insensitive"
+// @Param min_price query int false "Minimum price, inclusive"
+// @Param max_price query int false "Maximum price, inclusive"
+// @Param active_from query string false "Only subscriptions active on or after this date (YYYY-MM-DD or MM-YYYY)"
+// @Param active_to query string false "Only subscriptions active on or before this date (YYYY-MM-DD or MM-YYYY)"
+// @Param format query string false "Response format, overrides the Accept header" Enums(json, csv, xlsx, ndjson)
+// @Param locale query string false "Locale of CSV numbers and dates such as ru or en-GB, overrides Accept-Language; ISO 8601 dates by default"
 // @Success 200 {object} ListSubscriptionsResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 200 {string} Content-Disposition "Name of the downloaded file, for csv, xlsx and ndjson"
+// @Failure 400 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
 // @Router /api/v1/subscriptions/user/{user_id} [get]
 func (h *SubscriptionHandler) GetUserSubscriptions(c *gin.Context) {
 	userID := c.Param("user_id")
 
-	subscriptions, err := h.subscriptionService.GetUserSubscriptions(c.Request.Context(), userID)
+	var req ListSubscriptionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind list subscriptions query", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	format, locale, ok := negotiateExport(c, req.Format, req.Locale)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		h.exportUserSubscriptions(c, req.ToServiceRequest(userID), format, locale)
+		return
+	}
+
+	page, err := h.subscriptionService.ListUserSubscriptions(c.Request.Context(), req.ToServiceRequest(userID))
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
+		return
+	}
+
+	response := SubscriptionsPageToResponse(page)
+	c.JSON(http.StatusOK, response)
+}
+
+// exportUserSubscriptions streams every subscription matching the filters, reading them page by
+// page. Once the file has started, errors can only cut it short.
+func (h *SubscriptionHandler) exportUserSubscriptions(c *gin.Context, req *service.ListSubscriptionsRequest, format export.Format, locale export.Locale) {
+	log := logger.FromContext(c.Request.Context())
+	req.Limit = exportPageSize
+	req.Cursor = ""
+
+	page, err := h.subscriptionService.ListUserSubscriptions(c.Request.Context(), req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	w, err := startExport(c, format, locale, "subscriptions-"+req.UserID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if err := w.Sheet("Subscriptions", subscriptionExportColumns...); err != nil {
+		log.Error("failed to write subscriptions export", logger.Error(err))
+		return
+	}
+	for {
+		for _, sub := range page.Subscriptions {
+			if err := w.Row(subscriptionExportRow(sub)...); err != nil {
+				log.Error("failed to write subscriptions export", logger.Error(err))
+				return
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+
+		req.Cursor = page.NextCursor
+		page, err = h.subscriptionService.ListUserSubscriptions(c.Request.Context(), req)
+		if err != nil {
+			log.Error("failed to read the next page of subscriptions export", logger.Error(err))
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Error("failed to write subscriptions export", logger.Error(err))
+	}
+}
+
+// GetEndingTrials retrieves subscriptions whose free trial ends soon
+// @Summary Get subscriptions with ending trials
+// @Description Get the user's subscriptions whose free trial ends between today and today plus the window, ordered by trial end date
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path string true "User ID" format(uuid)
+// @Param within query string false "Look-ahead window in days (30d) or weeks (2w), default 30d"
+// @Success 200 {object} ListSubscriptionsResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/user/{user_id}/trials-ending [get]
+func (h *SubscriptionHandler) GetEndingTrials(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	subscriptions, err := h.subscriptionService.GetEndingTrials(c.Request.Context(), userID, c.Query("within"))
+	if err != nil {
+		handleError(c, err)
 		return
 	}
 
@@ -199,28 +514,121 @@ func (h *SubscriptionHandler) GetUserSubscriptions(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// AddSubscriptionPrice schedules a price change for a subscription
+// @Summary Add a subscription price
+// @Description Append a price to the subscription's price history, effective from the given date. An existing entry for the same date is replaced. A change of the current price is a new version of the subscription, sent as the ETag.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID" format(uuid)
+// @Param subscription_id path int true "Subscription ID"
+// @Param price body AddSubscriptionPriceRequest true "Price change"
+// @Success 201 {object} SubscriptionPricesResponse
+// @Header 201 {string} ETag "Version of the subscription"
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/{user_id}/{subscription_id}/prices [post]
+func (h *SubscriptionHandler) AddSubscriptionPrice(c *gin.Context) {
+	userID := c.Param("user_id")
+	subscriptionIDStr := c.Param("subscription_id")
+
+	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
+		return
+	}
+
+	var req AddSubscriptionPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind add subscription price request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	prices, version, err := h.subscriptionService.AddSubscriptionPrice(c.Request.Context(), userID, subscriptionID, req.ToServiceRequest())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	response := SubscriptionPricesToResponse(subscriptionID, prices)
+	c.Header("ETag", subscriptionETag(version))
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetSubscriptionPrices retrieves the price history of a subscription
+// @Summary Get subscription price history
+// @Description Get every price of a subscription ordered by effective date
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path string true "User ID" format(uuid)
+// @Param subscription_id path int true "Subscription ID"
+// @Success 200 {object} SubscriptionPricesResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 404 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/{user_id}/{subscription_id}/prices [get]
+func (h *SubscriptionHandler) GetSubscriptionPrices(c *gin.Context) {
+	userID := c.Param("user_id")
+	subscriptionIDStr := c.Param("subscription_id")
+
+	subscriptionID, err := strconv.Atoi(subscriptionIDStr)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("invalid subscription ID", logger.Error(err))
+		respondError(c, service.ErrInvalidSubscriptionID)
+		return
+	}
+
+	prices, err := h.subscriptionService.GetSubscriptionPrices(c.Request.Context(), userID, subscriptionID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	response := SubscriptionPricesToResponse(subscriptionID, prices)
+	c.JSON(http.StatusOK, response)
+}
+
 // CalculateTotalCostQuery calculates total cost using query parameters (alternative endpoint)
 // @Summary Calculate total subscription cost (query params)
-// @Description Calculate total cost of chosen subscriptions for a user within a specified period using query parameters
+// @Description Calculate total cost of chosen subscriptions for a user within a specified period using query parameters. With format, or an Accept header, of csv, xlsx or ndjson the report is downloaded as a file: the breakdown, the price segments, the groups and time series of grouped reports and the totals are separate tables in CSV and separate sheets in XLSX, and NDJSON rows name their table in the table field. CSV numbers and dates follow the locale.
 // @Tags subscriptions
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
 // @Param user_id query string true "User ID" format(uuid)
-// @Param start_date query string true "Start date in MM-YYYY format"
-// @Param end_date query string true "End date in MM-YYYY format"
+// @Param start_date query string true "Start date in YYYY-MM-DD or MM-YYYY format"
+// @Param end_date query string true "End date in YYYY-MM-DD or MM-YYYY format (inclusive)"
 // @Param service_names query []string false "Service names to filter (comma-separated)"
+// @Param currency query string false "ISO 4217 currency to convert the report into (default RUB)"
+// @Param proration query string false "Proration mode: charge_date (default), whole_months or daily_prorated"
+// @Param group_by query string false "Grouping of subtotals: service, month or month,service. Grouped reports include a zero-filled monthly time series"
+// @Param format query string false "Response format, overrides the Accept header" Enums(json, csv, xlsx, ndjson)
+// @Param locale query string false "Locale of CSV numbers and dates such as ru or en-GB, overrides Accept-Language; ISO 8601 dates by default"
 // @Success 200 {object} CostResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 200 {string} Content-Disposition "Name of the downloaded file, for csv, xlsx and ndjson"
+// @Failure 400 {object} ProblemResponse
+// @Failure 422 {object} ProblemResponse
+// @Failure 500 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
 // @Router /api/v1/subscriptions/cost [get]
 func (h *SubscriptionHandler) CalculateTotalCostQuery(c *gin.Context) {
 	var req GetCostRequest
 
 	if err := c.ShouldBindQuery(&req); err != nil {
-		logger.Global().Error("failed to bind cost calculation query", logger.Error(err))
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "validation failed",
-			Message: err.Error(),
-		})
+		logger.FromContext(c.Request.Context()).Error("failed to bind cost calculation query", logger.Error(err))
+		respondValidationError(c, err)
 		return
 	}
 
@@ -234,9 +642,30 @@ func (h *SubscriptionHandler) CalculateTotalCostQuery(c *gin.Context) {
 		}
 	}
 
+	format, locale, ok := negotiateExport(c, req.Format, req.Locale)
+	if !ok {
+		return
+	}
+
 	costResponse, err := h.subscriptionService.CalculateTotalCost(c.Request.Context(), req.ToServiceRequest())
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
+		return
+	}
+
+	if format != export.FormatJSON {
+		w, err := startExport(c, format, locale, "cost-"+costResponse.UserID)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		if err := writeCostExport(w, costResponse); err != nil {
+			logger.FromContext(c.Request.Context()).Error("failed to write cost export", logger.Error(err))
+			return
+		}
+		if err := w.Close(); err != nil {
+			logger.FromContext(c.Request.Context()).Error("failed to write cost export", logger.Error(err))
+		}
 		return
 	}
 
@@ -246,7 +675,7 @@ func (h *SubscriptionHandler) CalculateTotalCostQuery(c *gin.Context) {
 
 // HealthCheck provides a health check endpoint
 // @Summary Health check
-// @Description Check if the service is running
+// @Description Check if the service is running. Dependencies are not checked, see /livez and /readyz for probes.
 // @Tags health
 // @Produce json
 // @Success 200 {object} SuccessResponse
@@ -257,61 +686,3 @@ func HealthCheck(c *gin.Context) {
 		"message": "subscription service is running",
 	})
 }
-
-// handleError handles service errors and maps them to appropriate HTTP responses
-func (h *SubscriptionHandler) handleError(c *gin.Context, err error) {
-	logger.Global().Error("handler error", logger.Error(err))
-
-	switch {
-	case errors.Is(err, service.ErrSubscriptionNotFound):
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error:   "subscription not found",
-			Message: "the requested subscription does not exist",
-		})
-	case errors.Is(err, service.ErrInvalidUserID):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid user ID",
-			Message: "user ID must be a valid UUID",
-		})
-	case errors.Is(err, service.ErrInvalidSubscriptionID):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid subscription ID",
-			Message: "subscription ID must be a positive integer",
-		})
-	case errors.Is(err, service.ErrInvalidServiceName):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid service name",
-			Message: "service name cannot be empty",
-		})
-	case errors.Is(err, service.ErrInvalidPrice):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid price",
-			Message: "price must be greater than or equal to zero",
-		})
-	case errors.Is(err, service.ErrInvalidDateFormat):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid date format",
-			Message: "date must be in MM-YYYY format",
-		})
-	case errors.Is(err, service.ErrEndDateBeforeStart):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid date range",
-			Message: "end date must be after start date",
-		})
-	case errors.Is(err, service.ErrInvalidDateRange):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid date range",
-			Message: "end date must be after start date",
-		})
-	case strings.Contains(err.Error(), "validation failed"):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "validation failed",
-			Message: err.Error(),
-		})
-	default:
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal server error",
-			Message: "an unexpected error occurred",
-		})
-	}
-}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+// NewHealthHandler creates a new probes handler
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Liveness reports that the process is running
+// @Summary Liveness probe
+// @Description Check that the process is running and able to serve HTTP. Dependencies are not checked, so a database outage doesn't get the process restarted.
+// @Tags health
+// @Produce json
+// @Success 200 {object} LivenessResponse
+// @Router /livez [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponse{
+		Status: "alive",
+		Build:  BuildInfoToResponse(buildinfo.Get()),
+	})
+}
+
+// Readiness reports whether the service can handle requests
+// @Summary Readiness probe
+// @Description Ping the database and check that the schema is at the expected migration version. Responds with 503 and the status of each component when a dependency is not ready.
+// @Tags health
+// @Produce json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.healthService.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, ReadinessReportToResponse(report))
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key of a request that may be retried
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retry
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotentBodySize limits the body of a request with an Idempotency-Key, which is buffered to
+// fingerprint it
+const maxIdempotentBodySize = 64 << 10
+
+// IdempotencyMiddleware makes requests with an Idempotency-Key header safe to retry. The response
+// of the first request with a key is stored and replayed to retries with the same request, while
+// reusing the key for a different request is rejected with 422. Keys belong to the authenticated
+// caller. Server errors and panics are not stored, so that the request can be retried.
+func IdempotencyMiddleware(idempotencyService service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				respondRequestTooLarge(c, fmt.Sprintf("the body must not exceed %d bytes", maxIdempotentBodySize))
+				return
+			}
+			respondValidationError(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := ""
+		if identity, ok := auth.IdentityFromContext(c.Request.Context()); ok {
+			scope = identity.Subject
+		}
+
+		reservation, stored, err := idempotencyService.Begin(c.Request.Context(), scope, key, requestFingerprint(c.Request, body))
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		if stored != nil {
+			if stored.ETag != "" {
+				c.Header("ETag", stored.ETag)
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &responseCapture{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// The outcome is recorded even if the client has gone away or the handler panics, otherwise
+		// the key would stay reserved until its lease ends. The panic is passed on to the recovery
+		// middleware.
+		defer func() {
+			recovered := recover()
+			ctx := context.WithoutCancel(c.Request.Context())
+			log := logger.FromContext(ctx)
+			status := c.Writer.Status()
+
+			if recovered != nil || status >= http.StatusInternalServerError {
+				if err := idempotencyService.Release(ctx, scope, key, reservation); err != nil {
+					log.Error("failed to release idempotency key", logger.Error(err))
+				}
+			} else {
+				response := &service.StoredResponse{
+					StatusCode:  status,
+					ContentType: c.Writer.Header().Get("Content-Type"),
+					ETag:        c.Writer.Header().Get("ETag"),
+					Body:        writer.body.Bytes(),
+				}
+				if err := idempotencyService.Complete(ctx, scope, key, reservation, response); err != nil {
+					log.Error("failed to store idempotent response", logger.Error(err))
+				}
+			}
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// requestFingerprint hashes the method, path and body of a request. JSON bodies are hashed in
+// their canonical form, so that retries may format them differently.
+func requestFingerprint(r *http.Request, body []byte) string {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil {
+		if canonical, err := json.Marshal(value); err == nil {
+			body = canonical
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseCapture keeps a copy of the response body as the handler writes it
+type responseCapture struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseCapture) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCapture) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
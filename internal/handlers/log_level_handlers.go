@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LogLevelHandler struct {
+	service service.LogLevelService
+}
+
+// NewLogLevelHandler creates a new instance of log level handler
+func NewLogLevelHandler(service service.LogLevelService) *LogLevelHandler {
+	return &LogLevelHandler{
+		service: service,
+	}
+}
+
+// GetLogLevel returns the current logging level
+// @Summary Get log level
+// @Description Get the current level of the application logger
+// @Tags admin
+// @Produce json
+// @Success 200 {object} LogLevelResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, LogLevelResponse{
+		Level: h.service.Level().String(),
+	})
+}
+
+// SetLogLevel changes the logging level at runtime
+// @Summary Set log level
+// @Description Change the level of the application logger without a restart. The change is not persisted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body LogLevelRequest true "New log level"
+// @Success 200 {object} LogLevelResponse
+// @Failure 400 {object} ProblemResponse
+// @Failure 401 {object} ProblemResponse
+// @Failure 403 {object} ProblemResponse
+// @Security BearerAuth
+// @Router /admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to bind log level request", logger.Error(err))
+		respondValidationError(c, err)
+		return
+	}
+
+	level, err := h.service.SetLevel(c.Request.Context(), req.Level)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LogLevelResponse{
+		Level: level.String(),
+	})
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records the duration of each request labelled by its route template
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.HTTPRequestStarted()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		done(c.Request.Method, route, c.Writer.Status())
+	}
+}
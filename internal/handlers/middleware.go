@@ -1,47 +1,99 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"runtime/debug"
+	"strings"
 	"time"
 
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
-// LoggerMiddleware creates a custom logger middleware
-func LoggerMiddleware() gin.HandlerFunc {
+const redacted = "[REDACTED]"
+
+// AccessLogMiddleware writes a structured log entry for every completed request through the
+// request's logger. Server errors are logged as errors, client errors and slow requests as
+// warnings, and successful requests at info level, sampled by the configured rate.
+func AccessLogMiddleware(cfg config.AccessLogConfig) gin.HandlerFunc {
+	skipPaths := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skipPaths[path] = struct{}{}
+	}
+	redactHeaders := make(map[string]struct{}, len(cfg.RedactHeaders))
+	for _, header := range cfg.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(header)] = struct{}{}
+	}
+	redactFields := make(map[string]struct{}, len(cfg.RedactBodyFields))
+	for _, field := range cfg.RedactBodyFields {
+		redactFields[strings.ToLower(field)] = struct{}{}
+	}
+
 	return func(c *gin.Context) {
+		if _, skip := skipPaths[c.Request.URL.Path]; skip || !cfg.Enabled {
+			c.Next()
+			return
+		}
+
 		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
+
+		var body *bodyCapture
+		if cfg.LogBody && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &bodyCapture{ReadCloser: c.Request.Body, limit: cfg.MaxBodySize}
+			c.Request.Body = body
+		}
 
 		// Process request
 		c.Next()
 
-		// Calculate latency
 		latency := time.Since(start)
+		status := c.Writer.Status()
+		slow := cfg.SlowThreshold > 0 && latency >= cfg.SlowThreshold
 
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
-
-		if raw != "" {
-			path = path + "?" + raw
+		if status >= http.StatusOK && status < http.StatusMultipleChoices && !slow && !sampled(cfg.SuccessSampleRate) {
+			return
 		}
 
-		log := logger.Global()
-		log.Info("HTTP request completed",
-			logger.String("method", method),
-			logger.String("path", path),
-			logger.String("client_ip", clientIP),
-			logger.Int("status_code", statusCode),
+		fields := []logger.Field{
+			logger.String("method", c.Request.Method),
+			logger.String("path", c.Request.URL.Path),
+			logger.String("query", c.Request.URL.RawQuery),
+			logger.Int("status", status),
 			logger.Any("latency", latency),
-			logger.Int("body_size", c.Writer.Size()),
-		)
-
+			logger.String("client_ip", c.ClientIP()),
+			logger.String("user_agent", c.Request.UserAgent()),
+			logger.Any("request_size", c.Request.ContentLength),
+			logger.Int("response_size", c.Writer.Size()),
+		}
+		if slow {
+			fields = append(fields, logger.Any("slow", true))
+		}
+		if cfg.LogHeaders {
+			fields = append(fields, logger.Any("request_headers", redactHeaderValues(c.Request.Header, redactHeaders)))
+		}
+		if body != nil {
+			if value, ok := body.json(redactFields); ok {
+				fields = append(fields, logger.Any("request_body", value))
+			}
+		}
 		if len(c.Errors) > 0 {
-			log.Error("HTTP request errors",
-				logger.String("path", path),
-				logger.Any("errors", c.Errors.String()))
+			fields = append(fields, logger.String("errors", c.Errors.String()))
+		}
+
+		log := logger.FromContext(c.Request.Context())
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("HTTP request completed", fields...)
+		case status >= http.StatusBadRequest || slow:
+			log.Warn("HTTP request completed", fields...)
+		default:
+			log.Info("HTTP request completed", fields...)
 		}
 	}
 }
@@ -51,18 +103,93 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Global().Error("panic recovered in HTTP handler",
+				logger.FromContext(c.Request.Context()).Error("panic recovered in HTTP handler",
 					logger.Any("error", err),
 					logger.String("path", c.Request.URL.Path),
-					logger.String("method", c.Request.Method))
+					logger.String("method", c.Request.Method),
+					logger.String("stack", string(debug.Stack())))
 
-				c.JSON(500, ErrorResponse{
-					Error:   "internal server error",
-					Message: "an unexpected error occurred",
+				writeError(c, apiError{
+					status: http.StatusInternalServerError,
+					code:   service.CodeInternal,
+					title:  "internal server error",
+					detail: "an unexpected error occurred",
 				})
-				c.Abort()
 			}
 		}()
 		c.Next()
 	}
 }
+
+// sampled reports whether an entry should be logged with the given sampling rate
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// redactHeaderValues returns the headers with the values of the redacted ones replaced
+func redactHeaderValues(header http.Header, redact map[string]struct{}) map[string]string {
+	values := make(map[string]string, len(header))
+	for name, value := range header {
+		if _, ok := redact[name]; ok {
+			values[name] = redacted
+			continue
+		}
+		values[name] = strings.Join(value, ", ")
+	}
+	return values
+}
+
+// redactJSON replaces the values of the redacted keys at any depth of a decoded JSON value
+func redactJSON(value interface{}, redact map[string]struct{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := redact[strings.ToLower(key)]; ok {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSON(field, redact)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item, redact)
+		}
+	}
+	return value
+}
+
+// bodyCapture keeps a copy of the first bytes of a request body as the handler reads it
+type bodyCapture struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *bodyCapture) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if remaining := b.limit - b.buf.Len(); remaining < n {
+		b.buf.Write(p[:max(remaining, 0)])
+		b.truncated = true
+	} else {
+		b.buf.Write(p[:n])
+	}
+	return n, err
+}
+
+// json decodes the captured body with the redacted fields masked. Bodies that were truncated or
+// aren't JSON are not logged, since their secrets can't be redacted.
+func (b *bodyCapture) json(redact map[string]struct{}) (interface{}, bool) {
+	if b.truncated || b.buf.Len() == 0 {
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(b.buf.Bytes(), &value); err != nil {
+		return nil, false
+	}
+	return redactJSON(value, redact), true
+}
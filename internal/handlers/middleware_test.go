@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// serveAccessLog sends a request through AccessLogMiddleware and returns the entries it logged
+func serveAccessLog(t *testing.T, cfg config.AccessLogConfig, req *http.Request) []observer.LoggedEntry {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := logger.NewWithCore(core)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), log))
+		c.Next()
+	})
+	router.Use(AccessLogMiddleware(cfg))
+	router.GET("/healthz", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	router.POST("/subscriptions", func(c *gin.Context) {
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusCreated, body)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	return logs.AllUntimed()
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	base := config.AccessLogConfig{
+		Enabled:           true,
+		SuccessSampleRate: 1,
+		SkipPaths:         []string{"/healthz"},
+		MaxBodySize:       1024,
+	}
+
+	tests := []struct {
+		name    string
+		cfg     func(cfg *config.AccessLogConfig)
+		request func() *http.Request
+		logged  bool
+		level   zapcore.Level
+		status  int64
+		check   func(t *testing.T, fields map[string]interface{})
+	}{
+		{
+			name:    "skipped path",
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/healthz", nil) },
+		},
+		{
+			name:    "disabled",
+			cfg:     func(cfg *config.AccessLogConfig) { cfg.Enabled = false },
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/fail", nil) },
+		},
+		{
+			name: "successful request sampled out",
+			cfg:  func(cfg *config.AccessLogConfig) { cfg.SuccessSampleRate = 0 },
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(`{"service_name":"Netflix"}`))
+			},
+		},
+		{
+			name: "successful request",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/healthz?verbose=1", nil)
+			},
+			cfg:    func(cfg *config.AccessLogConfig) { cfg.SkipPaths = nil },
+			logged: true,
+			level:  zapcore.InfoLevel,
+			status: http.StatusOK,
+			check: func(t *testing.T, fields map[string]interface{}) {
+				assert.Equal(t, "GET", fields["method"])
+				assert.Equal(t, "/healthz", fields["path"])
+				assert.Equal(t, "verbose=1", fields["query"])
+				assert.NotContains(t, fields, "slow")
+				assert.NotContains(t, fields, "request_headers")
+				assert.NotContains(t, fields, "request_body")
+			},
+		},
+		{
+			name:    "server error",
+			cfg:     func(cfg *config.AccessLogConfig) { cfg.SuccessSampleRate = 0 },
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/fail", nil) },
+			logged:  true,
+			level:   zapcore.ErrorLevel,
+			status:  http.StatusInternalServerError,
+		},
+		{
+			name:    "client error",
+			cfg:     func(cfg *config.AccessLogConfig) { cfg.SuccessSampleRate = 0 },
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/missing", nil) },
+			logged:  true,
+			level:   zapcore.WarnLevel,
+			status:  http.StatusNotFound,
+		},
+		{
+			name: "slow request",
+			cfg: func(cfg *config.AccessLogConfig) {
+				cfg.SuccessSampleRate = 0
+				cfg.SlowThreshold = 10 * time.Millisecond
+			},
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/slow", nil) },
+			logged:  true,
+			level:   zapcore.WarnLevel,
+			status:  http.StatusOK,
+			check: func(t *testing.T, fields map[string]interface{}) {
+				assert.Equal(t, true, fields["slow"])
+				assert.GreaterOrEqual(t, fields["latency"], 10*time.Millisecond)
+			},
+		},
+		{
+			name: "redacted headers",
+			cfg: func(cfg *config.AccessLogConfig) {
+				cfg.LogHeaders = true
+				cfg.RedactHeaders = []string{"authorization", "X-Api-Key"}
+			},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+				req.Header.Set("Authorization", "Bearer secret")
+				req.Header.Set("X-Api-Key", "secret")
+				req.Header.Add("Accept", "application/json")
+				req.Header.Add("Accept", "text/csv")
+				return req
+			},
+			logged: true,
+			level:  zapcore.ErrorLevel,
+			status: http.StatusInternalServerError,
+			check: func(t *testing.T, fields map[string]interface{}) {
+				assert.Equal(t, map[string]string{
+					"Authorization": "[REDACTED]",
+					"X-Api-Key":     "[REDACTED]",
+					"Accept":        "application/json, text/csv",
+				}, fields["request_headers"])
+			},
+		},
+		{
+			name: "redacted body fields",
+			cfg: func(cfg *config.AccessLogConfig) {
+				cfg.LogBody = true
+				cfg.RedactBodyFields = []string{"password", "Card_Number"}
+			},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/subscriptions",
+					strings.NewReader(`{"service_name":"Netflix","Password":"secret","payment":{"card_number":"4242"},"items":[{"password":"secret"}]}`))
+			},
+			logged: true,
+			level:  zapcore.InfoLevel,
+			status: http.StatusCreated,
+			check: func(t *testing.T, fields map[string]interface{}) {
+				assert.Equal(t, map[string]interface{}{
+					"service_name": "Netflix",
+					"Password":     "[REDACTED]",
+					"payment":      map[string]interface{}{"card_number": "[REDACTED]"},
+					"items":        []interface{}{map[string]interface{}{"password": "[REDACTED]"}},
+				}, fields["request_body"])
+			},
+		},
+		{
+			name: "truncated body",
+			cfg: func(cfg *config.AccessLogConfig) {
+				cfg.LogBody = true
+				cfg.MaxBodySize = 8
+			},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(`{"password":"secret"}`))
+			},
+			logged: true,
+			level:  zapcore.InfoLevel,
+			status: http.StatusCreated,
+			check: func(t *testing.T, fields map[string]interface{}) {
+				assert.NotContains(t, fields, "request_body")
+			},
+		},
+		{
+			name: "body that isn't JSON",
+			cfg:  func(cfg *config.AccessLogConfig) { cfg.LogBody = true },
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(`password=secret`))
+			},
+			logged: true,
+			level:  zapcore.WarnLevel,
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, fields map[string]interface{}) {
+				assert.NotContains(t, fields, "request_body")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+
+			entries := serveAccessLog(t, cfg, tt.request())
+			if !tt.logged {
+				assert.Empty(t, entries)
+				return
+			}
+
+			require.Len(t, entries, 1)
+			entry := entries[0]
+			assert.Equal(t, "HTTP request completed", entry.Message)
+			assert.Equal(t, tt.level, entry.Level)
+
+			fields := entry.ContextMap()
+			assert.Equal(t, tt.status, fields["status"])
+			assert.IsType(t, time.Duration(0), fields["latency"])
+			assert.Positive(t, fields["latency"])
+			if tt.check != nil {
+				tt.check(t, fields)
+			}
+		})
+	}
+}
@@ -1,73 +1,281 @@
 package handlers
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
 )
 
 // HTTP Request/Response models for Swagger documentation
 
 // CreateSubscriptionRequest represents the request body for creating a subscription
 type CreateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" binding:"required" example:"Yandex Plus"`
-	Price       int    `json:"price" binding:"required,min=0" example:"400"`
-	UserID      string `json:"user_id" binding:"required,uuid4" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   string `json:"start_date" binding:"required" example:"07-2025"`
-	EndDate     string `json:"end_date,omitempty" example:"12-2025"`
+	ServiceName           string `json:"service_name" example:"Yandex Plus"` // Resolved through catalog aliases
+	ServiceID             int    `json:"service_id,omitempty" example:"1"`   // Catalog entry, takes precedence over service_name
+	Price                 int    `json:"price" example:"400"`
+	Currency              string `json:"currency,omitempty" example:"RUB"`
+	BillingCycle          string `json:"billing_cycle,omitempty" enums:"weekly,monthly,quarterly,yearly,custom" example:"monthly"`
+	BillingIntervalMonths int    `json:"billing_interval_months,omitempty" example:"6"` // Only for custom cycles
+	UserID                string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate             string `json:"start_date" example:"2025-07-20"`
+	EndDate               string `json:"end_date,omitempty" example:"12-2025"`
+	TrialEndDate          string `json:"trial_end_date,omitempty" example:"2025-08-19"` // Last free day
 } // @name CreateSubscriptionRequest
 
 // UpdateSubscriptionRequest represents the request body for updating a subscription
 type UpdateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" binding:"required" example:"Netflix Premium"`
-	Price       int    `json:"price" binding:"required,min=0" example:"599"`
-	StartDate   string `json:"start_date" binding:"required" example:"07-2025"`
-	EndDate     string `json:"end_date,omitempty" example:"12-2025"`
+	ServiceName           string `json:"service_name" example:"Netflix Premium"` // Resolved through catalog aliases
+	ServiceID             int    `json:"service_id,omitempty" example:"1"`       // Catalog entry, takes precedence over service_name
+	Price                 int    `json:"price" example:"599"`
+	Currency              string `json:"currency,omitempty" example:"RUB"`
+	BillingCycle          string `json:"billing_cycle,omitempty" enums:"weekly,monthly,quarterly,yearly,custom" example:"monthly"`
+	BillingIntervalMonths int    `json:"billing_interval_months,omitempty" example:"6"` // Only for custom cycles
+	StartDate             string `json:"start_date" example:"07-2025"`
+	EndDate               string `json:"end_date,omitempty" example:"12-2025"`
+	TrialEndDate          string `json:"trial_end_date,omitempty" example:"2025-08-19"` // Last free day
 } // @name UpdateSubscriptionRequest
 
+// PatchSubscriptionRequest represents a JSON merge patch of a subscription: omitted fields are kept
+// and null clears a field
+type PatchSubscriptionRequest struct {
+	ServiceName           service.Optional[string] `json:"service_name" swaggertype:"string" example:"Netflix Premium"` // Unlinks the catalog entry unless service_id is set too
+	ServiceID             service.Optional[int]    `json:"service_id" swaggertype:"integer" example:"1"`
+	Price                 service.Optional[int]    `json:"price" swaggertype:"integer" example:"799"`
+	Currency              service.Optional[string] `json:"currency" swaggertype:"string" example:"RUB"`                                                         // null resets to RUB
+	BillingCycle          service.Optional[string] `json:"billing_cycle" swaggertype:"string" enums:"weekly,monthly,quarterly,yearly,custom" example:"monthly"` // null resets to monthly
+	BillingIntervalMonths service.Optional[int]    `json:"billing_interval_months" swaggertype:"integer" example:"6"`
+	StartDate             service.Optional[string] `json:"start_date" swaggertype:"string" example:"07-2025"`
+	EndDate               service.Optional[string] `json:"end_date" swaggertype:"string" example:"12-2025"` // null removes the end date
+	TrialEndDate          service.Optional[string] `json:"trial_end_date" swaggertype:"string" example:"2025-08-19"`
+} // @name PatchSubscriptionRequest
+
+// CreateServiceRequest represents the request body for creating a catalog service
+type CreateServiceRequest struct {
+	Name            string   `json:"name" example:"Yandex Plus"`
+	Category        string   `json:"category,omitempty" example:"music"`
+	DefaultPrice    *int     `json:"default_price,omitempty" example:"399"`
+	DefaultCurrency string   `json:"default_currency,omitempty" example:"RUB"`
+	Aliases         []string `json:"aliases,omitempty" example:"Яндекс Плюс,Yandex Music"`
+} // @name CreateServiceRequest
+
+// UpdateServiceRequest represents the request body for updating a catalog service
+type UpdateServiceRequest struct {
+	Name            string   `json:"name" example:"Yandex Plus"`
+	Category        string   `json:"category,omitempty" example:"music"`
+	DefaultPrice    *int     `json:"default_price,omitempty" example:"399"`
+	DefaultCurrency string   `json:"default_currency,omitempty" example:"RUB"`
+	Aliases         []string `json:"aliases,omitempty" example:"Яндекс Плюс,Yandex Music"` // Replaces existing aliases
+} // @name UpdateServiceRequest
+
+// AddSubscriptionPriceRequest represents the request body for scheduling a price change
+type AddSubscriptionPriceRequest struct {
+	Price         int    `json:"price" example:"499"`
+	EffectiveFrom string `json:"effective_from" example:"2025-09-01"`
+} // @name AddSubscriptionPriceRequest
+
+// ListSubscriptionsRequest represents the query params for listing a user's subscriptions
+type ListSubscriptionsRequest struct {
+	Limit             int    `form:"limit" example:"20"`
+	Cursor            string `form:"cursor"`
+	SortBy            string `form:"sort_by" enums:"price,name,start_date,end_date" example:"start_date"`
+	Order             string `form:"order" enums:"asc,desc" example:"desc"`
+	Status            string `form:"status" enums:"active,ended" example:"active"`
+	ServiceNamePrefix string `form:"service_name_prefix" example:"Net"`
+	MinPrice          *int   `form:"min_price" example:"100"`
+	MaxPrice          *int   `form:"max_price" example:"1000"`
+	ActiveFrom        string `form:"active_from" example:"2025-01-01"`
+	ActiveTo          string `form:"active_to" example:"2025-12-31"`
+	Format            string `form:"format" enums:"json,csv,xlsx,ndjson" example:"csv"` // Overrides the Accept header
+	Locale            string `form:"locale" example:"ru"`                               // Of CSV numbers and dates, overrides Accept-Language
+}
+
+// ImportSubscriptionsRequest represents the query params of a subscriptions import
+type ImportSubscriptionsRequest struct {
+	Mode    string `form:"mode" enums:"atomic,best_effort" example:"atomic"`
+	DryRun  bool   `form:"dry_run" example:"false"`
+	Mapping string `form:"mapping" example:"Service=service_name,Cost=price"` // Comma-separated CSV header=field pairs
+}
+
+// ListAuditEntriesRequest represents the query params for listing the audit log
+type ListAuditEntriesRequest struct {
+	UserID         string `form:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	SubscriptionID int    `form:"subscription_id" example:"1"`
+	Actor          string `form:"actor" example:"admin-user"`
+	Operation      string `form:"operation" enums:"create,update,delete,price_change" example:"update"`
+	From           string `form:"from" example:"2025-01-01"`
+	To             string `form:"to" example:"2025-12-31"`
+	BeforeID       int64  `form:"before_id" example:"120"`
+	Limit          int    `form:"limit" example:"50"`
+}
+
 // GetCostRequest represents the request body/query params for calculating total cost
 type GetCostRequest struct {
-	UserID       string   `json:"user_id" form:"user_id" binding:"required,uuid4" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	UserID       string   `json:"user_id" form:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
 	ServiceNames []string `json:"service_names,omitempty" form:"service_names" example:"Netflix,Spotify"`
-	StartDate    string   `json:"start_date" form:"start_date" binding:"required" example:"01-2025"`
-	EndDate      string   `json:"end_date" form:"end_date" binding:"required" example:"12-2025"`
+	StartDate    string   `json:"start_date" form:"start_date" example:"01-2025"`
+	EndDate      string   `json:"end_date" form:"end_date" example:"12-2025"`
+	Currency     string   `json:"currency,omitempty" form:"currency" example:"RUB"`
+	Proration    string   `json:"proration,omitempty" form:"proration" enums:"charge_date,whole_months,daily_prorated" example:"charge_date"`
+	GroupBy      string   `json:"group_by,omitempty" form:"group_by" example:"month,service"`
+	Format       string   `json:"-" form:"format" enums:"json,csv,xlsx,ndjson" example:"xlsx"` // Overrides the Accept header
+	Locale       string   `json:"-" form:"locale" example:"ru"`                                // Of CSV numbers and dates, overrides Accept-Language
 } // @name GetCostRequest
 
 // SubscriptionResponse represents a subscription in API responses
 type SubscriptionResponse struct {
-	ID          int     `json:"id" example:"1"`
-	ServiceName string  `json:"service_name" example:"Yandex Plus"`
-	Price       int     `json:"price" example:"400"`
-	UserID      string  `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   string  `json:"start_date" example:"2025-07-01T00:00:00Z"`
-	EndDate     *string `json:"end_date,omitempty" example:"2025-12-31T23:59:59Z"`
+	ID                    int     `json:"id" example:"1"`
+	ServiceName           string  `json:"service_name" example:"Yandex Plus"`
+	ServiceID             *int    `json:"service_id,omitempty" example:"1"`
+	Price                 int     `json:"price" example:"400"`
+	Currency              string  `json:"currency" example:"RUB"`
+	BillingCycle          string  `json:"billing_cycle" example:"monthly"`
+	BillingIntervalMonths *int    `json:"billing_interval_months,omitempty" example:"6"`
+	UserID                string  `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate             string  `json:"start_date" example:"2025-07-01T00:00:00Z"`
+	EndDate               *string `json:"end_date,omitempty" example:"2025-12-31T23:59:59Z"`
+	TrialEndDate          *string `json:"trial_end_date,omitempty" example:"2025-08-19T00:00:00Z"`
+	Version               int     `json:"version" example:"3"` // Incremented by every update, also sent as the ETag
 } // @name SubscriptionResponse
 
+// SetExchangeRateRequest represents the request body for setting an exchange rate
+type SetExchangeRateRequest struct {
+	Rate float64 `json:"rate" example:"80.5"` // Units of the quote currency one unit of the base currency is worth
+} // @name SetExchangeRateRequest
+
+// ExchangeRateResponse represents an exchange rate in API responses
+type ExchangeRateResponse struct {
+	BaseCurrency  string  `json:"base_currency" example:"USD"`
+	QuoteCurrency string  `json:"quote_currency" example:"RUB"`
+	Rate          float64 `json:"rate" example:"80.5"`
+	UpdatedAt     string  `json:"updated_at" example:"2025-07-01T00:00:00Z"`
+} // @name ExchangeRateResponse
+
+// ListExchangeRatesResponse represents response for listing exchange rates
+type ListExchangeRatesResponse struct {
+	ExchangeRates []ExchangeRateResponse `json:"exchange_rates"`
+	Count         int                    `json:"count" example:"2"`
+} // @name ListExchangeRatesResponse
+
+// SubscriptionPriceResponse represents an entry of a subscription's price history
+type SubscriptionPriceResponse struct {
+	Price         int    `json:"price" example:"499"`
+	EffectiveFrom string `json:"effective_from" example:"2025-09-01"`
+	CreatedAt     string `json:"created_at" example:"2025-08-15T10:00:00Z"`
+} // @name SubscriptionPriceResponse
+
+// SubscriptionPricesResponse represents the price history of a subscription
+type SubscriptionPricesResponse struct {
+	SubscriptionID int                         `json:"subscription_id" example:"1"`
+	Prices         []SubscriptionPriceResponse `json:"prices"`
+} // @name SubscriptionPricesResponse
+
+// ServiceResponse represents a catalog service in API responses
+type ServiceResponse struct {
+	ID              int      `json:"id" example:"1"`
+	Name            string   `json:"name" example:"Yandex Plus"`
+	Category        string   `json:"category" example:"music"`
+	DefaultPrice    *int     `json:"default_price,omitempty" example:"399"`
+	DefaultCurrency string   `json:"default_currency" example:"RUB"`
+	Aliases         []string `json:"aliases" example:"Яндекс Плюс,Yandex Music"`
+	CreatedAt       string   `json:"created_at" example:"2025-07-01T00:00:00Z"`
+	UpdatedAt       string   `json:"updated_at" example:"2025-07-01T00:00:00Z"`
+} // @name ServiceResponse
+
+// ListServicesResponse represents response for listing catalog services
+type ListServicesResponse struct {
+	Services []ServiceResponse `json:"services"`
+	Count    int               `json:"count" example:"5"`
+} // @name ListServicesResponse
+
 // CostResponse represents the response for cost calculation
 type CostResponse struct {
-	UserID    string                      `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate string                      `json:"start_date" example:"01-2025"`
-	EndDate   string                      `json:"end_date" example:"12-2025"`
-	TotalCost int                         `json:"total_cost" example:"4800"`
-	Breakdown []SubscriptionCostBreakdown `json:"breakdown"`
+	UserID     string                      `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate  string                      `json:"start_date" example:"01-2025"`
+	EndDate    string                      `json:"end_date" example:"12-2025"`
+	Currency   string                      `json:"currency" example:"RUB"`
+	Proration  string                      `json:"proration" example:"charge_date"`
+	TotalCost  float64                     `json:"total_cost" example:"4800"`
+	Breakdown  []SubscriptionCostBreakdown `json:"breakdown"`
+	GroupBy    string                      `json:"group_by,omitempty" example:"month"`
+	Groups     []CostGroup                 `json:"groups,omitempty"`
+	TimeSeries []CostPoint                 `json:"time_series,omitempty"`
 } // @name CostResponse
 
-// SubscriptionCostBreakdown represents cost breakdown for each subscription
+// CostGroup represents a subtotal of a grouped cost report in the report currency
+type CostGroup struct {
+	ServiceName        string  `json:"service_name,omitempty" example:"Netflix"`
+	Month              string  `json:"month,omitempty" example:"01-2025"`
+	SubscriptionsCount int     `json:"subscriptions_count" example:"1"`
+	TotalCost          float64 `json:"total_cost" example:"400"`
+} // @name CostGroup
+
+// CostPoint represents the spend of one month of the period; months without spend are zero
+type CostPoint struct {
+	Month     string  `json:"month" example:"01-2025"`
+	TotalCost float64 `json:"total_cost" example:"400"`
+} // @name CostPoint
+
+// SubscriptionCostBreakdown represents cost breakdown for each subscription.
+// TotalCost is in the subscription's own currency and depends on the proration mode, ConvertedCost is in the report currency.
 type SubscriptionCostBreakdown struct {
-	SubscriptionID int    `json:"subscription_id" example:"1"`
-	ServiceName    string `json:"service_name" example:"Netflix"`
-	MonthlyPrice   int    `json:"monthly_price" example:"599"`
-	MonthsCount    int    `json:"months_count" example:"6"`
-	TotalCost      int    `json:"total_cost" example:"3594"`
+	SubscriptionID        int           `json:"subscription_id" example:"1"`
+	ServiceName           string        `json:"service_name" example:"Netflix"`
+	Currency              string        `json:"currency" example:"USD"`
+	BillingCycle          string        `json:"billing_cycle" example:"monthly"`
+	BillingIntervalMonths int           `json:"billing_interval_months,omitempty" example:"6"`
+	Price                 int           `json:"price" example:"10"`
+	ChargesCount          int           `json:"charges_count" example:"6"`
+	MonthsCount           int           `json:"months_count" example:"6"`
+	TrialEndDate          string        `json:"trial_end_date,omitempty" example:"2025-01-31"`
+	TrialChargesCount     int           `json:"trial_charges_count" example:"1"`
+	TrialMonthsCount      int           `json:"trial_months_count" example:"1"`
+	TotalCost             float64       `json:"total_cost" example:"60"`
+	ExchangeRate          float64       `json:"exchange_rate" example:"80.5"`
+	ConvertedCost         float64       `json:"converted_cost" example:"4830"`
+	Segments              []CostSegment `json:"segments"`
 } // @name SubscriptionCostBreakdown
 
-// ErrorResponse represents an error response
+// CostSegment represents the part of a breakdown line billed at one price
+type CostSegment struct {
+	Price        int     `json:"price" example:"10"`
+	From         string  `json:"from" example:"2025-01-01"`
+	To           string  `json:"to" example:"2025-03-31"`
+	ChargesCount int     `json:"charges_count" example:"3"`
+	TotalCost    float64 `json:"total_cost" example:"30"`
+} // @name CostSegment
+
+// ProblemResponse represents an RFC 7807 problem details error response
+type ProblemResponse struct {
+	Type      string               `json:"type" example:"/problems/validation-failed"`                          // Identifies the kind of problem
+	Title     string               `json:"title" example:"validation failed"`                                   // Short summary of the problem type
+	Status    int                  `json:"status" example:"400"`                                                // HTTP status code
+	Detail    string               `json:"detail,omitempty" example:"user_id: must be a valid UUID"`            // Explanation of this occurrence
+	Instance  string               `json:"instance,omitempty" example:"/api/v1/subscriptions"`                  // Request path
+	Code      string               `json:"code" example:"VALIDATION_FAILED"`                                    // Stable error code
+	RequestID string               `json:"request_id,omitempty" example:"3f1c9a1e-2b7d-4c55-9a8e-4d0f1b2c3d4e"` // ID of the request for support
+	Fields    []FieldErrorResponse `json:"fields,omitempty"`                                                    // Invalid fields of a request that failed validation
+} // @name ProblemResponse
+
+// ErrorResponse represents an error response in the legacy format
 type ErrorResponse struct {
-	Error   string `json:"error" example:"validation failed"`
-	Message string `json:"message,omitempty" example:"invalid user ID format"`
+	Error   string               `json:"error" example:"validation failed"`
+	Message string               `json:"message,omitempty" example:"invalid user ID format"`
+	Code    string               `json:"code,omitempty" example:"VALIDATION_FAILED"` // Stable error code
+	Fields  []FieldErrorResponse `json:"fields,omitempty"`                           // Invalid fields of a request that failed validation
 } // @name ErrorResponse
 
+// FieldErrorResponse describes an invalid request field
+type FieldErrorResponse struct {
+	Field   string `json:"field,omitempty" example:"user_id"`      // JSON or query parameter name, empty for the whole body
+	Code    string `json:"code" example:"uuid4"`                   // Failed rule
+	Message string `json:"message" example:"must be a valid UUID"` // Human-readable description
+	Param   string `json:"param,omitempty" example:""`             // Parameter of the rule, e.g. the minimum for min
+} // @name FieldErrorResponse
+
 // SuccessResponse represents a generic success response
 type SuccessResponse struct {
 	Message string `json:"message" example:"operation completed successfully"`
@@ -76,26 +284,208 @@ type SuccessResponse struct {
 // ListSubscriptionsResponse represents response for listing subscriptions
 type ListSubscriptionsResponse struct {
 	Subscriptions []SubscriptionResponse `json:"subscriptions"`
-	Count         int                    `json:"count" example:"5"`
+	Count         int                    `json:"count" example:"5"`                               // Subscriptions in this response
+	TotalCount    int                    `json:"total_count" example:"42"`                        // All subscriptions matching the filters
+	NextCursor    string                 `json:"next_cursor,omitempty" example:"eyJzIjoic3Rh..."` // Pass as cursor to get the next page
 } // @name ListSubscriptionsResponse
 
+// ImportReportResponse represents the outcome of a subscriptions import
+type ImportReportResponse struct {
+	Mode    string              `json:"mode" example:"atomic"`
+	DryRun  bool                `json:"dry_run" example:"false"`
+	Total   int                 `json:"total" example:"3"`   // Rows in the import
+	Created int                 `json:"created" example:"2"` // Subscriptions created
+	Failed  int                 `json:"failed" example:"1"`  // Rows that failed
+	Rows    []ImportRowResponse `json:"rows"`
+} // @name ImportReportResponse
+
+// ImportRowResponse represents the outcome of one row of an import
+type ImportRowResponse struct {
+	Row    int                  `json:"row" example:"1"`                                                          // 1-based, not counting the CSV header
+	Status string               `json:"status" enums:"created,valid,failed" example:"created"`                    // valid rows weren't created because of a dry run or of failed rows in an atomic import
+	ID     int                  `json:"id,omitempty" example:"42"`                                                // ID of the created subscription
+	Code   string               `json:"code,omitempty" example:"VALIDATION_FAILED"`                               // Error code of a failed row
+	Error  string               `json:"error,omitempty" example:"validation failed: price: must be 0 or greater"` // Why the row failed
+	Fields []FieldErrorResponse `json:"fields,omitempty"`                                                         // Invalid fields of the row
+} // @name ImportRowResponse
+
+// AuditEntryResponse represents a recorded subscription change
+type AuditEntryResponse struct {
+	ID             int64                 `json:"id" example:"120"`
+	SubscriptionID int                   `json:"subscription_id" example:"1"`
+	UserID         string                `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Actor          string                `json:"actor" example:"admin-user"`
+	RequestID      string                `json:"request_id,omitempty" example:"3f2a9c0e5b7d41a8c6e1f0d2b4a69788"`
+	Operation      string                `json:"operation" example:"update"`
+	OldData        *SubscriptionResponse `json:"old_data,omitempty"` // Absent for creations
+	NewData        *SubscriptionResponse `json:"new_data,omitempty"` // Absent for deletions
+	CreatedAt      string                `json:"created_at" example:"2025-08-15T10:00:00Z"`
+} // @name AuditEntryResponse
+
+// SubscriptionHistoryResponse represents the change history of a subscription, oldest first
+type SubscriptionHistoryResponse struct {
+	SubscriptionID int                  `json:"subscription_id" example:"1"`
+	Entries        []AuditEntryResponse `json:"entries"`
+} // @name SubscriptionHistoryResponse
+
+// ListAuditEntriesResponse represents a page of the audit log, newest first
+type ListAuditEntriesResponse struct {
+	Entries      []AuditEntryResponse `json:"entries"`
+	Count        int                  `json:"count" example:"50"`
+	NextBeforeID int64                `json:"next_before_id,omitempty" example:"71"` // Pass as before_id to get the next page
+} // @name ListAuditEntriesResponse
+
+// LivenessResponse represents the response of the liveness probe
+type LivenessResponse struct {
+	Status string            `json:"status" example:"alive"`
+	Build  BuildInfoResponse `json:"build"`
+} // @name LivenessResponse
+
+// ReadinessResponse represents the response of the readiness probe
+type ReadinessResponse struct {
+	Status     string              `json:"status" example:"ready"` // ready or not_ready
+	Components ReadinessComponents `json:"components"`
+	Build      BuildInfoResponse   `json:"build"`
+} // @name ReadinessResponse
+
+// ReadinessComponents represents the status of each dependency
+type ReadinessComponents struct {
+	Database   DatabaseStatusResponse   `json:"database"`
+	Migrations MigrationsStatusResponse `json:"migrations"`
+} // @name ReadinessComponents
+
+// DatabaseStatusResponse represents the status of the database connection
+type DatabaseStatusResponse struct {
+	Status    string  `json:"status" example:"up"` // up or down
+	Error     string  `json:"error,omitempty" example:"database is unreachable"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+} // @name DatabaseStatusResponse
+
+// MigrationsStatusResponse represents the status of the database schema
+type MigrationsStatusResponse struct {
+	Status          string `json:"status" example:"up"` // up or down
+	Error           string `json:"error,omitempty" example:"schema is at version 7, expected 8"`
+	Version         uint   `json:"version" example:"8"`
+	ExpectedVersion uint   `json:"expected_version" example:"8"`
+	Dirty           bool   `json:"dirty" example:"false"`
+} // @name MigrationsStatusResponse
+
+// BuildInfoResponse represents the running build
+type BuildInfoResponse struct {
+	Version       string `json:"version" example:"v1.2.0"`
+	Commit        string `json:"commit" example:"3797ee4"`
+	GoVersion     string `json:"go_version" example:"go1.24.4"`
+	StartedAt     string `json:"started_at" example:"2025-08-15T10:00:00Z"`
+	UptimeSeconds int64  `json:"uptime_seconds" example:"3600"`
+} // @name BuildInfoResponse
+
 // Convert service request to handler request
 func (r *CreateSubscriptionRequest) ToServiceRequest() *service.CreateSubscriptionRequest {
 	return &service.CreateSubscriptionRequest{
-		ServiceName: r.ServiceName,
-		Price:       r.Price,
-		UserID:      r.UserID,
-		StartDate:   r.StartDate,
-		EndDate:     r.EndDate,
+		ServiceName:           r.ServiceName,
+		ServiceID:             r.ServiceID,
+		Price:                 r.Price,
+		Currency:              r.Currency,
+		BillingCycle:          r.BillingCycle,
+		BillingIntervalMonths: r.BillingIntervalMonths,
+		UserID:                r.UserID,
+		StartDate:             r.StartDate,
+		EndDate:               r.EndDate,
+		TrialEndDate:          r.TrialEndDate,
 	}
 }
 
 func (r *UpdateSubscriptionRequest) ToServiceRequest() *service.UpdateSubscriptionRequest {
 	return &service.UpdateSubscriptionRequest{
-		ServiceName: r.ServiceName,
-		Price:       r.Price,
-		StartDate:   r.StartDate,
-		EndDate:     r.EndDate,
+		ServiceName:           r.ServiceName,
+		ServiceID:             r.ServiceID,
+		Price:                 r.Price,
+		Currency:              r.Currency,
+		BillingCycle:          r.BillingCycle,
+		BillingIntervalMonths: r.BillingIntervalMonths,
+		StartDate:             r.StartDate,
+		EndDate:               r.EndDate,
+		TrialEndDate:          r.TrialEndDate,
+	}
+}
+
+func (r *PatchSubscriptionRequest) ToServiceRequest() *service.PatchSubscriptionRequest {
+	return &service.PatchSubscriptionRequest{
+		ServiceName:           r.ServiceName,
+		ServiceID:             r.ServiceID,
+		Price:                 r.Price,
+		Currency:              r.Currency,
+		BillingCycle:          r.BillingCycle,
+		BillingIntervalMonths: r.BillingIntervalMonths,
+		StartDate:             r.StartDate,
+		EndDate:               r.EndDate,
+		TrialEndDate:          r.TrialEndDate,
+	}
+}
+
+func (r *CreateServiceRequest) ToServiceRequest() *service.CreateServiceRequest {
+	return &service.CreateServiceRequest{
+		Name:            r.Name,
+		Category:        r.Category,
+		DefaultPrice:    r.DefaultPrice,
+		DefaultCurrency: r.DefaultCurrency,
+		Aliases:         r.Aliases,
+	}
+}
+
+func (r *UpdateServiceRequest) ToServiceRequest() *service.UpdateServiceRequest {
+	return &service.UpdateServiceRequest{
+		Name:            r.Name,
+		Category:        r.Category,
+		DefaultPrice:    r.DefaultPrice,
+		DefaultCurrency: r.DefaultCurrency,
+		Aliases:         r.Aliases,
+	}
+}
+
+func (r *AddSubscriptionPriceRequest) ToServiceRequest() *service.AddSubscriptionPriceRequest {
+	return &service.AddSubscriptionPriceRequest{
+		Price:         r.Price,
+		EffectiveFrom: r.EffectiveFrom,
+	}
+}
+
+func (r *ListSubscriptionsRequest) ToServiceRequest(userID string) *service.ListSubscriptionsRequest {
+	return &service.ListSubscriptionsRequest{
+		UserID:            userID,
+		Limit:             r.Limit,
+		Cursor:            r.Cursor,
+		SortBy:            r.SortBy,
+		Order:             r.Order,
+		Status:            r.Status,
+		ServiceNamePrefix: r.ServiceNamePrefix,
+		MinPrice:          r.MinPrice,
+		MaxPrice:          r.MaxPrice,
+		ActiveFrom:        r.ActiveFrom,
+		ActiveTo:          r.ActiveTo,
+	}
+}
+
+func (r *ImportSubscriptionsRequest) ToServiceRequest(format string, mapping map[string]string, data []byte) *service.ImportSubscriptionsRequest {
+	return &service.ImportSubscriptionsRequest{
+		Format:  format,
+		Mode:    r.Mode,
+		DryRun:  r.DryRun,
+		Mapping: mapping,
+		Data:    data,
+	}
+}
+
+func (r *ListAuditEntriesRequest) ToServiceRequest() *service.ListAuditEntriesRequest {
+	return &service.ListAuditEntriesRequest{
+		UserID:         r.UserID,
+		SubscriptionID: r.SubscriptionID,
+		Actor:          r.Actor,
+		Operation:      r.Operation,
+		From:           r.From,
+		To:             r.To,
+		BeforeID:       r.BeforeID,
+		Limit:          r.Limit,
 	}
 }
 
@@ -105,17 +495,25 @@ func (r *GetCostRequest) ToServiceRequest() *service.GetCostRequest {
 		ServiceNames: r.ServiceNames,
 		StartDate:    r.StartDate,
 		EndDate:      r.EndDate,
+		Currency:     r.Currency,
+		Proration:    r.Proration,
+		GroupBy:      r.GroupBy,
 	}
 }
 
 // Convert model to response
 func SubscriptionToResponse(sub *repository.Subscription) SubscriptionResponse {
 	resp := SubscriptionResponse{
-		ID:          sub.ID,
-		ServiceName: sub.ServiceName,
-		Price:       sub.Price,
-		UserID:      sub.UserID,
-		StartDate:   sub.StartDate.Format(time.RFC3339),
+		ID:                    sub.ID,
+		ServiceName:           sub.ServiceName,
+		ServiceID:             sub.ServiceID,
+		Price:                 sub.Price,
+		Currency:              sub.Currency,
+		BillingCycle:          sub.BillingCycle,
+		BillingIntervalMonths: sub.BillingIntervalMonths,
+		UserID:                sub.UserID,
+		StartDate:             sub.StartDate.Format(time.RFC3339),
+		Version:               sub.Version,
 	}
 
 	if sub.EndDate != nil {
@@ -123,6 +521,11 @@ func SubscriptionToResponse(sub *repository.Subscription) SubscriptionResponse {
 		resp.EndDate = &endDateStr
 	}
 
+	if sub.TrialEndDate != nil {
+		trialEndDateStr := sub.TrialEndDate.Format(time.RFC3339)
+		resp.TrialEndDate = &trialEndDateStr
+	}
+
 	return resp
 }
 
@@ -135,6 +538,179 @@ func SubscriptionsToResponse(subs []*repository.Subscription) ListSubscriptionsR
 	return ListSubscriptionsResponse{
 		Subscriptions: responses,
 		Count:         len(responses),
+		TotalCount:    len(responses),
+	}
+}
+
+func SubscriptionsPageToResponse(page *service.SubscriptionsPage) ListSubscriptionsResponse {
+	response := SubscriptionsToResponse(page.Subscriptions)
+	response.TotalCount = page.TotalCount
+	response.NextCursor = page.NextCursor
+	return response
+}
+
+func ImportReportToResponse(report *service.ImportReport) ImportReportResponse {
+	rows := make([]ImportRowResponse, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = ImportRowResponse{
+			Row:    row.Row,
+			Status: row.Status,
+			ID:     row.ID,
+			Code:   string(row.Code),
+			Error:  row.Error,
+			Fields: FieldErrorsToResponse(row.Fields),
+		}
+	}
+
+	return ImportReportResponse{
+		Mode:    report.Mode,
+		DryRun:  report.DryRun,
+		Total:   report.Total,
+		Created: report.Created,
+		Failed:  report.Failed,
+		Rows:    rows,
+	}
+}
+
+func AuditEntryToResponse(entry *repository.AuditEntry) AuditEntryResponse {
+	return AuditEntryResponse{
+		ID:             entry.ID,
+		SubscriptionID: entry.SubscriptionID,
+		UserID:         entry.UserID,
+		Actor:          entry.Actor,
+		RequestID:      entry.RequestID,
+		Operation:      entry.Operation,
+		OldData:        auditSnapshotToResponse(entry.OldData),
+		NewData:        auditSnapshotToResponse(entry.NewData),
+		CreatedAt:      entry.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// auditSnapshotToResponse decodes a subscription snapshot stored in the audit log
+func auditSnapshotToResponse(data json.RawMessage) *SubscriptionResponse {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var sub repository.Subscription
+	if err := json.Unmarshal(data, &sub); err != nil {
+		return nil
+	}
+	resp := SubscriptionToResponse(&sub)
+	return &resp
+}
+
+func AuditEntriesToResponse(entries []*repository.AuditEntry) []AuditEntryResponse {
+	responses := make([]AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = AuditEntryToResponse(entry)
+	}
+	return responses
+}
+
+func AuditPageToResponse(page *service.AuditPage) ListAuditEntriesResponse {
+	entries := AuditEntriesToResponse(page.Entries)
+	return ListAuditEntriesResponse{
+		Entries:      entries,
+		Count:        len(entries),
+		NextBeforeID: page.NextBeforeID,
+	}
+}
+
+func BuildInfoToResponse(info buildinfo.Info) BuildInfoResponse {
+	return BuildInfoResponse{
+		Version:       info.Version,
+		Commit:        info.Commit,
+		GoVersion:     info.GoVersion,
+		StartedAt:     info.StartedAt.UTC().Format(time.RFC3339),
+		UptimeSeconds: int64(info.Uptime.Seconds()),
+	}
+}
+
+func ReadinessReportToResponse(report *service.ReadinessReport) ReadinessResponse {
+	status := "ready"
+	if !report.Ready {
+		status = "not_ready"
+	}
+
+	return ReadinessResponse{
+		Status: status,
+		Components: ReadinessComponents{
+			Database: DatabaseStatusResponse{
+				Status:    report.Database.Status,
+				Error:     report.Database.Error,
+				LatencyMs: float64(report.Database.Latency.Microseconds()) / 1000,
+			},
+			Migrations: MigrationsStatusResponse(report.Migrations),
+		},
+		Build: BuildInfoToResponse(report.Build),
+	}
+}
+
+func ServiceToResponse(svc *repository.Service) ServiceResponse {
+	aliases := make([]string, len(svc.Aliases))
+	for i, alias := range svc.Aliases {
+		aliases[i] = alias.Alias
+	}
+
+	return ServiceResponse{
+		ID:              svc.ID,
+		Name:            svc.Name,
+		Category:        svc.Category,
+		DefaultPrice:    svc.DefaultPrice,
+		DefaultCurrency: svc.DefaultCurrency,
+		Aliases:         aliases,
+		CreatedAt:       svc.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       svc.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func ServicesToResponse(services []*repository.Service) ListServicesResponse {
+	responses := make([]ServiceResponse, len(services))
+	for i, svc := range services {
+		responses[i] = ServiceToResponse(svc)
+	}
+
+	return ListServicesResponse{
+		Services: responses,
+		Count:    len(responses),
+	}
+}
+
+func ExchangeRateToResponse(rate *repository.ExchangeRate) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		UpdatedAt:     rate.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func ExchangeRatesToResponse(rates []*repository.ExchangeRate) ListExchangeRatesResponse {
+	responses := make([]ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = ExchangeRateToResponse(rate)
+	}
+
+	return ListExchangeRatesResponse{
+		ExchangeRates: responses,
+		Count:         len(responses),
+	}
+}
+
+func SubscriptionPricesToResponse(subscriptionID int, prices []*repository.SubscriptionPrice) SubscriptionPricesResponse {
+	responses := make([]SubscriptionPriceResponse, len(prices))
+	for i, price := range prices {
+		responses[i] = SubscriptionPriceResponse{
+			Price:         price.Price,
+			EffectiveFrom: price.EffectiveFrom.Format("2006-01-02"),
+			CreatedAt:     price.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return SubscriptionPricesResponse{
+		SubscriptionID: subscriptionID,
+		Prices:         responses,
 	}
 }
 
@@ -142,19 +718,78 @@ func ServiceCostToResponse(serviceCost *service.CostResponse) CostResponse {
 	breakdown := make([]SubscriptionCostBreakdown, len(serviceCost.Breakdown))
 	for i, item := range serviceCost.Breakdown {
 		breakdown[i] = SubscriptionCostBreakdown{
-			SubscriptionID: item.SubscriptionID,
-			ServiceName:    item.ServiceName,
-			MonthlyPrice:   item.MonthlyPrice,
-			MonthsCount:    item.MonthsCount,
-			TotalCost:      item.TotalCost,
+			SubscriptionID:        item.SubscriptionID,
+			ServiceName:           item.ServiceName,
+			Currency:              item.Currency,
+			BillingCycle:          item.BillingCycle,
+			BillingIntervalMonths: item.BillingIntervalMonths,
+			Price:                 item.Price,
+			ChargesCount:          item.ChargesCount,
+			MonthsCount:           item.MonthsCount,
+			TrialEndDate:          item.TrialEndDate,
+			TrialChargesCount:     item.TrialChargesCount,
+			TrialMonthsCount:      item.TrialMonthsCount,
+			TotalCost:             item.TotalCost,
+			ExchangeRate:          item.ExchangeRate,
+			ConvertedCost:         item.ConvertedCost,
+			Segments:              make([]CostSegment, len(item.Segments)),
+		}
+		for j, segment := range item.Segments {
+			breakdown[i].Segments[j] = CostSegment(segment)
 		}
 	}
 
-	return CostResponse{
+	response := CostResponse{
 		UserID:    serviceCost.UserID,
 		StartDate: serviceCost.StartDate,
 		EndDate:   serviceCost.EndDate,
+		Currency:  serviceCost.Currency,
+		Proration: serviceCost.Proration,
 		TotalCost: serviceCost.TotalCost,
 		Breakdown: breakdown,
+		GroupBy:   serviceCost.GroupBy,
+	}
+
+	if serviceCost.Groups != nil {
+		response.Groups = make([]CostGroup, len(serviceCost.Groups))
+		for i, group := range serviceCost.Groups {
+			response.Groups[i] = CostGroup(group)
+		}
+	}
+	if serviceCost.TimeSeries != nil {
+		response.TimeSeries = make([]CostPoint, len(serviceCost.TimeSeries))
+		for i, point := range serviceCost.TimeSeries {
+			response.TimeSeries[i] = CostPoint(point)
+		}
+	}
+
+	return response
+}
+
+// LogLevelRequest represents a request to change the logging level
+type LogLevelRequest struct {
+	Level string `json:"level" enums:"debug,info,warn,error" example:"debug"`
+}
+
+// LogLevelResponse represents the current logging level
+type LogLevelResponse struct {
+	Level string `json:"level" example:"info"`
+}
+
+// FieldErrorsToResponse converts validation field errors to response models
+func FieldErrorsToResponse(fields []validation.FieldError) []FieldErrorResponse {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	response := make([]FieldErrorResponse, len(fields))
+	for i, f := range fields {
+		response[i] = FieldErrorResponse{
+			Field:   f.Field,
+			Code:    f.Code,
+			Message: f.Message,
+			Param:   f.Param,
+		}
 	}
+	return response
 }
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDMiddleware takes the request ID from the X-Request-ID header or generates a new one,
+// stores it in the request context and echoes it in the response. The request context also gets
+// a logger tagged with the request ID, the route and the user_id parameter, if any.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		fields := []logger.Field{
+			logger.String("request_id", id),
+			logger.String("route", c.FullPath()),
+		}
+		if userID := requestUserID(c); userID != "" {
+			fields = append(fields, logger.String("user_id", userID))
+		}
+
+		ctx := requestid.WithRequestID(c.Request.Context(), id)
+		ctx = logger.WithContext(ctx, logger.Global().With(fields...))
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
+
+// requestUserID returns the user_id path or query parameter of the request
+func requestUserID(c *gin.Context) string {
+	if userID := c.Param("user_id"); userID != "" {
+		return userID
+	}
+	return c.Query("user_id")
+}
@@ -1,43 +1,107 @@
 package handlers
 
 import (
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/metrics"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(subscriptionService service.SubscriptionService) *gin.Engine {
+// SetupRouter creates the HTTP router. API routes require a bearer token unless verifier is nil,
+// and metrics are neither recorded nor served if m is nil.
+func SetupRouter(subscriptionService service.SubscriptionService, catalogService service.CatalogService, auditService service.AuditService, exchangeRateService service.ExchangeRateService, healthService service.HealthService, idempotencyService service.IdempotencyService, m *metrics.Metrics, verifier *auth.Verifier, cfg *config.Config) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 
-	router := gin.Default()
+	router := gin.New()
 
-	// Middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	// Middleware. Panics are recovered innermost so that the access log, traces and metrics
+	// see the 500 response.
+	router.Use(RequestIDMiddleware())
+	router.Use(ErrorFormatMiddleware(cfg.Errors.Format))
+	router.Use(TracingMiddleware())
+	router.Use(AccessLogMiddleware(cfg.AccessLog))
+	if m != nil {
+		router.Use(MetricsMiddleware(m))
+	}
+	router.Use(ErrorHandlerMiddleware())
 	router.Use(CORSMiddleware())
 
-	// Health check endpoint
+	// Health check endpoints
+	healthHandler := NewHealthHandler(healthService)
 	router.GET("/health", HealthCheck)
+	router.GET("/livez", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+
+	// Prometheus metrics
+	if m != nil {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
 
 	subscriptionHandler := NewSubscriptionHandler(subscriptionService)
+	catalogHandler := NewCatalogHandler(catalogService)
+	auditHandler := NewAuditHandler(auditService)
+	exchangeRateHandler := NewExchangeRateHandler(exchangeRateService)
+	logLevelHandler := NewLogLevelHandler(service.NewLogLevelService(logger.Global()))
 
 	v1 := router.Group("/api/v1")
+	if verifier != nil {
+		v1.Use(AuthMiddleware(verifier))
+	}
 	{
 		subscriptions := v1.Group("/subscriptions")
 		{
-			subscriptions.POST("", subscriptionHandler.CreateSubscription)
+			subscriptions.POST("", IdempotencyMiddleware(idempotencyService), subscriptionHandler.CreateSubscription)
+			subscriptions.POST("/import", subscriptionHandler.ImportSubscriptions)
 			subscriptions.GET("/:user_id/:subscription_id", subscriptionHandler.GetSubscription)
 			subscriptions.PUT("/:user_id/:subscription_id", subscriptionHandler.UpdateSubscription)
+			subscriptions.PATCH("/:user_id/:subscription_id", subscriptionHandler.PatchSubscription)
 			subscriptions.DELETE("/:user_id/:subscription_id", subscriptionHandler.DeleteSubscription)
+			subscriptions.POST("/:user_id/:subscription_id/prices", subscriptionHandler.AddSubscriptionPrice)
+			subscriptions.GET("/:user_id/:subscription_id/prices", subscriptionHandler.GetSubscriptionPrices)
+			subscriptions.GET("/:user_id/:subscription_id/history", auditHandler.GetSubscriptionHistory)
 			subscriptions.GET("/user/:user_id", subscriptionHandler.GetUserSubscriptions)
+			subscriptions.GET("/user/:user_id/trials-ending", subscriptionHandler.GetEndingTrials)
 			subscriptions.GET("/cost", subscriptionHandler.CalculateTotalCostQuery)
 		}
+
+		services := v1.Group("/services")
+		{
+			services.POST("", RequireAdmin(), catalogHandler.CreateService)
+			services.GET("", catalogHandler.ListServices)
+			services.GET("/resolve", catalogHandler.ResolveService)
+			services.GET("/:service_id", catalogHandler.GetService)
+			services.PUT("/:service_id", RequireAdmin(), catalogHandler.UpdateService)
+			services.DELETE("/:service_id", RequireAdmin(), catalogHandler.DeleteService)
+		}
+
+		admin := v1.Group("/admin", RequireAdmin())
+		{
+			admin.GET("/audit", auditHandler.ListAuditEntries)
+			admin.GET("/exchange-rates", exchangeRateHandler.ListExchangeRates)
+			admin.PUT("/exchange-rates/:base/:quote", exchangeRateHandler.SetExchangeRate)
+		}
+	}
+
+	// Operational endpoints, which are not part of the versioned API
+	admin := router.Group("/admin")
+	if verifier != nil {
+		admin.Use(AuthMiddleware(verifier))
+	}
+	admin.Use(RequireAdmin())
+	{
+		admin.GET("/log-level", logLevelHandler.GetLogLevel)
+		admin.PUT("/log-level", logLevelHandler.SetLogLevel)
 	}
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	router.NoRoute(RouteNotFound)
+
 	return router
 }
 
@@ -45,8 +109,9 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, Idempotency-Key, If-Match, If-None-Match")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, Idempotent-Replayed, ETag, Content-Disposition")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
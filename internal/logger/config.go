@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Setup configures the global logger. It has to be called before the global logger is first used.
+func Setup(cfg config.LoggingConfig) error {
+	l, err := NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	applied := false
+	initOnce.Do(func() {
+		globalLogger = l
+		applied = true
+	})
+	if !applied {
+		return errors.New("global logger is already initialized")
+	}
+	return nil
+}
+
+// NewFromConfig creates a logger with the level, encoding, outputs and options of the configuration
+func NewFromConfig(cfg config.LoggingConfig) (Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	switch cfg.Encoding {
+	case "json", "":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("unknown log encoding %q", cfg.Encoding)
+	}
+
+	writers := make([]io.Writer, 0, len(cfg.Outputs))
+	for _, output := range cfg.Outputs {
+		switch output {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		case "file":
+			if cfg.File.Path == "" {
+				return nil, errors.New("log file path is required for the file output")
+			}
+			writers = append(writers, newRotatingFile(cfg.File))
+		default:
+			return nil, fmt.Errorf("unknown log output %q", output)
+		}
+	}
+
+	var opts []zap.Option
+	if cfg.Caller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if cfg.StacktraceLevel != "" {
+		stacktraceLevel, err := ParseLevel(cfg.StacktraceLevel)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, zap.AddStacktrace(toZapLevel(stacktraceLevel)))
+	}
+
+	return newLogger(encoder, zap.NewAtomicLevelAt(toZapLevel(level)), writers, opts...), nil
+}
+
+// rotatingFile is a log file rotated by lumberjack when it grows over the maximum size, and
+// additionally each time the rotation interval passes.
+type rotatingFile struct {
+	*lumberjack.Logger
+	interval time.Duration
+
+	mu       sync.Mutex
+	rotateAt time.Time
+}
+
+func newRotatingFile(cfg config.LogFileConfig) *rotatingFile {
+	return &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   cfg.Path,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxAge:     cfg.MaxAgeDays,
+			MaxBackups: cfg.MaxBackups,
+			Compress:   cfg.Compress,
+		},
+		interval: cfg.RotationInterval,
+	}
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.interval > 0 {
+		f.mu.Lock()
+		now := time.Now()
+		if f.rotateAt.IsZero() {
+			f.rotateAt = now.Add(f.interval)
+		} else if !now.Before(f.rotateAt) {
+			if err := f.Rotate(); err != nil {
+				f.mu.Unlock()
+				return 0, err
+			}
+			f.rotateAt = now.Add(f.interval)
+		}
+		f.mu.Unlock()
+	}
+
+	return f.Logger.Write(p)
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, level)
+	assert.Equal(t, "warn", level.String())
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestNewFromConfig_FileOutputAndLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := NewFromConfig(config.LoggingConfig{
+		Level:    "warn",
+		Encoding: "console",
+		Outputs:  []string{"file"},
+		File:     config.LogFileConfig{Path: path},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, l.Level())
+
+	l.Info("dropped")
+	l.Warn("kept")
+
+	l.SetLevel(DebugLevel)
+	assert.Equal(t, DebugLevel, l.With(String("key", "value")).Level(), "derived loggers share the level")
+	l.Debug("kept after level change")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "dropped")
+	assert.Contains(t, string(data), "WARN\tkept")
+	assert.Contains(t, string(data), "kept after level change")
+}
+
+func TestNewFromConfig_RequiresFilePath(t *testing.T) {
+	_, err := NewFromConfig(config.LoggingConfig{Level: "info", Encoding: "json", Outputs: []string{"file"}})
+	assert.Error(t, err)
+}
+
+func TestRotatingFile_RotatesAfterInterval(t *testing.T) {
+	dir := t.TempDir()
+	f := newRotatingFile(config.LogFileConfig{
+		Path:             filepath.Join(dir, "app.log"),
+		RotationInterval: 20 * time.Millisecond,
+	})
+	defer f.Close()
+
+	_, err := f.Write([]byte("first\n"))
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	_, err = f.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "the first file is kept as a backup")
+}
@@ -0,0 +1,20 @@
+package logger
+
+import "context"
+
+type loggerKey struct{}
+
+// WithContext returns a copy of ctx carrying the logger
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx, or the global logger if there is none
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(Logger); ok {
+			return l
+		}
+	}
+	return Global()
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext_ReturnsStoredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf).With(String("request_id", "abc"))
+
+	ctx := WithContext(context.Background(), l)
+	FromContext(ctx).Info("handled")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "handled", entry["msg"])
+	assert.Equal(t, "abc", entry["request_id"])
+}
+
+func TestFromContext_FallsBackToGlobal(t *testing.T) {
+	assert.Same(t, Global(), FromContext(context.Background()))
+}
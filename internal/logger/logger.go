@@ -1,6 +1,10 @@
 package logger
 
-import "sync"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
 // Level represents a level of logging. If the level set in the logger is higher than it,
 // the message will not be logged.
@@ -15,6 +19,33 @@ const (
 	FatalLevel
 )
 
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	PanicLevel: "panic",
+	FatalLevel: "fatal",
+}
+
+// String returns the lowercase name of the level
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel returns the level with the given name, ignoring case
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return InfoLevel, fmt.Errorf("unknown log level %q", name)
+}
+
 var (
 	globalLogger Logger
 	initOnce     sync.Once
@@ -30,6 +61,7 @@ type Logger interface {
 	With(fields ...Field) Logger
 	Sync() error
 	SetLevel(level Level)
+	Level() Level
 }
 
 // Field represents a json field in a log message.
@@ -17,11 +17,14 @@ type loggerImpl struct {
 
 // New creates a new logger instance that writes logs to the provided io.Writer interfaces.
 func New(writers ...io.Writer) Logger {
-	level := zap.NewAtomicLevelAt(zap.InfoLevel)
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	encoder := zapcore.NewJSONEncoder(encoderConfig)
 
+	return newLogger(encoder, zap.NewAtomicLevelAt(zap.InfoLevel), writers, zap.AddCaller())
+}
+
+func newLogger(encoder zapcore.Encoder, level zap.AtomicLevel, writers []io.Writer, opts ...zap.Option) *loggerImpl {
 	cores := make([]zapcore.Core, 0, len(writers))
 	for _, w := range writers {
 		core := zapcore.NewCore(
@@ -35,13 +38,23 @@ func New(writers ...io.Writer) Logger {
 	teedCore := zapcore.NewTee(cores...)
 
 	return &loggerImpl{
-		zapLogger: zap.New(teedCore, zap.AddCaller(), zap.AddCallerSkip(1)),
+		zapLogger: zap.New(teedCore, append(opts, zap.AddCallerSkip(1))...),
 		level:     level,
 		encoder:   encoder,
 		teedCore:  teedCore,
 	}
 }
 
+// NewWithCore creates a logger that writes to the given zap core, such as an observer in tests.
+// The core decides which levels are logged.
+func NewWithCore(core zapcore.Core) Logger {
+	return &loggerImpl{
+		zapLogger: zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)),
+		level:     zap.NewAtomicLevelAt(zap.DebugLevel),
+		teedCore:  core,
+	}
+}
+
 // Initialize sets up the global logger instance with the specified writers. Thread-safe.
 func Initialize(writers ...io.Writer) {
 	initOnce.Do(func() {
@@ -127,6 +140,11 @@ func (l *loggerImpl) SetLevel(level Level) {
 	l.level.SetLevel(toZapLevel(level))
 }
 
+// Level returns the current logging level of this logger instance.
+func (l *loggerImpl) Level() Level {
+	return fromZapLevel(l.level.Level())
+}
+
 func convertFields(fields []Field) []zap.Field {
 	zapFields := make([]zap.Field, len(fields))
 	for i, f := range fields {
@@ -153,3 +171,20 @@ func toZapLevel(level Level) zapcore.Level {
 		return zap.InfoLevel
 	}
 }
+
+func fromZapLevel(level zapcore.Level) Level {
+	switch level {
+	case zap.DebugLevel:
+		return DebugLevel
+	case zap.WarnLevel:
+		return WarnLevel
+	case zap.ErrorLevel:
+		return ErrorLevel
+	case zap.PanicLevel, zap.DPanicLevel:
+		return PanicLevel
+	case zap.FatalLevel:
+		return FatalLevel
+	default:
+		return InfoLevel
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subscription_aggregator"
+
+// Results of instrumented calls
+const (
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+// Metrics owns the Prometheus registry of the application and the metrics shared by the HTTP
+// middleware and the instrumented service and repository
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	serviceCalls    *prometheus.CounterVec
+	serviceDuration *prometheus.HistogramVec
+
+	repositoryCalls    *prometheus.CounterVec
+	repositoryDuration *prometheus.HistogramVec
+}
+
+// New creates the registry with the Go runtime and process collectors and the application metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		serviceCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "service_calls_total",
+			Help:      "Number of service method calls by result.",
+		}, []string{"service", "method", "result"}),
+		serviceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "service_call_duration_seconds",
+			Help:      "Duration of service method calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method"}),
+		repositoryCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "repository_calls_total",
+			Help:      "Number of repository method calls by result.",
+		}, []string{"repository", "method", "result"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_call_duration_seconds",
+			Help:      "Duration of repository method calls.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.httpInFlight,
+		m.serviceCalls,
+		m.serviceDuration,
+		m.repositoryCalls,
+		m.repositoryDuration,
+	)
+
+	return m
+}
+
+// Registry returns the registry to add further collectors to
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDBStats exposes the connection pool stats of the database
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// RegisterSubscriptionStats exposes the business gauges, queried from repo on every scrape
+func (m *Metrics) RegisterSubscriptionStats(repo repository.StatsRepository) {
+	m.registry.MustRegister(newStatsCollector(repo))
+}
+
+// HTTPRequestStarted counts a request in flight. The returned function records its completion.
+func (m *Metrics) HTTPRequestStarted() func(method, route string, status int) {
+	start := time.Now()
+	m.httpInFlight.Inc()
+
+	return func(method, route string, status int) {
+		m.httpInFlight.Dec()
+		m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) observeService(service, method string, start time.Time, err error) {
+	m.serviceCalls.WithLabelValues(service, method, result(err)).Inc()
+	m.serviceDuration.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) observeRepository(repository, method string, start time.Time, err error) {
+	m.repositoryCalls.WithLabelValues(repository, method, result(err)).Inc()
+	m.repositoryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultOK
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriptionService implements only the methods used by the tests
+type fakeSubscriptionService struct {
+	service.SubscriptionService
+	err error
+}
+
+func (s *fakeSubscriptionService) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error {
+	return s.err
+}
+
+type fakeStatsRepository struct {
+	stats *repository.SubscriptionStats
+	err   error
+}
+
+func (r *fakeStatsRepository) GetSubscriptionStats(ctx context.Context) (*repository.SubscriptionStats, error) {
+	return r.stats, r.err
+}
+
+func TestInstrumentSubscriptionService_CountsResults(t *testing.T) {
+	m := New()
+	next := &fakeSubscriptionService{}
+	svc := InstrumentSubscriptionService(next, m)
+
+	require.NoError(t, svc.DeleteSubscription(context.Background(), "user", 1, 0))
+	next.err = errors.New("boom")
+	require.Error(t, svc.DeleteSubscription(context.Background(), "user", 1, 0))
+	require.Error(t, svc.DeleteSubscription(context.Background(), "user", 1, 0))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.serviceCalls.WithLabelValues("subscription", "DeleteSubscription", resultOK)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.serviceCalls.WithLabelValues("subscription", "DeleteSubscription", resultError)))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.serviceDuration))
+}
+
+func TestHTTPRequestStarted(t *testing.T) {
+	m := New()
+
+	done := m.HTTPRequestStarted()
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpInFlight))
+
+	done("GET", "/api/v1/subscriptions/:user_id/:subscription_id", 404)
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.httpInFlight))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.httpDuration, "subscription_aggregator_http_request_duration_seconds"))
+}
+
+func TestSubscriptionStats(t *testing.T) {
+	repo := &fakeStatsRepository{stats: &repository.SubscriptionStats{
+		ActiveSubscriptions: 42,
+		TrialSubscriptions:  3,
+		ActiveUsers:         17,
+	}}
+	collector := newStatsCollector(repo)
+
+	expected := `
+# HELP subscription_aggregator_subscriptions_active Number of subscriptions that have not ended.
+# TYPE subscription_aggregator_subscriptions_active gauge
+subscription_aggregator_subscriptions_active 42
+# HELP subscription_aggregator_subscriptions_in_trial Number of active subscriptions in a free trial.
+# TYPE subscription_aggregator_subscriptions_in_trial gauge
+subscription_aggregator_subscriptions_in_trial 3
+# HELP subscription_aggregator_users_active Number of users with at least one active subscription.
+# TYPE subscription_aggregator_users_active gauge
+subscription_aggregator_users_active 17
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	repo.err = errors.New("database is down")
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
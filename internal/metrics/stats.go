@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsQueryTimeout bounds the stats query of a scrape
+const statsQueryTimeout = 5 * time.Second
+
+// statsCollector reports the subscription counts. The counts are queried on each scrape, so
+// they are never stale and cost nothing between scrapes.
+type statsCollector struct {
+	repo repository.StatsRepository
+
+	activeSubscriptions *prometheus.Desc
+	trialSubscriptions  *prometheus.Desc
+	activeUsers         *prometheus.Desc
+}
+
+func newStatsCollector(repo repository.StatsRepository) *statsCollector {
+	return &statsCollector{
+		repo: repo,
+		activeSubscriptions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "subscriptions_active"),
+			"Number of subscriptions that have not ended.", nil, nil),
+		trialSubscriptions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "subscriptions_in_trial"),
+			"Number of active subscriptions in a free trial.", nil, nil),
+		activeUsers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "users_active"),
+			"Number of users with at least one active subscription.", nil, nil),
+	}
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeSubscriptions
+	ch <- c.trialSubscriptions
+	ch <- c.activeUsers
+}
+
+// Collect skips the gauges when the query fails rather than reporting zeros
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsQueryTimeout)
+	defer cancel()
+
+	stats, err := c.repo.GetSubscriptionStats(ctx)
+	if err != nil {
+		logger.Global().Warn("failed to collect subscription stats", logger.Error(err))
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.activeSubscriptions, prometheus.GaugeValue, float64(stats.ActiveSubscriptions))
+	ch <- prometheus.MustNewConstMetric(c.trialSubscriptions, prometheus.GaugeValue, float64(stats.TrialSubscriptions))
+	ch <- prometheus.MustNewConstMetric(c.activeUsers, prometheus.GaugeValue, float64(stats.ActiveUsers))
+}
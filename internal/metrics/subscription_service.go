@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+)
+
+const subscriptionServiceLabel = "subscription"
+
+type subscriptionService struct {
+	next    service.SubscriptionService
+	metrics *Metrics
+}
+
+// InstrumentSubscriptionService wraps the service to count its calls and measure their duration
+func InstrumentSubscriptionService(next service.SubscriptionService, m *Metrics) service.SubscriptionService {
+	return &subscriptionService{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (s *subscriptionService) observe(method string, start time.Time, err *error) {
+	s.metrics.observeService(subscriptionServiceLabel, method, start, *err)
+}
+
+func (s *subscriptionService) CreateSubscription(ctx context.Context, req *service.CreateSubscriptionRequest) (_ *repository.Subscription, err error) {
+	defer s.observe("CreateSubscription", time.Now(), &err)
+	return s.next.CreateSubscription(ctx, req)
+}
+
+func (s *subscriptionService) ImportSubscriptions(ctx context.Context, req *service.ImportSubscriptionsRequest) (_ *service.ImportReport, err error) {
+	defer s.observe("ImportSubscriptions", time.Now(), &err)
+	return s.next.ImportSubscriptions(ctx, req)
+}
+
+func (s *subscriptionService) GetSubscription(ctx context.Context, userID string, subscriptionID int) (_ *repository.Subscription, err error) {
+	defer s.observe("GetSubscription", time.Now(), &err)
+	return s.next.GetSubscription(ctx, userID, subscriptionID)
+}
+
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID string, subscriptionID int, req *service.UpdateSubscriptionRequest, expectedVersion int) (_ *repository.Subscription, err error) {
+	defer s.observe("UpdateSubscription", time.Now(), &err)
+	return s.next.UpdateSubscription(ctx, userID, subscriptionID, req, expectedVersion)
+}
+
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) (err error) {
+	defer s.observe("DeleteSubscription", time.Now(), &err)
+	return s.next.DeleteSubscription(ctx, userID, subscriptionID, expectedVersion)
+}
+
+func (s *subscriptionService) PatchSubscription(ctx context.Context, userID string, subscriptionID int, req *service.PatchSubscriptionRequest, expectedVersion int) (_ *repository.Subscription, err error) {
+	defer s.observe("PatchSubscription", time.Now(), &err)
+	return s.next.PatchSubscription(ctx, userID, subscriptionID, req, expectedVersion)
+}
+
+func (s *subscriptionService) GetUserSubscriptions(ctx context.Context, userID string) (_ []*repository.Subscription, err error) {
+	defer s.observe("GetUserSubscriptions", time.Now(), &err)
+	return s.next.GetUserSubscriptions(ctx, userID)
+}
+
+func (s *subscriptionService) ListUserSubscriptions(ctx context.Context, req *service.ListSubscriptionsRequest) (_ *service.SubscriptionsPage, err error) {
+	defer s.observe("ListUserSubscriptions", time.Now(), &err)
+	return s.next.ListUserSubscriptions(ctx, req)
+}
+
+func (s *subscriptionService) GetEndingTrials(ctx context.Context, userID string, within string) (_ []*repository.Subscription, err error) {
+	defer s.observe("GetEndingTrials", time.Now(), &err)
+	return s.next.GetEndingTrials(ctx, userID, within)
+}
+
+func (s *subscriptionService) AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, req *service.AddSubscriptionPriceRequest) (_ []*repository.SubscriptionPrice, _ int, err error) {
+	defer s.observe("AddSubscriptionPrice", time.Now(), &err)
+	return s.next.AddSubscriptionPrice(ctx, userID, subscriptionID, req)
+}
+
+func (s *subscriptionService) GetSubscriptionPrices(ctx context.Context, userID string, subscriptionID int) (_ []*repository.SubscriptionPrice, err error) {
+	defer s.observe("GetSubscriptionPrices", time.Now(), &err)
+	return s.next.GetSubscriptionPrices(ctx, userID, subscriptionID)
+}
+
+func (s *subscriptionService) CalculateTotalCost(ctx context.Context, req *service.GetCostRequest) (_ *service.CostResponse, err error) {
+	defer s.observe("CalculateTotalCost", time.Now(), &err)
+	return s.next.CalculateTotalCost(ctx, req)
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+const subscriptionsRepositoryLabel = "subscriptions"
+
+type subscriptionsRepository struct {
+	next    repository.SubscriptionsRepository
+	metrics *Metrics
+}
+
+// InstrumentSubscriptionsRepository wraps the repository to count its calls and measure their
+// duration. Close and RunMigrations are passed through as they aren't part of request handling.
+func InstrumentSubscriptionsRepository(next repository.SubscriptionsRepository, m *Metrics) repository.SubscriptionsRepository {
+	return &subscriptionsRepository{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (r *subscriptionsRepository) observe(method string, start time.Time, err *error) {
+	r.metrics.observeRepository(subscriptionsRepositoryLabel, method, start, *err)
+}
+
+func (r *subscriptionsRepository) Create(ctx context.Context, subscription *repository.Subscription) (err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, subscription)
+}
+
+func (r *subscriptionsRepository) CreateSubscriptions(ctx context.Context, subscriptions []*repository.Subscription) (err error) {
+	defer r.observe("CreateSubscriptions", time.Now(), &err)
+	return r.next.CreateSubscriptions(ctx, subscriptions)
+}
+
+func (r *subscriptionsRepository) GetSubscription(ctx context.Context, userID string, subscriptionID int) (_ *repository.Subscription, err error) {
+	defer r.observe("GetSubscription", time.Now(), &err)
+	return r.next.GetSubscription(ctx, userID, subscriptionID)
+}
+
+func (r *subscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *repository.Subscription, userID string, subscriptionID int, expectedVersion int) (err error) {
+	defer r.observe("UpdateSubscription", time.Now(), &err)
+	return r.next.UpdateSubscription(ctx, subscription, userID, subscriptionID, expectedVersion)
+}
+
+func (r *subscriptionsRepository) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) (err error) {
+	defer r.observe("DeleteSubscription", time.Now(), &err)
+	return r.next.DeleteSubscription(ctx, userID, subscriptionID, expectedVersion)
+}
+
+func (r *subscriptionsRepository) PatchSubscription(ctx context.Context, subscription *repository.Subscription, columns []string, userID string, subscriptionID int, expectedVersion int) (err error) {
+	defer r.observe("PatchSubscription", time.Now(), &err)
+	return r.next.PatchSubscription(ctx, subscription, columns, userID, subscriptionID, expectedVersion)
+}
+
+func (r *subscriptionsRepository) GetSubscriptionsByUserID(ctx context.Context, userID string) (_ []*repository.Subscription, err error) {
+	defer r.observe("GetSubscriptionsByUserID", time.Now(), &err)
+	return r.next.GetSubscriptionsByUserID(ctx, userID)
+}
+
+func (r *subscriptionsRepository) ListSubscriptions(ctx context.Context, filter repository.SubscriptionFilter) (_ []*repository.Subscription, _ int, err error) {
+	defer r.observe("ListSubscriptions", time.Now(), &err)
+	return r.next.ListSubscriptions(ctx, filter)
+}
+
+func (r *subscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, serviceIDs []int, startDate, endDate time.Time) (_ []*repository.Subscription, err error) {
+	defer r.observe("GetSubscriptionsByPeriod", time.Now(), &err)
+	return r.next.GetSubscriptionsByPeriod(ctx, userID, serviceNames, serviceIDs, startDate, endDate)
+}
+
+func (r *subscriptionsRepository) GetTrialsEndingBetween(ctx context.Context, userID string, from, to time.Time) (_ []*repository.Subscription, err error) {
+	defer r.observe("GetTrialsEndingBetween", time.Now(), &err)
+	return r.next.GetTrialsEndingBetween(ctx, userID, from, to)
+}
+
+func (r *subscriptionsRepository) AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, price *repository.SubscriptionPrice) (_ int, err error) {
+	defer r.observe("AddSubscriptionPrice", time.Now(), &err)
+	return r.next.AddSubscriptionPrice(ctx, userID, subscriptionID, price)
+}
+
+func (r *subscriptionsRepository) GetSubscriptionPrices(ctx context.Context, subscriptionIDs []int) (_ []*repository.SubscriptionPrice, err error) {
+	defer r.observe("GetSubscriptionPrices", time.Now(), &err)
+	return r.next.GetSubscriptionPrices(ctx, subscriptionIDs)
+}
+
+func (r *subscriptionsRepository) GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string) (_ *repository.ExchangeRate, err error) {
+	defer r.observe("GetExchangeRate", time.Now(), &err)
+	return r.next.GetExchangeRate(ctx, baseCurrency, quoteCurrency)
+}
+
+func (r *subscriptionsRepository) Close() error {
+	return r.next.Close()
+}
+
+func (r *subscriptionsRepository) RunMigrations(migrationsFilePath string) error {
+	return r.next.RunMigrations(migrationsFilePath)
+}
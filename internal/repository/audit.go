@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited operations on subscriptions
+const (
+	AuditOperationCreate      = "create"
+	AuditOperationUpdate      = "update"
+	AuditOperationDelete      = "delete"
+	AuditOperationPriceChange = "price_change"
+)
+
+// AuditEntry records one mutation of a subscription together with its state before and after.
+// OldData is empty for creations, NewData for deletions.
+type AuditEntry struct {
+	ID             int64           `db:"id" json:"id"`
+	SubscriptionID int             `db:"subscription_id" json:"subscription_id"`
+	UserID         string          `db:"user_id" json:"user_id"` // Owner of the subscription
+	Actor          string          `db:"actor" json:"actor"`     // Subject of the token that made the change
+	RequestID      string          `db:"request_id" json:"request_id"`
+	Operation      string          `db:"operation" json:"operation"`
+	OldData        json.RawMessage `db:"old_data" json:"old_data,omitempty"`
+	NewData        json.RawMessage `db:"new_data" json:"new_data,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter selects audit entries, newest first. Empty fields don't filter.
+type AuditFilter struct {
+	UserID         string
+	SubscriptionID int
+	Actor          string
+	Operation      string
+	From           *time.Time // Inclusive
+	To             *time.Time // Inclusive
+	BeforeID       int64      // Keyset position, 0 for the first page
+	Limit          int
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of repository failures. Implementations wrap their errors with one of them when the cause
+// is known, so that callers can tell them apart with errors.Is.
+var (
+	// ErrNotFound is returned when the requested row doesn't exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned when a change collides with existing data or a concurrent transaction
+	ErrConflict = errors.New("conflict")
+
+	// ErrConstraintViolation is returned when a change breaks a check, not-null or foreign key constraint
+	ErrConstraintViolation = errors.New("constraint violation")
+
+	// ErrUnavailable is returned when the database can't be reached or refuses connections
+	ErrUnavailable = errors.New("database unavailable")
+
+	// ErrTimeout is returned when a statement is cancelled by a deadline or the statement timeout
+	ErrTimeout = errors.New("database timeout")
+
+	// ErrVersionMismatch is returned when a conditional change expected another version of the row
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+var (
+	// ErrExchangeRateNotFound is returned when no rate is stored for the requested currency pair
+	ErrExchangeRateNotFound = fmt.Errorf("exchange rate %w", ErrNotFound)
+
+	// ErrServiceNotFound is returned when no catalog entry matches the requested ID or name
+	ErrServiceNotFound = fmt.Errorf("service %w", ErrNotFound)
+
+	// ErrServiceAlreadyExists is returned when a catalog name or alias is already taken
+	ErrServiceAlreadyExists = fmt.Errorf("service already exists: %w", ErrConflict)
+)
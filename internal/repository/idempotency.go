@@ -0,0 +1,18 @@
+package repository
+
+import "time"
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key. Keys are
+// unique per scope, the caller the key belongs to. StatusCode is 0 while the first request with
+// the key is still being processed, which it may be until the record expires.
+type IdempotencyRecord struct {
+	Scope        string    `db:"scope"`
+	Key          string    `db:"idempotency_key"`
+	Fingerprint  string    `db:"fingerprint"` // Hash of the request the key was first used with
+	StatusCode   int       `db:"status_code"`
+	ContentType  string    `db:"content_type"`
+	ETag         string    `db:"etag"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/requestid"
+	"github.com/jmoiron/sqlx"
+)
+
+// anonymousActor is recorded for changes made while authentication is disabled
+const anonymousActor = "anonymous"
+
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditRepository creates a new instance of PostgreSQL audit log repository
+func NewAuditRepository(db *sqlx.DB) repository.AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// auditRow is an audit_log row as scanned from the database. database/sql can't store NULL
+// in json.RawMessage, so the snapshots are scanned as plain bytes.
+type auditRow struct {
+	repository.AuditEntry
+	OldData []byte `db:"old_data"`
+	NewData []byte `db:"new_data"`
+}
+
+func auditRowsToEntries(rows []auditRow) []*repository.AuditEntry {
+	entries := make([]*repository.AuditEntry, len(rows))
+	for i := range rows {
+		entry := rows[i].AuditEntry
+		entry.OldData = rows[i].OldData
+		entry.NewData = rows[i].NewData
+		entries[i] = &entry
+	}
+	return entries
+}
+
+// insertAuditEntry records a subscription mutation within the mutation's transaction. The actor and
+// request ID are taken from the request context.
+func insertAuditEntry(ctx context.Context, tx *sqlx.Tx, operation string, userID string, subscriptionID int, oldData, newData *repository.Subscription) error {
+	query := `
+		INSERT INTO audit_log (subscription_id, user_id, actor, request_id, operation, old_data, new_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	actor := anonymousActor
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		actor = identity.Subject
+	}
+
+	oldJSON, err := auditData(oldData)
+	if err != nil {
+		return err
+	}
+	newJSON, err := auditData(newData)
+	if err != nil {
+		return err
+	}
+
+	_, err = execContext(ctx, tx, "audit_log.insert", query,
+		subscriptionID,
+		userID,
+		actor,
+		requestid.FromContext(ctx),
+		operation,
+		oldJSON,
+		newJSON)
+	return err
+}
+
+// auditData encodes a subscription snapshot for a JSONB column. lib/pq sends []byte as bytea,
+// so the JSON is passed as a string.
+func auditData(subscription *repository.Subscription) (interface{}, error) {
+	if subscription == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// GetSubscriptionHistory retrieves the audit entries of a subscription, oldest first. Entries of
+// deleted subscriptions are kept.
+func (r *auditRepository) GetSubscriptionHistory(ctx context.Context, userID string, subscriptionID int) ([]*repository.AuditEntry, error) {
+	query := `
+		SELECT id, subscription_id, user_id, actor, request_id, operation, old_data, new_data, created_at
+		FROM audit_log
+		WHERE user_id = $1 AND subscription_id = $2
+		ORDER BY id ASC`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Getting subscription history",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID))
+
+	rows := []auditRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, userID, subscriptionID); err != nil {
+		log.Error("Failed to get subscription history",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, wrapError(ErrGetAuditEntriesFailed, err)
+	}
+
+	return auditRowsToEntries(rows), nil
+}
+
+// ListAuditEntries retrieves audit entries matching the filter, newest first
+func (r *auditRepository) ListAuditEntries(ctx context.Context, filter repository.AuditFilter) ([]*repository.AuditEntry, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.UserID != "" {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if filter.SubscriptionID != 0 {
+		addCondition("subscription_id = $%d", filter.SubscriptionID)
+	}
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if filter.Operation != "" {
+		addCondition("operation = $%d", filter.Operation)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at <= $%d", *filter.To)
+	}
+	if filter.BeforeID > 0 {
+		addCondition("id < $%d", filter.BeforeID)
+	}
+
+	queryBuilder := strings.Builder{}
+	queryBuilder.WriteString(`
+		SELECT id, subscription_id, user_id, actor, request_id, operation, old_data, new_data, created_at
+		FROM audit_log`)
+	if len(conditions) > 0 {
+		queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	args = append(args, filter.Limit)
+	queryBuilder.WriteString(fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args)))
+
+	log := logger.FromContext(ctx)
+	log.Debug("Listing audit entries",
+		logger.String("user_id", filter.UserID),
+		logger.String("actor", filter.Actor),
+		logger.Int("limit", filter.Limit))
+
+	rows := []auditRow{}
+	if err := r.db.SelectContext(ctx, &rows, queryBuilder.String(), args...); err != nil {
+		log.Error("Failed to list audit entries",
+			logger.Error(err))
+		return nil, wrapError(ErrGetAuditEntriesFailed, err)
+	}
+
+	return auditRowsToEntries(rows), nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+var auditColumns = []string{"id", "subscription_id", "user_id", "actor", "request_id", "operation", "old_data", "new_data", "created_at"}
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo repository.AuditRepository
+}
+
+func (suite *AuditRepositoryTestSuite) SetupTest() {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(suite.T(), err)
+
+	suite.db = sqlx.NewDb(mockDB, "postgres")
+	suite.mock = mock
+	suite.repo = NewAuditRepository(suite.db)
+}
+
+func (suite *AuditRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *AuditRepositoryTestSuite) TestGetSubscriptionHistory_Success() {
+	ctx := context.Background()
+	userID := "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	createdAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT id, subscription_id, user_id, actor, request_id, operation, old_data, new_data, created_at
+		FROM audit_log
+		WHERE user_id = $1 AND subscription_id = $2
+		ORDER BY id ASC`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, 5).
+		WillReturnRows(sqlmock.NewRows(auditColumns).
+			AddRow(1, 5, userID, "anonymous", "", "create", nil, []byte(`{"id":5,"price":399}`), createdAt).
+			AddRow(2, 5, userID, "admin-user", "req-1", "delete", []byte(`{"id":5,"price":399}`), nil, createdAt))
+
+	entries, err := suite.repo.GetSubscriptionHistory(ctx, userID, 5)
+
+	assert.NoError(suite.T(), err)
+	require.Len(suite.T(), entries, 2)
+	assert.Equal(suite.T(), repository.AuditOperationCreate, entries[0].Operation)
+	assert.Empty(suite.T(), entries[0].OldData)
+	assert.JSONEq(suite.T(), `{"id":5,"price":399}`, string(entries[0].NewData))
+	assert.Equal(suite.T(), "req-1", entries[1].RequestID)
+	assert.Empty(suite.T(), entries[1].NewData)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestListAuditEntries_Filters() {
+	ctx := context.Background()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT id, subscription_id, user_id, actor, request_id, operation, old_data, new_data, created_at
+		FROM audit_log WHERE actor = $1 AND operation = $2 AND created_at >= $3 AND id < $4 ORDER BY id DESC LIMIT $5`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs("admin-user", "update", from, int64(100), 51).
+		WillReturnRows(sqlmock.NewRows(auditColumns))
+
+	entries, err := suite.repo.ListAuditEntries(ctx, repository.AuditFilter{
+		Actor:     "admin-user",
+		Operation: repository.AuditOperationUpdate,
+		From:      &from,
+		BeforeID:  100,
+		Limit:     51,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), entries)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestListAuditEntries_DatabaseError() {
+	ctx := context.Background()
+
+	expectedQuery := `
+		SELECT id, subscription_id, user_id, actor, request_id, operation, old_data, new_data, created_at
+		FROM audit_log ORDER BY id DESC LIMIT $1`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(50).
+		WillReturnError(sql.ErrConnDone)
+
+	entries, err := suite.repo.ListAuditEntries(ctx, repository.AuditFilter{Limit: 50})
+
+	assert.Nil(suite.T(), entries)
+	assert.ErrorIs(suite.T(), err, ErrGetAuditEntriesFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func TestAuditRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
@@ -1,6 +1,16 @@
 package postgres
 
-import "errors"
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/lib/pq"
+)
 
 var (
 	// Create subscription errors
@@ -8,25 +18,134 @@ var (
 
 	// Get subscription errors
 	ErrGetSubscriptionFailed = errors.New("failed to get subscription")
-	ErrSubscriptionNotFound  = errors.New("subscription not found")
+	ErrSubscriptionNotFound  = fmt.Errorf("subscription %w", repository.ErrNotFound)
 
 	// Update subscription errors
 	ErrUpdateSubscriptionFailed      = errors.New("failed to update subscription")
 	ErrGetRowsAffectedFailed         = errors.New("failed to get rows affected")
-	ErrSubscriptionNotFoundForUpdate = errors.New("subscription not found")
+	ErrSubscriptionNotFoundForUpdate = fmt.Errorf("subscription %w", repository.ErrNotFound)
+	ErrSubscriptionVersionMismatch   = fmt.Errorf("subscription %w", repository.ErrVersionMismatch)
 
 	// Delete subscription errors
 	ErrDeleteSubscriptionFailed        = errors.New("failed to delete subscription")
-	ErrSubscriptionNotFoundForDeletion = errors.New("subscription not found")
+	ErrSubscriptionNotFoundForDeletion = fmt.Errorf("subscription %w", repository.ErrNotFound)
 
 	// Get subscriptions by user ID errors
 	ErrGetSubscriptionsByUserIDFailed = errors.New("failed to get subscriptions")
 
+	// List subscriptions errors
+	ErrListSubscriptionsFailed = errors.New("failed to list subscriptions")
+
 	// Get subscriptions by period errors
 	ErrGetSubscriptionsByPeriodFailed = errors.New("failed to get subscriptions by period")
 
+	// Get trials ending errors
+	ErrGetTrialsEndingFailed = errors.New("failed to get subscriptions with ending trials")
+
+	// Subscription price errors
+	ErrAddSubscriptionPriceFailed  = errors.New("failed to add subscription price")
+	ErrGetSubscriptionPricesFailed = errors.New("failed to get subscription prices")
+
+	// Audit log errors
+	ErrGetAuditEntriesFailed = errors.New("failed to get audit entries")
+
+	// Idempotency key errors
+	ErrReserveIdempotencyKeyFailed  = errors.New("failed to reserve idempotency key")
+	ErrCompleteIdempotencyKeyFailed = errors.New("failed to store idempotent response")
+	ErrReleaseIdempotencyKeyFailed  = errors.New("failed to release idempotency key")
+	ErrDeleteIdempotencyKeysFailed  = errors.New("failed to delete expired idempotency keys")
+
+	// Stats errors
+	ErrGetSubscriptionStatsFailed = errors.New("failed to get subscription stats")
+
+	// Exchange rate errors
+	ErrGetExchangeRateFailed   = errors.New("failed to get exchange rate")
+	ErrListExchangeRatesFailed = errors.New("failed to list exchange rates")
+	ErrSetExchangeRateFailed   = errors.New("failed to set exchange rate")
+	ErrExchangeRateNotFound    = repository.ErrExchangeRateNotFound
+
+	// Services catalog errors
+	ErrCreateServiceFailed  = errors.New("failed to create service")
+	ErrGetServiceFailed     = errors.New("failed to get service")
+	ErrListServicesFailed   = errors.New("failed to list services")
+	ErrUpdateServiceFailed  = errors.New("failed to update service")
+	ErrDeleteServiceFailed  = errors.New("failed to delete service")
+	ErrResolveServiceFailed = errors.New("failed to resolve service")
+	ErrServiceNotFound      = repository.ErrServiceNotFound
+	ErrServiceAlreadyExists = repository.ErrServiceAlreadyExists
+
 	// Migration errors
 	ErrCreateMigrationDriverFailed   = errors.New("failed to create migration driver")
 	ErrCreateMigrationInstanceFailed = errors.New("failed to create migration instance")
 	ErrRunMigrationsFailed           = errors.New("failed to run migrations")
+	ErrReadMigrationsFailed          = errors.New("failed to read migrations")
+	ErrGetMigrationVersionFailed     = errors.New("failed to get migration version")
+
+	// Health check errors
+	ErrPingFailed = errors.New("database is unreachable")
+)
+
+// PostgreSQL error codes and classes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	uniqueViolation      = "23505"
+	exclusionViolation   = "23P01"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	queryCanceled        = "57014"
+	lockNotAvailable     = "55P03"
+
+	connectionExceptionClass   = "08"
+	integrityViolationClass    = "23"
+	insufficientResourcesClass = "53"
+	operatorInterventionClass  = "57"
 )
+
+// wrapError returns the operation error, marked with the kind of its cause when the cause can be
+// classified, so that callers can tell an unreachable database from a failed query. Missing rows
+// are reported with the not found errors above instead.
+func wrapError(op error, err error) error {
+	kind := classifyError(err)
+	if kind == nil {
+		return op
+	}
+	return fmt.Errorf("%w: %w", op, kind)
+}
+
+// classifyError returns the repository error kind of a driver error, or nil if it has none
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == uniqueViolation || pqErr.Code == exclusionViolation,
+			pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected:
+			return repository.ErrConflict
+		case pqErr.Code.Class() == integrityViolationClass:
+			return repository.ErrConstraintViolation
+		case pqErr.Code == queryCanceled || pqErr.Code == lockNotAvailable:
+			return repository.ErrTimeout
+		case pqErr.Code.Class() == connectionExceptionClass,
+			pqErr.Code.Class() == insufficientResourcesClass,
+			pqErr.Code.Class() == operatorInterventionClass:
+			return repository.ErrUnavailable
+		}
+		return nil
+	}
+
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return repository.ErrTimeout
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return repository.ErrUnavailable
+	case errors.As(err, &opErr):
+		if opErr.Timeout() {
+			return repository.ErrTimeout
+		}
+		return repository.ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"unique violation", &pq.Error{Code: "23505"}, repository.ErrConflict},
+		{"serialization failure", &pq.Error{Code: "40001"}, repository.ErrConflict},
+		{"foreign key violation", &pq.Error{Code: "23503"}, repository.ErrConstraintViolation},
+		{"check violation", &pq.Error{Code: "23514"}, repository.ErrConstraintViolation},
+		{"statement timeout", &pq.Error{Code: "57014"}, repository.ErrTimeout},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, repository.ErrUnavailable},
+		{"too many connections", &pq.Error{Code: "53300"}, repository.ErrUnavailable},
+		{"connection failure", &pq.Error{Code: "08006"}, repository.ErrUnavailable},
+		{"syntax error", &pq.Error{Code: "42601"}, nil},
+		{"deadline exceeded", fmt.Errorf("query: %w", context.DeadlineExceeded), repository.ErrTimeout},
+		{"bad connection", driver.ErrBadConn, repository.ErrUnavailable},
+		{"connection done", sql.ErrConnDone, repository.ErrUnavailable},
+		{"dial failure", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, repository.ErrUnavailable},
+		{"unknown", errors.New("boom"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyError(tt.err))
+		})
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	err := wrapError(ErrGetSubscriptionFailed, driver.ErrBadConn)
+	assert.ErrorIs(t, err, ErrGetSubscriptionFailed)
+	assert.ErrorIs(t, err, repository.ErrUnavailable)
+
+	assert.Equal(t, ErrGetSubscriptionFailed, wrapError(ErrGetSubscriptionFailed, errors.New("boom")))
+	assert.ErrorIs(t, ErrSubscriptionNotFound, repository.ErrNotFound)
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+type exchangeRatesRepository struct {
+	db *sqlx.DB
+}
+
+// NewExchangeRatesRepository creates a new instance of PostgreSQL exchange rates repository
+func NewExchangeRatesRepository(db *sqlx.DB) repository.ExchangeRatesRepository {
+	return &exchangeRatesRepository{
+		db: db,
+	}
+}
+
+// ListExchangeRates retrieves all stored rates ordered by currency pair
+func (r *exchangeRatesRepository) ListExchangeRates(ctx context.Context) ([]*repository.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Listing exchange rates")
+
+	rates := []*repository.ExchangeRate{}
+	if err := selectContext(ctx, r.db, "exchange_rates.list", &rates, query); err != nil {
+		log.Error("Failed to list exchange rates",
+			logger.Error(err))
+		return nil, wrapError(ErrListExchangeRatesFailed, err)
+	}
+
+	return rates, nil
+}
+
+// SetExchangeRate inserts the rate of a currency pair or replaces the stored one
+func (r *exchangeRatesRepository) SetExchangeRate(ctx context.Context, rate *repository.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE
+		SET rate = EXCLUDED.rate, updated_at = NOW()
+		RETURNING updated_at`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Setting exchange rate",
+		logger.String("base_currency", rate.BaseCurrency),
+		logger.String("quote_currency", rate.QuoteCurrency))
+
+	if err := getContext(ctx, r.db, "exchange_rates.set", &rate.UpdatedAt, query, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate); err != nil {
+		log.Error("Failed to set exchange rate",
+			logger.Error(err),
+			logger.String("base_currency", rate.BaseCurrency),
+			logger.String("quote_currency", rate.QuoteCurrency))
+		return wrapError(ErrSetExchangeRateFailed, err)
+	}
+
+	log.Info("Exchange rate set successfully",
+		logger.String("base_currency", rate.BaseCurrency),
+		logger.String("quote_currency", rate.QuoteCurrency),
+		logger.Any("rate", rate.Rate))
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExchangeRatesRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo repository.ExchangeRatesRepository
+}
+
+func (suite *ExchangeRatesRepositoryTestSuite) SetupTest() {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(suite.T(), err)
+
+	suite.db = sqlx.NewDb(mockDB, "postgres")
+	suite.mock = mock
+	suite.repo = NewExchangeRatesRepository(suite.db)
+}
+
+func (suite *ExchangeRatesRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *ExchangeRatesRepositoryTestSuite) TestListExchangeRates_Success() {
+	ctx := context.Background()
+	updatedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate", "updated_at"}).
+			AddRow("EUR", "RUB", 90.25, updatedAt).
+			AddRow("USD", "RUB", 80.5, updatedAt))
+
+	rates, err := suite.repo.ListExchangeRates(ctx)
+
+	assert.NoError(suite.T(), err)
+	require.Len(suite.T(), rates, 2)
+	assert.Equal(suite.T(), "EUR", rates[0].BaseCurrency)
+	assert.Equal(suite.T(), 80.5, rates[1].Rate)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ExchangeRatesRepositoryTestSuite) TestSetExchangeRate_Success() {
+	ctx := context.Background()
+	updatedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	rate := &repository.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 80.5}
+
+	expectedQuery := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE
+		SET rate = EXCLUDED.rate, updated_at = NOW()
+		RETURNING updated_at`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs("USD", "RUB", 80.5).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(updatedAt))
+
+	err := suite.repo.SetExchangeRate(ctx, rate)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), updatedAt, rate.UpdatedAt)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ExchangeRatesRepositoryTestSuite) TestSetExchangeRate_DatabaseError() {
+	ctx := context.Background()
+	rate := &repository.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 80.5}
+
+	suite.mock.ExpectQuery(`
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE
+		SET rate = EXCLUDED.rate, updated_at = NOW()
+		RETURNING updated_at`).
+		WithArgs("USD", "RUB", 80.5).
+		WillReturnError(sql.ErrConnDone)
+
+	err := suite.repo.SetExchangeRate(ctx, rate)
+
+	assert.ErrorIs(suite.T(), err, ErrSetExchangeRateFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func TestExchangeRatesRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ExchangeRatesRepositoryTestSuite))
+}
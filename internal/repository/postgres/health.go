@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// undefinedTableCode is the PostgreSQL error code of a missing table
+const undefinedTableCode = "42P01"
+
+// migrationFilePattern matches golang-migrate up files such as 000001_init.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+type healthRepository struct {
+	db *sqlx.DB
+}
+
+// NewHealthRepository creates a new instance of PostgreSQL health repository
+func NewHealthRepository(db *sqlx.DB) repository.HealthRepository {
+	return &healthRepository{
+		db: db,
+	}
+}
+
+// Ping checks that the database accepts connections
+func (r *healthRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		logger.FromContext(ctx).Warn("Database ping failed",
+			logger.Error(err))
+		return wrapError(ErrPingFailed, err)
+	}
+	return nil
+}
+
+// MigrationVersion reads the schema version recorded by golang-migrate
+func (r *healthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+	var row struct {
+		Version uint `db:"version"`
+		Dirty   bool `db:"dirty"`
+	}
+	err := r.db.GetContext(ctx, &row, query)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &pqErr) && pqErr.Code == undefinedTableCode) {
+			return 0, false, nil
+		}
+		logger.FromContext(ctx).Warn("Failed to get migration version",
+			logger.Error(err))
+		return 0, false, wrapError(ErrGetMigrationVersionFailed, err)
+	}
+
+	return row.Version, row.Dirty, nil
+}
+
+// LatestMigrationVersion returns the highest version among the migrations in the directory,
+// that is the version the database is expected to be at after RunMigrations
+func LatestMigrationVersion(migrationsFilePath string) (uint, error) {
+	files, err := os.ReadDir(migrationsFilePath)
+	if err != nil {
+		logger.Global().Error("Failed to read migrations directory",
+			logger.Error(err),
+			logger.String("migrations_path", migrationsFilePath))
+		return 0, ErrReadMigrationsFailed
+	}
+
+	var latest uint
+	for _, file := range files {
+		match := migrationFilePattern.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo repository.HealthRepository
+}
+
+func (suite *HealthRepositoryTestSuite) SetupTest() {
+	mockDB, mock, err := sqlmock.New(
+		sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual),
+		sqlmock.MonitorPingsOption(true))
+	require.NoError(suite.T(), err)
+
+	suite.db = sqlx.NewDb(mockDB, "postgres")
+	suite.mock = mock
+	suite.repo = NewHealthRepository(suite.db)
+}
+
+func (suite *HealthRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *HealthRepositoryTestSuite) TestPing_Failure() {
+	suite.mock.ExpectPing().WillReturnError(assert.AnError)
+
+	err := suite.repo.Ping(context.Background())
+
+	assert.Equal(suite.T(), ErrPingFailed, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *HealthRepositoryTestSuite) TestMigrationVersion_Success() {
+	suite.mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(8, false))
+
+	version, dirty, err := suite.repo.MigrationVersion(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(8), version)
+	assert.False(suite.T(), dirty)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *HealthRepositoryTestSuite) TestMigrationVersion_NoMigrationsTable() {
+	suite.mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		WillReturnError(&pq.Error{Code: undefinedTableCode})
+
+	version, dirty, err := suite.repo.MigrationVersion(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.Zero(suite.T(), version)
+	assert.False(suite.T(), dirty)
+}
+
+func (suite *HealthRepositoryTestSuite) TestMigrationVersion_DatabaseError() {
+	suite.mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		WillReturnError(assert.AnError)
+
+	_, _, err := suite.repo.MigrationVersion(context.Background())
+
+	assert.Equal(suite.T(), ErrGetMigrationVersionFailed, err)
+}
+
+func TestHealthRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthRepositoryTestSuite))
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"000001_init.up.sql",
+		"000001_init.down.sql",
+		"000012_audit_log.up.sql",
+		"000012_audit_log.down.sql",
+		"000003_prices.up.sql",
+		"README.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	version, err := LatestMigrationVersion(dir)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(12), version)
+
+	_, err = LatestMigrationVersion(filepath.Join(dir, "missing"))
+	assert.Equal(t, ErrReadMigrationsFailed, err)
+}
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+
+// NewIdempotencyRepository creates a new instance of PostgreSQL idempotency key repository
+func NewIdempotencyRepository(db *sqlx.DB) repository.IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+// ReserveKey inserts a record for the key, taking over an expired record with the same key. The
+// primary key makes concurrent requests with one key reserve it only once. A reservation expires
+// after its lease, so that the key of a request that never completed can be used again.
+func (r *idempotencyRepository) ReserveKey(ctx context.Context, scope, key, fingerprint string, lease time.Duration) (string, *repository.IdempotencyRecord, error) {
+	reserveQuery := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			status_code = 0,
+			content_type = '',
+			etag = '',
+			response_body = NULL,
+			reservation = EXCLUDED.reservation,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING reservation`
+
+	getQuery := `
+		SELECT scope, idempotency_key, fingerprint, status_code, content_type, etag, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Reserving idempotency key",
+		logger.String("idempotency_key", key))
+
+	var reservation string
+	err := getContext(ctx, r.db, "idempotency_keys.reserve", &reservation, reserveQuery, scope, key, fingerprint, lease.Seconds())
+	if err == nil {
+		return reservation, nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Error("Failed to reserve idempotency key",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return "", nil, wrapError(ErrReserveIdempotencyKeyFailed, err)
+	}
+
+	// The key is in use, return the record it was used with
+	record := &repository.IdempotencyRecord{}
+	if err := getContext(ctx, r.db, "idempotency_keys.get", record, getQuery, scope, key); err != nil {
+		log.Error("Failed to get idempotency key",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return "", nil, wrapError(ErrReserveIdempotencyKeyFailed, err)
+	}
+
+	return "", record, nil
+}
+
+// CompleteKey stores the response of the request a key was reserved for and extends the record
+// from the lease of the reservation to the TTL of responses. Nothing is stored if a retry has taken
+// over the reservation since.
+func (r *idempotencyRepository) CompleteKey(ctx context.Context, scope, key, reservation string, statusCode int, contentType, etag string, body []byte, ttl time.Duration) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $4, content_type = $5, etag = $6, response_body = $7, expires_at = NOW() + make_interval(secs => $8)
+		WHERE scope = $1 AND idempotency_key = $2 AND reservation = $3`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Storing idempotent response",
+		logger.String("idempotency_key", key),
+		logger.Int("status", statusCode))
+
+	result, err := execContext(ctx, r.db, "idempotency_keys.complete", query, scope, key, reservation, statusCode, contentType, etag, body, ttl.Seconds())
+	if err != nil {
+		log.Error("Failed to store idempotent response",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return wrapError(ErrCompleteIdempotencyKeyFailed, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("Failed to get rows affected",
+			logger.Error(err))
+		return wrapError(ErrGetRowsAffectedFailed, err)
+	}
+	if rowsAffected == 0 {
+		log.Warn("Idempotent response not stored, the reservation was taken over after its lease",
+			logger.String("idempotency_key", key))
+	}
+
+	return nil
+}
+
+// ReleaseKey removes a reservation that has no response yet, unless a retry has taken it over
+func (r *idempotencyRepository) ReleaseKey(ctx context.Context, scope, key, reservation string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND reservation = $3 AND status_code = 0`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Releasing idempotency key",
+		logger.String("idempotency_key", key))
+
+	result, err := execContext(ctx, r.db, "idempotency_keys.release", query, scope, key, reservation)
+	if err != nil {
+		log.Error("Failed to release idempotency key",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return wrapError(ErrReleaseIdempotencyKeyFailed, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("Failed to get rows affected",
+			logger.Error(err))
+		return wrapError(ErrGetRowsAffectedFailed, err)
+	}
+	if rowsAffected == 0 {
+		log.Warn("Idempotency key not released, the reservation was taken over after its lease",
+			logger.String("idempotency_key", key))
+	}
+
+	return nil
+}
+
+// DeleteExpiredKeys removes the records whose TTL has passed
+func (r *idempotencyRepository) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`
+
+	log := logger.FromContext(ctx)
+
+	result, err := execContext(ctx, r.db, "idempotency_keys.delete_expired", query)
+	if err != nil {
+		log.Error("Failed to delete expired idempotency keys",
+			logger.Error(err))
+		return 0, wrapError(ErrDeleteIdempotencyKeysFailed, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("Failed to get rows affected",
+			logger.Error(err))
+		return 0, wrapError(ErrGetRowsAffectedFailed, err)
+	}
+
+	return rowsAffected, nil
+}
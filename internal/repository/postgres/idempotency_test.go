@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	reserveIdempotencyKeyQuery = `
+		INSERT INTO idempotency_keys (scope, idempotency_key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			status_code = 0,
+			content_type = '',
+			etag = '',
+			response_body = NULL,
+			reservation = EXCLUDED.reservation,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING reservation`
+
+	getIdempotencyKeyQuery = `
+		SELECT scope, idempotency_key, fingerprint, status_code, content_type, etag, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2`
+
+	completeIdempotencyKeyQuery = `
+		UPDATE idempotency_keys
+		SET status_code = $4, content_type = $5, etag = $6, response_body = $7, expires_at = NOW() + make_interval(secs => $8)
+		WHERE scope = $1 AND idempotency_key = $2 AND reservation = $3`
+
+	releaseIdempotencyKeyQuery = `
+		DELETE FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND reservation = $3 AND status_code = 0`
+)
+
+var idempotencyColumns = []string{"scope", "idempotency_key", "fingerprint", "status_code", "content_type", "etag", "response_body", "created_at", "expires_at"}
+
+type IdempotencyRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo repository.IdempotencyRepository
+}
+
+func (suite *IdempotencyRepositoryTestSuite) SetupTest() {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(suite.T(), err)
+
+	suite.db = sqlx.NewDb(mockDB, "postgres")
+	suite.mock = mock
+	suite.repo = NewIdempotencyRepository(suite.db)
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserveKey_Reserved() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(reserveIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "abc", float64(60)).
+		WillReturnRows(sqlmock.NewRows([]string{"reservation"}).AddRow("6f1c3e0a-5b7d-4f4e-9a51-2d8c0e7b9f10"))
+
+	reservation, record, err := suite.repo.ReserveKey(ctx, "user-1", "key-1", "abc", time.Minute)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "6f1c3e0a-5b7d-4f4e-9a51-2d8c0e7b9f10", reservation)
+	assert.Nil(suite.T(), record)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserveKey_ExistingRecord() {
+	ctx := context.Background()
+	createdAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectQuery(reserveIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "abc", float64(3600)).
+		WillReturnRows(sqlmock.NewRows([]string{"reservation"}))
+	suite.mock.ExpectQuery(getIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).
+			AddRow("user-1", "key-1", "abc", 201, "application/json", `"1"`, []byte(`{"id":5}`), createdAt, createdAt.Add(time.Hour)))
+
+	reservation, record, err := suite.repo.ReserveKey(ctx, "user-1", "key-1", "abc", time.Hour)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), reservation)
+	require.NotNil(suite.T(), record)
+	assert.Equal(suite.T(), 201, record.StatusCode)
+	assert.Equal(suite.T(), "application/json", record.ContentType)
+	assert.Equal(suite.T(), `"1"`, record.ETag)
+	assert.JSONEq(suite.T(), `{"id":5}`, string(record.ResponseBody))
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserveKey_DatabaseError() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(reserveIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "abc", float64(3600)).
+		WillReturnError(sql.ErrConnDone)
+
+	_, record, err := suite.repo.ReserveKey(ctx, "user-1", "key-1", "abc", time.Hour)
+
+	assert.Nil(suite.T(), record)
+	assert.ErrorIs(suite.T(), err, ErrReserveIdempotencyKeyFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestCompleteKey_Success() {
+	ctx := context.Background()
+
+	// The record outlives the lease of the reservation by the TTL of responses
+	suite.mock.ExpectExec(completeIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "token-1", 201, "application/json", `"1"`, []byte(`{"id":5}`), float64(86400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.CompleteKey(ctx, "user-1", "key-1", "token-1", 201, "application/json", `"1"`, []byte(`{"id":5}`), 24*time.Hour)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestCompleteKey_ReservationTakenOver() {
+	ctx := context.Background()
+
+	// A retry reserved the key after the lease ended, its record is left as it is
+	suite.mock.ExpectExec(completeIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "token-1", 201, "application/json", `"1"`, []byte(`{"id":5}`), float64(86400)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.CompleteKey(ctx, "user-1", "key-1", "token-1", 201, "application/json", `"1"`, []byte(`{"id":5}`), 24*time.Hour)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReleaseKey_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectExec(releaseIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "token-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.ReleaseKey(ctx, "user-1", "key-1", "token-1")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReleaseKey_ReservationTakenOver() {
+	ctx := context.Background()
+
+	suite.mock.ExpectExec(releaseIdempotencyKeyQuery).
+		WithArgs("user-1", "key-1", "token-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.ReleaseKey(ctx, "user-1", "key-1", "token-1")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestDeleteExpiredKeys_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at <= NOW()`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := suite.repo.DeleteExpiredKeys(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyRepositoryTestSuite))
+}
@@ -27,36 +27,120 @@ func NewSubscriptionsRepository(db *sqlx.DB) repository.SubscriptionsRepository
 	}
 }
 
-// Create inserts a new subscription into the database
+// Create inserts a new subscription into the database together with its initial price and audit entry
 func (r *subscriptionsRepository) Create(ctx context.Context, subscription *repository.Subscription) error {
-	query := `
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
-
-	log := logger.Global()
+	log := logger.FromContext(ctx)
 	log.Debug("Creating subscription",
 		logger.String("user_id", subscription.UserID),
 		logger.String("service_name", subscription.ServiceName),
 		logger.Int("price", subscription.Price))
 
-	err := r.db.QueryRowContext(ctx, query,
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err),
+			logger.String("user_id", subscription.UserID))
+		return wrapError(ErrCreateSubscriptionFailed, err)
+	}
+	defer tx.Rollback()
+
+	if err := insertSubscription(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit subscription creation",
+			logger.Error(err),
+			logger.String("user_id", subscription.UserID))
+		return wrapError(ErrCreateSubscriptionFailed, err)
+	}
+
+	log.Info("Subscription created successfully",
+		logger.Int("subscription_id", subscription.ID),
+		logger.String("user_id", subscription.UserID))
+
+	return nil
+}
+
+// CreateSubscriptions inserts several subscriptions in one transaction, so that either all of them
+// are created or none
+func (r *subscriptionsRepository) CreateSubscriptions(ctx context.Context, subscriptions []*repository.Subscription) error {
+	log := logger.FromContext(ctx)
+	log.Debug("Creating subscriptions",
+		logger.Int("count", len(subscriptions)))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err))
+		return wrapError(ErrCreateSubscriptionFailed, err)
+	}
+	defer tx.Rollback()
+
+	for _, subscription := range subscriptions {
+		if err := insertSubscription(ctx, tx, subscription); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit subscriptions creation",
+			logger.Error(err))
+		return wrapError(ErrCreateSubscriptionFailed, err)
+	}
+
+	log.Info("Subscriptions created successfully",
+		logger.Int("count", len(subscriptions)))
+
+	return nil
+}
+
+// insertSubscription inserts a subscription with its initial price and audit entry in the transaction
+// and sets its ID and version
+func insertSubscription(ctx context.Context, tx *sqlx.Tx, subscription *repository.Subscription) error {
+	query := `
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version`
+
+	priceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)`
+
+	log := logger.FromContext(ctx)
+
+	err := getContext(ctx, tx, "subscriptions.insert", subscription, query,
 		subscription.ServiceName,
 		subscription.Price,
+		subscription.Currency,
+		subscription.BillingCycle,
+		subscription.BillingIntervalMonths,
 		subscription.UserID,
 		subscription.StartDate,
-		subscription.EndDate).Scan(&subscription.ID)
+		subscription.EndDate,
+		subscription.TrialEndDate,
+		subscription.ServiceID)
 
 	if err != nil {
 		log.Error("Failed to create subscription",
 			logger.Error(err),
 			logger.String("user_id", subscription.UserID))
-		return ErrCreateSubscriptionFailed
+		return wrapError(ErrCreateSubscriptionFailed, err)
 	}
 
-	log.Info("Subscription created successfully",
-		logger.Int("subscription_id", subscription.ID),
-		logger.String("user_id", subscription.UserID))
+	if _, err := execContext(ctx, tx, "subscription_prices.insert", priceQuery, subscription.ID, subscription.Price, subscription.StartDate); err != nil {
+		log.Error("Failed to create initial subscription price",
+			logger.Error(err),
+			logger.Int("subscription_id", subscription.ID))
+		return wrapError(ErrCreateSubscriptionFailed, err)
+	}
+
+	if err := insertAuditEntry(ctx, tx, repository.AuditOperationCreate, subscription.UserID, subscription.ID, nil, subscription); err != nil {
+		log.Error("Failed to write audit entry",
+			logger.Error(err),
+			logger.Int("subscription_id", subscription.ID))
+		return wrapError(ErrCreateSubscriptionFailed, err)
+	}
 
 	return nil
 }
@@ -64,17 +148,17 @@ func (r *subscriptionsRepository) Create(ctx context.Context, subscription *repo
 // GetSubscription retrieves a specific subscription by user ID and subscription ID
 func (r *subscriptionsRepository) GetSubscription(ctx context.Context, userID string, subscriptionID int) (*repository.Subscription, error) {
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1 AND id = $2`
 
-	log := logger.Global()
+	log := logger.FromContext(ctx)
 	log.Debug("Getting subscription",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
 	subscription := &repository.Subscription{}
-	err := r.db.GetContext(ctx, subscription, query, userID, subscriptionID)
+	err := getContext(ctx, r.db, "subscriptions.get", subscription, query, userID, subscriptionID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,7 +171,7 @@ func (r *subscriptionsRepository) GetSubscription(ctx context.Context, userID st
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return nil, ErrGetSubscriptionFailed
+		return nil, wrapError(ErrGetSubscriptionFailed, err)
 	}
 
 	log.Debug("Subscription retrieved successfully",
@@ -97,23 +181,76 @@ func (r *subscriptionsRepository) GetSubscription(ctx context.Context, userID st
 	return subscription, nil
 }
 
-// UpdateSubscription updates an existing subscription
-func (r *subscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *repository.Subscription, userID string, subscriptionID int) error {
+// UpdateSubscription updates an existing subscription and sets its new version. A price change is appended
+// to the price schedule as effective from today, or from the start date for subscriptions that haven't
+// started yet. The states before and after the update are recorded in the audit log.
+func (r *subscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *repository.Subscription, userID string, subscriptionID int, expectedVersion int) error {
+	lockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
 	query := `
 		UPDATE subscriptions
-		SET service_name = $1, price = $2, start_date = $3, end_date = $4
-		WHERE user_id = $5 AND id = $6`
+		SET service_name = $1, price = $2, currency = $3, billing_cycle = $4, billing_interval_months = $5,
+			start_date = $6, end_date = $7, trial_end_date = $8, service_id = $9, version = version + 1
+		WHERE user_id = $10 AND id = $11`
 
-	log := logger.Global()
+	priceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, GREATEST(CURRENT_DATE, $3::date))
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price`
+
+	log := logger.FromContext(ctx)
 	log.Debug("Updating subscription",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
-	result, err := r.db.ExecContext(ctx, query,
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+	defer tx.Rollback()
+
+	current := &repository.Subscription{}
+	if err := getContext(ctx, tx, "subscriptions.lock", current, lockQuery, userID, subscriptionID); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn("Subscription not found for update",
+				logger.String("user_id", userID),
+				logger.Int("subscription_id", subscriptionID))
+			return ErrSubscriptionNotFoundForUpdate
+		}
+		log.Error("Failed to lock subscription for update",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		log.Warn("Subscription version mismatch on update",
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID),
+			logger.Int("expected_version", expectedVersion),
+			logger.Int("version", current.Version))
+		return ErrSubscriptionVersionMismatch
+	}
+
+	_, err = execContext(ctx, tx, "subscriptions.update", query,
 		subscription.ServiceName,
 		subscription.Price,
+		subscription.Currency,
+		subscription.BillingCycle,
+		subscription.BillingIntervalMonths,
 		subscription.StartDate,
 		subscription.EndDate,
+		subscription.TrialEndDate,
+		subscription.ServiceID,
 		userID,
 		subscriptionID)
 
@@ -122,21 +259,36 @@ func (r *subscriptionsRepository) UpdateSubscription(ctx context.Context, subscr
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return ErrUpdateSubscriptionFailed
+		return wrapError(ErrUpdateSubscriptionFailed, err)
 	}
+	// The row is locked, so the version read with it is the one that was incremented
+	subscription.Version = current.Version + 1
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Error("Failed to get rows affected",
-			logger.Error(err))
-		return ErrGetRowsAffectedFailed
+	if current.Price != subscription.Price {
+		if _, err := execContext(ctx, tx, "subscription_prices.upsert", priceQuery, subscriptionID, subscription.Price, subscription.StartDate); err != nil {
+			log.Error("Failed to append subscription price",
+				logger.Error(err),
+				logger.Int("subscription_id", subscriptionID))
+			return wrapError(ErrUpdateSubscriptionFailed, err)
+		}
+	}
+
+	updated := *subscription
+	updated.ID = subscriptionID
+	updated.UserID = userID
+	if err := insertAuditEntry(ctx, tx, repository.AuditOperationUpdate, userID, subscriptionID, current, &updated); err != nil {
+		log.Error("Failed to write audit entry",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
 	}
 
-	if rowsAffected == 0 {
-		log.Warn("Subscription not found for update",
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit subscription update",
+			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return ErrSubscriptionNotFoundForUpdate
+		return wrapError(ErrUpdateSubscriptionFailed, err)
 	}
 
 	log.Info("Subscription updated successfully",
@@ -146,36 +298,219 @@ func (r *subscriptionsRepository) UpdateSubscription(ctx context.Context, subscr
 	return nil
 }
 
-// DeleteSubscription removes a subscription from the database
-func (r *subscriptionsRepository) DeleteSubscription(ctx context.Context, userID string, subscriptionID int) error {
-	query := `DELETE FROM subscriptions WHERE user_id = $1 AND id = $2`
+// patchColumns copy a column of a subscription to another one and return the copied value
+var patchColumns = map[string]func(dst, src *repository.Subscription) interface{}{
+	repository.ColumnServiceName: func(dst, src *repository.Subscription) interface{} {
+		dst.ServiceName = src.ServiceName
+		return dst.ServiceName
+	},
+	repository.ColumnServiceID: func(dst, src *repository.Subscription) interface{} {
+		dst.ServiceID = src.ServiceID
+		return dst.ServiceID
+	},
+	repository.ColumnPrice: func(dst, src *repository.Subscription) interface{} {
+		dst.Price = src.Price
+		return dst.Price
+	},
+	repository.ColumnCurrency: func(dst, src *repository.Subscription) interface{} {
+		dst.Currency = src.Currency
+		return dst.Currency
+	},
+	repository.ColumnBillingCycle: func(dst, src *repository.Subscription) interface{} {
+		dst.BillingCycle = src.BillingCycle
+		return dst.BillingCycle
+	},
+	repository.ColumnBillingIntervalMonths: func(dst, src *repository.Subscription) interface{} {
+		dst.BillingIntervalMonths = src.BillingIntervalMonths
+		return dst.BillingIntervalMonths
+	},
+	repository.ColumnStartDate: func(dst, src *repository.Subscription) interface{} {
+		dst.StartDate = src.StartDate
+		return dst.StartDate
+	},
+	repository.ColumnEndDate: func(dst, src *repository.Subscription) interface{} {
+		dst.EndDate = src.EndDate
+		return dst.EndDate
+	},
+	repository.ColumnTrialEndDate: func(dst, src *repository.Subscription) interface{} {
+		dst.TrialEndDate = src.TrialEndDate
+		return dst.TrialEndDate
+	},
+}
 
-	log := logger.Global()
+// PatchSubscription writes the listed columns of a subscription, leaving the others as they are, and
+// records the change in the audit log
+func (r *subscriptionsRepository) PatchSubscription(ctx context.Context, subscription *repository.Subscription, columns []string, userID string, subscriptionID int, expectedVersion int) error {
+	lockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	priceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, GREATEST(CURRENT_DATE, $3::date))
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Patching subscription",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID),
+		logger.Any("columns", columns))
+
+	for _, column := range columns {
+		if _, ok := patchColumns[column]; !ok {
+			log.Error("Unknown subscription column in patch",
+				logger.String("column", column))
+			return ErrUpdateSubscriptionFailed
+		}
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+	defer tx.Rollback()
+
+	current := &repository.Subscription{}
+	if err := getContext(ctx, tx, "subscriptions.lock", current, lockQuery, userID, subscriptionID); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn("Subscription not found for patch",
+				logger.String("user_id", userID),
+				logger.Int("subscription_id", subscriptionID))
+			return ErrSubscriptionNotFoundForUpdate
+		}
+		log.Error("Failed to lock subscription for patch",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		log.Warn("Subscription version mismatch on patch",
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID),
+			logger.Int("expected_version", expectedVersion),
+			logger.Int("version", current.Version))
+		return ErrSubscriptionVersionMismatch
+	}
+
+	patched := *current
+	assignments := make([]string, 0, len(columns)+1)
+	args := make([]interface{}, 0, len(columns)+2)
+	for _, column := range columns {
+		args = append(args, patchColumns[column](&patched, subscription))
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	assignments = append(assignments, "version = version + 1")
+	args = append(args, userID, subscriptionID)
+
+	query := fmt.Sprintf(`
+		UPDATE subscriptions
+		SET %s
+		WHERE user_id = $%d AND id = $%d`, strings.Join(assignments, ", "), len(args)-1, len(args))
+
+	if _, err := execContext(ctx, tx, "subscriptions.patch", query, args...); err != nil {
+		log.Error("Failed to patch subscription",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+	patched.Version = current.Version + 1
+
+	if current.Price != patched.Price {
+		if _, err := execContext(ctx, tx, "subscription_prices.upsert", priceQuery, subscriptionID, patched.Price, patched.StartDate); err != nil {
+			log.Error("Failed to append subscription price",
+				logger.Error(err),
+				logger.Int("subscription_id", subscriptionID))
+			return wrapError(ErrUpdateSubscriptionFailed, err)
+		}
+	}
+
+	if err := insertAuditEntry(ctx, tx, repository.AuditOperationUpdate, userID, subscriptionID, current, &patched); err != nil {
+		log.Error("Failed to write audit entry",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit subscription patch",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrUpdateSubscriptionFailed, err)
+	}
+
+	*subscription = patched
+
+	log.Info("Subscription patched successfully",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID))
+
+	return nil
+}
+
+// DeleteSubscription removes a subscription from the database and records its last state in the audit log
+func (r *subscriptionsRepository) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error {
+	query := `
+		DELETE FROM subscriptions s
+		USING current_subscriptions c
+		WHERE s.id = c.id AND c.user_id = $1 AND c.id = $2 AND ($3 = 0 OR c.version = $3)
+		RETURNING c.id, c.service_name, c.price, c.currency, c.billing_cycle, c.billing_interval_months, c.user_id, c.start_date, c.end_date, c.trial_end_date, c.service_id, c.version`
+
+	log := logger.FromContext(ctx)
 	log.Debug("Deleting subscription",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
-	result, err := r.db.ExecContext(ctx, query, userID, subscriptionID)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrDeleteSubscriptionFailed, err)
+	}
+	defer tx.Rollback()
+
+	deleted := &repository.Subscription{}
+	if err := getContext(ctx, tx, "subscriptions.delete", deleted, query, userID, subscriptionID, expectedVersion); err != nil {
+		if err == sql.ErrNoRows && expectedVersion != 0 {
+			return r.deleteMismatch(ctx, tx, userID, subscriptionID)
+		}
+		if err == sql.ErrNoRows {
+			log.Warn("Subscription not found for deletion",
+				logger.String("user_id", userID),
+				logger.Int("subscription_id", subscriptionID))
+			return ErrSubscriptionNotFoundForDeletion
+		}
 		log.Error("Failed to delete subscription",
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return ErrDeleteSubscriptionFailed
+		return wrapError(ErrDeleteSubscriptionFailed, err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Error("Failed to get rows affected",
-			logger.Error(err))
-		return ErrGetRowsAffectedFailed
+	if err := insertAuditEntry(ctx, tx, repository.AuditOperationDelete, userID, subscriptionID, deleted, nil); err != nil {
+		log.Error("Failed to write audit entry",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrDeleteSubscriptionFailed, err)
 	}
 
-	if rowsAffected == 0 {
-		log.Warn("Subscription not found for deletion",
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit subscription deletion",
+			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return ErrSubscriptionNotFoundForDeletion
+		return wrapError(ErrDeleteSubscriptionFailed, err)
 	}
 
 	log.Info("Subscription deleted successfully",
@@ -185,26 +520,55 @@ func (r *subscriptionsRepository) DeleteSubscription(ctx context.Context, userID
 	return nil
 }
 
+// deleteMismatch tells why a conditional deletion removed nothing: the subscription either doesn't
+// exist or has another version
+func (r *subscriptionsRepository) deleteMismatch(ctx context.Context, tx *sqlx.Tx, userID string, subscriptionID int) error {
+	query := `SELECT EXISTS (SELECT 1 FROM subscriptions WHERE user_id = $1 AND id = $2)`
+
+	log := logger.FromContext(ctx)
+
+	var exists bool
+	if err := getContext(ctx, tx, "subscriptions.exists", &exists, query, userID, subscriptionID); err != nil {
+		log.Error("Failed to check subscription existence",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return wrapError(ErrDeleteSubscriptionFailed, err)
+	}
+
+	if !exists {
+		log.Warn("Subscription not found for deletion",
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return ErrSubscriptionNotFoundForDeletion
+	}
+
+	log.Warn("Subscription version mismatch on deletion",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID))
+	return ErrSubscriptionVersionMismatch
+}
+
 // GetSubscriptionsByUserID retrieves all subscriptions for a specific user
 func (r *subscriptionsRepository) GetSubscriptionsByUserID(ctx context.Context, userID string) ([]*repository.Subscription, error) {
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		ORDER BY start_date DESC`
 
-	log := logger.Global()
+	log := logger.FromContext(ctx)
 	log.Debug("Getting subscriptions by user ID",
 		logger.String("user_id", userID))
 
 	subscriptions := []*repository.Subscription{}
-	err := r.db.SelectContext(ctx, &subscriptions, query, userID)
+	err := selectContext(ctx, r.db, "subscriptions.list_by_user", &subscriptions, query, userID)
 
 	if err != nil {
 		log.Error("Failed to get subscriptions by user ID",
 			logger.Error(err),
 			logger.String("user_id", userID))
-		return nil, ErrGetSubscriptionsByUserIDFailed
+		return nil, wrapError(ErrGetSubscriptionsByUserIDFailed, err)
 	}
 
 	log.Debug("Subscriptions retrieved successfully",
@@ -214,19 +578,128 @@ func (r *subscriptionsRepository) GetSubscriptionsByUserID(ctx context.Context,
 	return subscriptions, nil
 }
 
-// GetSubscriptionsByPeriod retrieves subscriptions for a user within a time period with optional service name filtering
-func (r *subscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, startDate, endDate time.Time) ([]*repository.Subscription, error) {
-	log := logger.Global()
+// sortColumns maps the sort keys of the subscription list to SQL expressions and the type of their cursor values
+var sortColumns = map[string]struct{ expr, cast string }{
+	repository.SortByPrice:       {"price", "int"},
+	repository.SortByServiceName: {"service_name", "text"},
+	repository.SortByStartDate:   {"start_date", "date"},
+	repository.SortByEndDate:     {"COALESCE(end_date, 'infinity'::date)", "date"},
+}
+
+// ListSubscriptions retrieves one page of a user's subscriptions using keyset pagination on the sort key and ID
+func (r *subscriptionsRepository) ListSubscriptions(ctx context.Context, filter repository.SubscriptionFilter) ([]*repository.Subscription, int, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("Listing subscriptions",
+		logger.String("user_id", filter.UserID),
+		logger.String("sort_by", filter.SortBy),
+		logger.Int("limit", filter.Limit))
+
+	sortColumn, ok := sortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = sortColumns[repository.SortByStartDate]
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	conditions, args := subscriptionFilterConditions(filter)
+
+	countQuery := "SELECT COUNT(*) FROM current_subscriptions WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := getContext(ctx, r.db, "subscriptions.count", &total, countQuery, args...); err != nil {
+		log.Error("Failed to count subscriptions",
+			logger.Error(err),
+			logger.String("user_id", filter.UserID))
+		return nil, 0, wrapError(ErrListSubscriptionsFailed, err)
+	}
+
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+			sortColumn.expr, comparison, len(args)+1, sortColumn.cast, len(args)+2))
+		args = append(args, filter.After.Value, filter.After.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT $%d`, strings.Join(conditions, " AND "), sortColumn.expr, direction, direction, len(args)+1)
+	args = append(args, filter.Limit)
+
+	subscriptions := []*repository.Subscription{}
+	if err := selectContext(ctx, r.db, "subscriptions.list_page", &subscriptions, query, args...); err != nil {
+		log.Error("Failed to list subscriptions",
+			logger.Error(err),
+			logger.String("user_id", filter.UserID))
+		return nil, 0, wrapError(ErrListSubscriptionsFailed, err)
+	}
+
+	log.Debug("Subscriptions listed successfully",
+		logger.String("user_id", filter.UserID),
+		logger.Int("count", len(subscriptions)),
+		logger.Int("total", total))
+
+	return subscriptions, total, nil
+}
+
+// subscriptionFilterConditions builds the WHERE conditions of a subscription list, without the cursor
+func subscriptionFilterConditions(filter repository.SubscriptionFilter) ([]string, []interface{}) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{filter.UserID}
+
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	switch filter.Status {
+	case repository.StatusActive:
+		conditions = append(conditions, "start_date <= CURRENT_DATE AND (end_date IS NULL OR end_date >= CURRENT_DATE)")
+	case repository.StatusEnded:
+		conditions = append(conditions, "end_date < CURRENT_DATE")
+	}
+	if filter.ServiceNamePrefix != "" {
+		addCondition("service_name ILIKE $%d", escapeLike(filter.ServiceNamePrefix)+"%")
+	}
+	if filter.MinPrice != nil {
+		addCondition("price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		addCondition("price <= $%d", *filter.MaxPrice)
+	}
+	if filter.ActiveFrom != nil {
+		addCondition("(end_date IS NULL OR end_date >= $%d)", *filter.ActiveFrom)
+	}
+	if filter.ActiveTo != nil {
+		addCondition("start_date <= $%d", *filter.ActiveTo)
+	}
+
+	return conditions, args
+}
+
+// escapeLike escapes the LIKE wildcards of a user-supplied pattern
+func escapeLike(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(pattern)
+}
+
+// GetSubscriptionsByPeriod retrieves subscriptions for a user within a time period. When service names or catalog
+// IDs are given, only subscriptions matching any of them are returned.
+func (r *subscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, serviceIDs []int, startDate, endDate time.Time) ([]*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
 	log.Debug("Getting subscriptions by period",
 		logger.String("user_id", userID),
 		logger.Any("service_names", serviceNames),
+		logger.Any("service_ids", serviceIDs),
 		logger.Any("start_date", startDate),
 		logger.Any("end_date", endDate))
 
 	queryBuilder := strings.Builder{}
 	queryBuilder.WriteString(`
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		AND start_date <= $3
 		AND (end_date IS NULL OR end_date >= $2)`)
@@ -234,7 +707,8 @@ func (r *subscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context,
 	args := []interface{}{userID, startDate, endDate}
 	argIndex := 4
 
-	// Add service name filtering if provided
+	// Add service filtering if provided
+	var conditions []string
 	if len(serviceNames) > 0 {
 		placeholders := make([]string, len(serviceNames))
 		for i, serviceName := range serviceNames {
@@ -242,19 +716,35 @@ func (r *subscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context,
 			args = append(args, serviceName)
 			argIndex++
 		}
-		queryBuilder.WriteString(fmt.Sprintf(" AND service_name IN (%s)", strings.Join(placeholders, ",")))
+		conditions = append(conditions, fmt.Sprintf("service_name IN (%s)", strings.Join(placeholders, ",")))
+	}
+	if len(serviceIDs) > 0 {
+		placeholders := make([]string, len(serviceIDs))
+		for i, serviceID := range serviceIDs {
+			placeholders[i] = fmt.Sprintf("$%d", argIndex)
+			args = append(args, serviceID)
+			argIndex++
+		}
+		conditions = append(conditions, fmt.Sprintf("service_id IN (%s)", strings.Join(placeholders, ",")))
+	}
+	switch len(conditions) {
+	case 0:
+	case 1:
+		queryBuilder.WriteString(" AND " + conditions[0])
+	default:
+		queryBuilder.WriteString(" AND (" + strings.Join(conditions, " OR ") + ")")
 	}
 
 	queryBuilder.WriteString(" ORDER BY start_date DESC")
 
 	subscriptions := []*repository.Subscription{}
-	err := r.db.SelectContext(ctx, &subscriptions, queryBuilder.String(), args...)
+	err := selectContext(ctx, r.db, "subscriptions.list_by_period", &subscriptions, queryBuilder.String(), args...)
 
 	if err != nil {
 		log.Error("Failed to get subscriptions by period",
 			logger.Error(err),
 			logger.String("user_id", userID))
-		return nil, ErrGetSubscriptionsByPeriodFailed
+		return nil, wrapError(ErrGetSubscriptionsByPeriodFailed, err)
 	}
 
 	log.Debug("Subscriptions by period retrieved successfully",
@@ -264,6 +754,210 @@ func (r *subscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context,
 	return subscriptions, nil
 }
 
+// GetTrialsEndingBetween retrieves subscriptions of a user whose free trial ends within the given dates
+// and which are still active afterwards, ordered by the trial end date
+func (r *subscriptionsRepository) GetTrialsEndingBetween(ctx context.Context, userID string, from, to time.Time) ([]*repository.Subscription, error) {
+	query := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1
+		AND trial_end_date BETWEEN $2 AND $3
+		AND (end_date IS NULL OR end_date > trial_end_date)
+		ORDER BY trial_end_date ASC, id ASC`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Getting subscriptions with ending trials",
+		logger.String("user_id", userID),
+		logger.Any("from", from),
+		logger.Any("to", to))
+
+	subscriptions := []*repository.Subscription{}
+	err := selectContext(ctx, r.db, "subscriptions.list_trials_ending", &subscriptions, query, userID, from, to)
+
+	if err != nil {
+		log.Error("Failed to get subscriptions with ending trials",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, wrapError(ErrGetTrialsEndingFailed, err)
+	}
+
+	log.Debug("Subscriptions with ending trials retrieved successfully",
+		logger.String("user_id", userID),
+		logger.Int("count", len(subscriptions)))
+
+	return subscriptions, nil
+}
+
+// AddSubscriptionPrice appends an entry to the price schedule of a subscription, replacing an entry
+// with the same effective date. An entry that changes the current price right away is a new version
+// of the subscription, so that ETags of the old price no longer match, while a future entry counts
+// as a version once it takes effect.
+func (r *subscriptionsRepository) AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, price *repository.SubscriptionPrice) (int, error) {
+	lockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	query := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+		RETURNING id, created_at`
+
+	selectQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE id = $1`
+
+	versionQuery := `UPDATE subscriptions SET version = version + 1 WHERE id = $1`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Adding subscription price",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID),
+		logger.Int("price", price.Price))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+	}
+	defer tx.Rollback()
+
+	current := &repository.Subscription{}
+	if err := getContext(ctx, tx, "subscriptions.lock", current, lockQuery, userID, subscriptionID); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn("Subscription not found for price change",
+				logger.String("user_id", userID),
+				logger.Int("subscription_id", subscriptionID))
+			return 0, ErrSubscriptionNotFound
+		}
+		log.Error("Failed to lock subscription for price change",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+	}
+
+	price.SubscriptionID = subscriptionID
+	err = getContext(ctx, tx, "subscription_prices.upsert", price, query, subscriptionID, price.Price, price.EffectiveFrom)
+	if err != nil {
+		log.Error("Failed to add subscription price",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+	}
+
+	updated := &repository.Subscription{}
+	if err := getContext(ctx, tx, "subscriptions.get", updated, selectQuery, subscriptionID); err != nil {
+		log.Error("Failed to get subscription after price change",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+	}
+
+	if updated.Price != current.Price {
+		if _, err := execContext(ctx, tx, "subscriptions.increment_version", versionQuery, subscriptionID); err != nil {
+			log.Error("Failed to increment subscription version",
+				logger.Error(err),
+				logger.Int("subscription_id", subscriptionID))
+			return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+		}
+		updated.Version++
+	}
+
+	if err := insertAuditEntry(ctx, tx, repository.AuditOperationPriceChange, userID, subscriptionID, current, updated); err != nil {
+		log.Error("Failed to write audit entry",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit subscription price",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return 0, wrapError(ErrAddSubscriptionPriceFailed, err)
+	}
+
+	log.Info("Subscription price added successfully",
+		logger.Int("subscription_id", subscriptionID),
+		logger.Int("price", price.Price),
+		logger.Int("version", updated.Version))
+
+	return updated.Version, nil
+}
+
+// GetSubscriptionPrices retrieves the price schedules of the given subscriptions ordered by effective date
+func (r *subscriptionsRepository) GetSubscriptionPrices(ctx context.Context, subscriptionIDs []int) ([]*repository.SubscriptionPrice, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("Getting subscription prices",
+		logger.Any("subscription_ids", subscriptionIDs))
+
+	prices := []*repository.SubscriptionPrice{}
+	if len(subscriptionIDs) == 0 {
+		return prices, nil
+	}
+
+	placeholders := make([]string, len(subscriptionIDs))
+	args := make([]interface{}, len(subscriptionIDs))
+	for i, id := range subscriptionIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, subscription_id, price, effective_from, created_at
+		FROM subscription_prices
+		WHERE subscription_id IN (%s)
+		ORDER BY subscription_id, effective_from`, strings.Join(placeholders, ","))
+
+	if err := selectContext(ctx, r.db, "subscription_prices.list", &prices, query, args...); err != nil {
+		log.Error("Failed to get subscription prices",
+			logger.Error(err))
+		return nil, wrapError(ErrGetSubscriptionPricesFailed, err)
+	}
+
+	log.Debug("Subscription prices retrieved successfully",
+		logger.Int("count", len(prices)))
+
+	return prices, nil
+}
+
+// GetExchangeRate retrieves the stored rate for converting baseCurrency into quoteCurrency
+func (r *subscriptionsRepository) GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string) (*repository.ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Getting exchange rate",
+		logger.String("base_currency", baseCurrency),
+		logger.String("quote_currency", quoteCurrency))
+
+	rate := &repository.ExchangeRate{}
+	err := getContext(ctx, r.db, "exchange_rates.get", rate, query, baseCurrency, quoteCurrency)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn("Exchange rate not found",
+				logger.String("base_currency", baseCurrency),
+				logger.String("quote_currency", quoteCurrency))
+			return nil, ErrExchangeRateNotFound
+		}
+		log.Error("Failed to get exchange rate",
+			logger.Error(err),
+			logger.String("base_currency", baseCurrency),
+			logger.String("quote_currency", quoteCurrency))
+		return nil, wrapError(ErrGetExchangeRateFailed, err)
+	}
+
+	return rate, nil
+}
+
 // Close closes the database connection
 func (r *subscriptionsRepository) Close() error {
 	log := logger.Global()
@@ -281,7 +975,7 @@ func (r *subscriptionsRepository) RunMigrations(migrationsFilePath string) error
 	if err != nil {
 		log.Error("Failed to create migration driver",
 			logger.Error(err))
-		return ErrCreateMigrationDriverFailed
+		return wrapError(ErrCreateMigrationDriverFailed, err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
@@ -292,15 +986,15 @@ func (r *subscriptionsRepository) RunMigrations(migrationsFilePath string) error
 	if err != nil {
 		log.Error("Failed to create migration instance",
 			logger.Error(err))
-		return ErrCreateMigrationInstanceFailed
+		return wrapError(ErrCreateMigrationInstanceFailed, err)
 	}
 
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 		log.Error("Failed to run migrations",
 			logger.Error(err))
-		return ErrRunMigrationsFailed
+		return wrapError(ErrRunMigrationsFailed, err)
 	}
 
 	log.Info("Database migrations completed successfully")
 	return nil
-}
\ No newline at end of file
+}
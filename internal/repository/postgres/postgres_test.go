@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/auth"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/requestid"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +27,7 @@ type PostgresRepositoryTestSuite struct {
 func (suite *PostgresRepositoryTestSuite) SetupTest() {
 	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
 	require.NoError(suite.T(), err)
-	
+
 	suite.db = sqlx.NewDb(mockDB, "postgres")
 	suite.mock = mock
 	suite.repo = NewSubscriptionsRepository(suite.db)
@@ -35,33 +37,55 @@ func (suite *PostgresRepositoryTestSuite) TearDownTest() {
 	suite.db.Close()
 }
 
+// expectedAuditQuery is written in the transaction of every subscription mutation
+const expectedAuditQuery = `
+		INSERT INTO audit_log (subscription_id, user_id, actor, request_id, operation, old_data, new_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+var subscriptionColumns = []string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id", "version"}
+
 func (suite *PostgresRepositoryTestSuite) TestCreate_Success() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
-	
+
 	subscription := &repository.Subscription{
-		ServiceName: "Netflix",
-		Price:       599,
-		UserID:      userID,
-		StartDate:   startDate,
-		EndDate:     &endDate,
+		ServiceName:  "Netflix",
+		Price:        599,
+		Currency:     "RUB",
+		BillingCycle: "monthly",
+		UserID:       userID,
+		StartDate:    startDate,
+		EndDate:      &endDate,
 	}
-	
+
 	expectedQuery := `
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
-	
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version`
+
+	expectedPriceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)`
+
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectQuery(expectedQuery).
-		WithArgs(subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, subscription.EndDate).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-	
+		WithArgs(subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingCycle, subscription.BillingIntervalMonths, subscription.UserID, subscription.StartDate, subscription.EndDate, subscription.TrialEndDate, subscription.ServiceID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+	suite.mock.ExpectExec(expectedPriceQuery).
+		WithArgs(1, subscription.Price, subscription.StartDate).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(1, userID, "anonymous", "", repository.AuditOperationCreate, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectCommit()
+
 	err := suite.repo.Create(ctx, subscription)
-	
+
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), 1, subscription.ID)
+	assert.Equal(suite.T(), 1, subscription.Version)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
@@ -69,7 +93,7 @@ func (suite *PostgresRepositoryTestSuite) TestCreate_WithoutEndDate() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440001"
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
-	
+
 	subscription := &repository.Subscription{
 		ServiceName: "Spotify",
 		Price:       299,
@@ -77,18 +101,30 @@ func (suite *PostgresRepositoryTestSuite) TestCreate_WithoutEndDate() {
 		StartDate:   startDate,
 		EndDate:     nil,
 	}
-	
+
 	expectedQuery := `
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
-	
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version`
+
+	expectedPriceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)`
+
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectQuery(expectedQuery).
-		WithArgs(subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, nil).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
-	
+		WithArgs(subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingCycle, subscription.BillingIntervalMonths, subscription.UserID, subscription.StartDate, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(2, 1))
+	suite.mock.ExpectExec(expectedPriceQuery).
+		WithArgs(2, subscription.Price, subscription.StartDate).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(2, userID, "anonymous", "", repository.AuditOperationCreate, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	suite.mock.ExpectCommit()
+
 	err := suite.repo.Create(ctx, subscription)
-	
+
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), 2, subscription.ID)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
@@ -102,20 +138,93 @@ func (suite *PostgresRepositoryTestSuite) TestCreate_DatabaseError() {
 		UserID:      "550e8400-e29b-41d4-a716-446655440000",
 		StartDate:   time.Now(),
 	}
-	
+
 	expectedQuery := `
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
-	
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version`
+
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectQuery(expectedQuery).
-		WithArgs(subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, subscription.EndDate).
+		WithArgs(subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingCycle, subscription.BillingIntervalMonths, subscription.UserID, subscription.StartDate, subscription.EndDate, subscription.TrialEndDate, subscription.ServiceID).
 		WillReturnError(sql.ErrConnDone)
-	
+	suite.mock.ExpectRollback()
+
 	err := suite.repo.Create(ctx, subscription)
-	
+
 	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), ErrCreateSubscriptionFailed, err)
+	assert.ErrorIs(suite.T(), err, ErrCreateSubscriptionFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestCreateSubscriptions_Success() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{ServiceName: "Netflix", Price: 599, Currency: "RUB", BillingCycle: "monthly", UserID: userID, StartDate: startDate},
+		{ServiceName: "Spotify", Price: 299, Currency: "RUB", BillingCycle: "monthly", UserID: userID, StartDate: startDate},
+	}
+
+	expectedQuery := `
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version`
+
+	expectedPriceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)`
+
+	suite.mock.ExpectBegin()
+	for i, subscription := range subscriptions {
+		id := i + 1
+		suite.mock.ExpectQuery(expectedQuery).
+			WithArgs(subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingCycle, nil, userID, startDate, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(id, 1))
+		suite.mock.ExpectExec(expectedPriceQuery).
+			WithArgs(id, subscription.Price, startDate).
+			WillReturnResult(sqlmock.NewResult(int64(id), 1))
+		suite.mock.ExpectExec(expectedAuditQuery).
+			WithArgs(id, userID, "anonymous", "", repository.AuditOperationCreate, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(int64(id), 1))
+	}
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.CreateSubscriptions(ctx, subscriptions)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, subscriptions[0].ID)
+	assert.Equal(suite.T(), 2, subscriptions[1].ID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestCreateSubscriptions_RollsBackOnError() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{ServiceName: "Netflix", Price: 599, Currency: "RUB", BillingCycle: "monthly", UserID: userID, StartDate: startDate},
+		{ServiceName: "Spotify", Price: 299, Currency: "RUB", BillingCycle: "monthly", UserID: userID, StartDate: startDate},
+	}
+
+	expectedQuery := `
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, version`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs("Netflix", 599, "RUB", "monthly", nil, userID, startDate, nil, nil, nil).
+		WillReturnError(sql.ErrConnDone)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.CreateSubscriptions(ctx, subscriptions)
+
+	assert.ErrorIs(suite.T(), err, ErrCreateSubscriptionFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
@@ -125,21 +234,21 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscription_Success() {
 	subscriptionID := 1
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1 AND id = $2`
-	
-	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "user_id", "start_date", "end_date"}).
-		AddRow(subscriptionID, "Netflix", 599, userID, startDate, endDate)
-	
+
+	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+		AddRow(subscriptionID, "Netflix", 599, "RUB", "monthly", nil, userID, startDate, endDate, nil, nil)
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID, subscriptionID).
 		WillReturnRows(rows)
-	
+
 	result, err := suite.repo.GetSubscription(ctx, userID, subscriptionID)
-	
+
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), subscriptionID, result.ID)
@@ -155,18 +264,18 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscription_NotFound() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 999
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1 AND id = $2`
-	
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID, subscriptionID).
 		WillReturnError(sql.ErrNoRows)
-	
+
 	result, err := suite.repo.GetSubscription(ctx, userID, subscriptionID)
-	
+
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
 	assert.Nil(suite.T(), result)
@@ -177,20 +286,21 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscription_DatabaseError() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 1
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1 AND id = $2`
-	
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID, subscriptionID).
 		WillReturnError(sql.ErrConnDone)
-	
+
 	result, err := suite.repo.GetSubscription(ctx, userID, subscriptionID)
-	
+
 	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), ErrGetSubscriptionFailed, err)
+	assert.ErrorIs(suite.T(), err, ErrGetSubscriptionFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
 	assert.Nil(suite.T(), result)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
@@ -200,7 +310,7 @@ func (suite *PostgresRepositoryTestSuite) TestUpdateSubscription_Success() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 1
 	startDate := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
-	
+
 	subscription := &repository.Subscription{
 		ID:          subscriptionID,
 		ServiceName: "Updated Service",
@@ -209,19 +319,44 @@ func (suite *PostgresRepositoryTestSuite) TestUpdateSubscription_Success() {
 		StartDate:   startDate,
 		EndDate:     nil,
 	}
-	
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
 	expectedQuery := `
 		UPDATE subscriptions
-		SET service_name = $1, price = $2, start_date = $3, end_date = $4
-		WHERE user_id = $5 AND id = $6`
-	
+		SET service_name = $1, price = $2, currency = $3, billing_cycle = $4, billing_interval_months = $5,
+			start_date = $6, end_date = $7, trial_end_date = $8, service_id = $9, version = version + 1
+		WHERE user_id = $10 AND id = $11`
+
+	expectedPriceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, GREATEST(CURRENT_DATE, $3::date))
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Old Service", 599, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 1))
 	suite.mock.ExpectExec(expectedQuery).
-		WithArgs(subscription.ServiceName, subscription.Price, subscription.StartDate, subscription.EndDate, userID, subscriptionID).
+		WithArgs(subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingCycle, subscription.BillingIntervalMonths, subscription.StartDate, subscription.EndDate, subscription.TrialEndDate, subscription.ServiceID, userID, subscriptionID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	
-	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID)
-	
+	suite.mock.ExpectExec(expectedPriceQuery).
+		WithArgs(subscriptionID, subscription.Price, subscription.StartDate).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(subscriptionID, userID, "anonymous", "", repository.AuditOperationUpdate, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID, 1)
+
 	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, subscription.Version)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
@@ -229,24 +364,27 @@ func (suite *PostgresRepositoryTestSuite) TestUpdateSubscription_NotFound() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 999
-	
+
 	subscription := &repository.Subscription{
 		ServiceName: "Updated Service",
 		Price:       799,
 		StartDate:   time.Now(),
 	}
-	
-	expectedQuery := `
-		UPDATE subscriptions
-		SET service_name = $1, price = $2, start_date = $3, end_date = $4
-		WHERE user_id = $5 AND id = $6`
-	
-	suite.mock.ExpectExec(expectedQuery).
-		WithArgs(subscription.ServiceName, subscription.Price, subscription.StartDate, subscription.EndDate, userID, subscriptionID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	
-	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID)
-	
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID, 0)
+
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), ErrSubscriptionNotFoundForUpdate, err)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
@@ -256,42 +394,130 @@ func (suite *PostgresRepositoryTestSuite) TestUpdateSubscription_DatabaseError()
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 1
-	
+
 	subscription := &repository.Subscription{
 		ServiceName: "Updated Service",
 		Price:       799,
 		StartDate:   time.Now(),
 	}
-	
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
 	expectedQuery := `
 		UPDATE subscriptions
-		SET service_name = $1, price = $2, start_date = $3, end_date = $4
-		WHERE user_id = $5 AND id = $6`
-	
+		SET service_name = $1, price = $2, currency = $3, billing_cycle = $4, billing_interval_months = $5,
+			start_date = $6, end_date = $7, trial_end_date = $8, service_id = $9, version = version + 1
+		WHERE user_id = $10 AND id = $11`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, subscription.ServiceName, subscription.Price, "RUB", "monthly", nil, userID, subscription.StartDate, nil, nil, nil, 1))
 	suite.mock.ExpectExec(expectedQuery).
-		WithArgs(subscription.ServiceName, subscription.Price, subscription.StartDate, subscription.EndDate, userID, subscriptionID).
+		WithArgs(subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingCycle, subscription.BillingIntervalMonths, subscription.StartDate, subscription.EndDate, subscription.TrialEndDate, subscription.ServiceID, userID, subscriptionID).
 		WillReturnError(sql.ErrConnDone)
-	
-	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID)
-	
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID, 0)
+
 	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), ErrUpdateSubscriptionFailed, err)
+	assert.ErrorIs(suite.T(), err, ErrUpdateSubscriptionFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
-func (suite *PostgresRepositoryTestSuite) TestDeleteSubscription_Success() {
+func (suite *PostgresRepositoryTestSuite) TestPatchSubscription_Success() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 1
-	
-	expectedQuery := `DELETE FROM subscriptions WHERE user_id = $1 AND id = $2`
-	
-	suite.mock.ExpectExec(expectedQuery).
+	startDate := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	subscription := &repository.Subscription{
+		ServiceName: "Ignored",
+		Price:       799,
+		EndDate:     nil,
+	}
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	expectedQuery := `
+		UPDATE subscriptions
+		SET price = $1, end_date = $2, version = version + 1
+		WHERE user_id = $3 AND id = $4`
+
+	expectedPriceQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, GREATEST(CURRENT_DATE, $3::date))
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
 		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 599, "RUB", "monthly", nil, userID, startDate, endDate, nil, nil, 4))
+	suite.mock.ExpectExec(expectedQuery).
+		WithArgs(799, nil, userID, subscriptionID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	
-	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID)
-	
+	suite.mock.ExpectExec(expectedPriceQuery).
+		WithArgs(subscriptionID, 799, startDate).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(subscriptionID, userID, "anonymous", "", repository.AuditOperationUpdate, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.PatchSubscription(ctx, subscription, []string{repository.ColumnPrice, repository.ColumnEndDate}, userID, subscriptionID, 4)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Netflix", subscription.ServiceName)
+	assert.Equal(suite.T(), 799, subscription.Price)
+	assert.Nil(suite.T(), subscription.EndDate)
+	assert.Equal(suite.T(), startDate, subscription.StartDate)
+	assert.Equal(suite.T(), 5, subscription.Version)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestPatchSubscription_UnknownColumn() {
+	err := suite.repo.PatchSubscription(context.Background(), &repository.Subscription{}, []string{"user_id"}, "550e8400-e29b-41d4-a716-446655440000", 1, 0)
+
+	assert.Equal(suite.T(), ErrUpdateSubscriptionFailed, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestDeleteSubscription_Success() {
+	ctx := auth.WithIdentity(requestid.WithRequestID(context.Background(), "req-1"), auth.Identity{Subject: "admin-user", Admin: true})
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		DELETE FROM subscriptions s
+		USING current_subscriptions c
+		WHERE s.id = c.id AND c.user_id = $1 AND c.id = $2 AND ($3 = 0 OR c.version = $3)
+		RETURNING c.id, c.service_name, c.price, c.currency, c.billing_cycle, c.billing_interval_months, c.user_id, c.start_date, c.end_date, c.trial_end_date, c.service_id, c.version`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, subscriptionID, 0).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 599, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(subscriptionID, userID, "admin-user", "req-1", repository.AuditOperationDelete, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID, 0)
+
 	assert.NoError(suite.T(), err)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
@@ -300,35 +526,112 @@ func (suite *PostgresRepositoryTestSuite) TestDeleteSubscription_NotFound() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 999
-	
-	expectedQuery := `DELETE FROM subscriptions WHERE user_id = $1 AND id = $2`
-	
-	suite.mock.ExpectExec(expectedQuery).
-		WithArgs(userID, subscriptionID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	
-	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID)
-	
+
+	expectedQuery := `
+		DELETE FROM subscriptions s
+		USING current_subscriptions c
+		WHERE s.id = c.id AND c.user_id = $1 AND c.id = $2 AND ($3 = 0 OR c.version = $3)
+		RETURNING c.id, c.service_name, c.price, c.currency, c.billing_cycle, c.billing_interval_months, c.user_id, c.start_date, c.end_date, c.trial_end_date, c.service_id, c.version`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, subscriptionID, 0).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID, 0)
+
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), ErrSubscriptionNotFoundForDeletion, err)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
-func (suite *PostgresRepositoryTestSuite) TestDeleteSubscription_DatabaseError() {
+func (suite *PostgresRepositoryTestSuite) TestDeleteSubscription_AuditFailureRollsBack() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 1
-	
-	expectedQuery := `DELETE FROM subscriptions WHERE user_id = $1 AND id = $2`
-	
-	suite.mock.ExpectExec(expectedQuery).
-		WithArgs(userID, subscriptionID).
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		DELETE FROM subscriptions s
+		USING current_subscriptions c
+		WHERE s.id = c.id AND c.user_id = $1 AND c.id = $2 AND ($3 = 0 OR c.version = $3)
+		RETURNING c.id, c.service_name, c.price, c.currency, c.billing_cycle, c.billing_interval_months, c.user_id, c.start_date, c.end_date, c.trial_end_date, c.service_id, c.version`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, subscriptionID, 0).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 599, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
 		WillReturnError(sql.ErrConnDone)
-	
-	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID)
-	
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID, 0)
+
 	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), ErrDeleteSubscriptionFailed, err)
+	assert.ErrorIs(suite.T(), err, ErrDeleteSubscriptionFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestUpdateSubscription_VersionMismatch() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	startDate := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	subscription := &repository.Subscription{
+		ServiceName: "Updated Service",
+		Price:       799,
+		StartDate:   startDate,
+	}
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Old Service", 599, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 3))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID, 2)
+
+	assert.Equal(suite.T(), ErrSubscriptionVersionMismatch, err)
+	assert.ErrorIs(suite.T(), err, repository.ErrVersionMismatch)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestDeleteSubscription_VersionMismatch() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+
+	expectedQuery := `
+		DELETE FROM subscriptions s
+		USING current_subscriptions c
+		WHERE s.id = c.id AND c.user_id = $1 AND c.id = $2 AND ($3 = 0 OR c.version = $3)
+		RETURNING c.id, c.service_name, c.price, c.currency, c.billing_cycle, c.billing_interval_months, c.user_id, c.start_date, c.end_date, c.trial_end_date, c.service_id, c.version`
+
+	expectedExistsQuery := `SELECT EXISTS (SELECT 1 FROM subscriptions WHERE user_id = $1 AND id = $2)`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, subscriptionID, 2).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns))
+	suite.mock.ExpectQuery(expectedExistsQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.DeleteSubscription(ctx, userID, subscriptionID, 2)
+
+	assert.Equal(suite.T(), ErrSubscriptionVersionMismatch, err)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
@@ -338,60 +641,60 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByUserID_Success()
 	startDate1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	startDate2 := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
 	endDate1 := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		ORDER BY start_date DESC`
-	
-	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "user_id", "start_date", "end_date"}).
-		AddRow(2, "Spotify", 299, userID, startDate2, nil).
-		AddRow(1, "Netflix", 599, userID, startDate1, endDate1)
-	
+
+	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+		AddRow(2, "Spotify", 299, "RUB", "monthly", nil, userID, startDate2, nil, nil, nil).
+		AddRow(1, "Netflix", 599, "RUB", "monthly", nil, userID, startDate1, endDate1, nil, nil)
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID).
 		WillReturnRows(rows)
-	
+
 	result, err := suite.repo.GetSubscriptionsByUserID(ctx, userID)
-	
+
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 2)
-	
+
 	// Check first subscription (should be ordered by start_date DESC)
 	assert.Equal(suite.T(), 2, result[0].ID)
 	assert.Equal(suite.T(), "Spotify", result[0].ServiceName)
 	assert.Equal(suite.T(), 299, result[0].Price)
 	assert.Nil(suite.T(), result[0].EndDate)
-	
+
 	// Check second subscription
 	assert.Equal(suite.T(), 1, result[1].ID)
 	assert.Equal(suite.T(), "Netflix", result[1].ServiceName)
 	assert.Equal(suite.T(), 599, result[1].Price)
 	assert.NotNil(suite.T(), result[1].EndDate)
 	assert.Equal(suite.T(), endDate1, *result[1].EndDate)
-	
+
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
 func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByUserID_EmptyResult() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		ORDER BY start_date DESC`
-	
-	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "user_id", "start_date", "end_date"})
-	
+
+	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"})
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID).
 		WillReturnRows(rows)
-	
+
 	result, err := suite.repo.GetSubscriptionsByUserID(ctx, userID)
-	
+
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 0)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
@@ -400,21 +703,22 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByUserID_EmptyResu
 func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByUserID_DatabaseError() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		ORDER BY start_date DESC`
-	
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID).
 		WillReturnError(sql.ErrConnDone)
-	
+
 	result, err := suite.repo.GetSubscriptionsByUserID(ctx, userID)
-	
+
 	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), ErrGetSubscriptionsByUserIDFailed, err)
+	assert.ErrorIs(suite.T(), err, ErrGetSubscriptionsByUserIDFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
 	assert.Nil(suite.T(), result)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
@@ -424,27 +728,27 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByPeriod_WithoutSe
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		AND start_date <= $3
 		AND (end_date IS NULL OR end_date >= $2)
 		ORDER BY start_date DESC`
-	
+
 	subStartDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
 	subEndDate := time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC)
-	
-	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "user_id", "start_date", "end_date"}).
-		AddRow(1, "Netflix", 599, userID, subStartDate, subEndDate)
-	
+
+	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+		AddRow(1, "Netflix", 599, "RUB", "monthly", nil, userID, subStartDate, subEndDate, nil, nil)
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID, startDate, endDate).
 		WillReturnRows(rows)
-	
-	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, nil, startDate, endDate)
-	
+
+	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, nil, nil, startDate, endDate)
+
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 1)
 	assert.Equal(suite.T(), "Netflix", result[0].ServiceName)
@@ -457,59 +761,456 @@ func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByPeriod_WithServi
 	serviceNames := []string{"Netflix", "Spotify"}
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		AND start_date <= $3
 		AND (end_date IS NULL OR end_date >= $2)
 		AND service_name IN ($4,$5)
 		ORDER BY start_date DESC`
-	
+
 	subStartDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
-	
-	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "user_id", "start_date", "end_date"}).
-		AddRow(1, "Netflix", 599, userID, subStartDate, nil).
-		AddRow(2, "Spotify", 299, userID, subStartDate, nil)
-	
+
+	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+		AddRow(1, "Netflix", 599, "RUB", "monthly", nil, userID, subStartDate, nil, nil, nil).
+		AddRow(2, "Spotify", 299, "RUB", "monthly", nil, userID, subStartDate, nil, nil, nil)
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID, startDate, endDate, "Netflix", "Spotify").
 		WillReturnRows(rows)
-	
-	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, serviceNames, startDate, endDate)
-	
+
+	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, serviceNames, nil, startDate, endDate)
+
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 2)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
+func (suite *PostgresRepositoryTestSuite) TestListSubscriptions_WithFiltersAndCursor() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	minPrice, maxPrice := 100, 1000
+	activeFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	activeTo := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	filter := repository.SubscriptionFilter{
+		UserID:            userID,
+		Status:            repository.StatusActive,
+		ServiceNamePrefix: "net_",
+		MinPrice:          &minPrice,
+		MaxPrice:          &maxPrice,
+		ActiveFrom:        &activeFrom,
+		ActiveTo:          &activeTo,
+		SortBy:            repository.SortByEndDate,
+		After:             &repository.SubscriptionCursor{Value: "2025-03-31", ID: 4},
+		Limit:             11,
+	}
+
+	where := `user_id = $1 AND start_date <= CURRENT_DATE AND (end_date IS NULL OR end_date >= CURRENT_DATE) AND service_name ILIKE $2 AND price >= $3 AND price <= $4 AND (end_date IS NULL OR end_date >= $5) AND start_date <= $6`
+
+	suite.mock.ExpectQuery(`SELECT COUNT(*) FROM current_subscriptions WHERE `+where).
+		WithArgs(userID, `net\_%`, minPrice, maxPrice, activeFrom, activeTo).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	expectedQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE ` + where + ` AND (COALESCE(end_date, 'infinity'::date), id) > ($7::date, $8)
+		ORDER BY COALESCE(end_date, 'infinity'::date) ASC, id ASC
+		LIMIT $9`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, `net\_%`, minPrice, maxPrice, activeFrom, activeTo, "2025-03-31", 4, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+			AddRow(5, "Net_Flix", 599, "RUB", "monthly", nil, userID, activeFrom, nil, nil, nil))
+
+	result, total, err := suite.repo.ListSubscriptions(ctx, filter)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 12, total)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), 5, result[0].ID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestListSubscriptions_StatusExcludesNotStarted() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// A subscription that starts in the future is neither active nor ended
+	conditions := map[string]string{
+		repository.StatusActive: "start_date <= CURRENT_DATE AND (end_date IS NULL OR end_date >= CURRENT_DATE)",
+		repository.StatusEnded:  "end_date < CURRENT_DATE",
+	}
+	for status, condition := range conditions {
+		where := `user_id = $1 AND ` + condition
+
+		suite.mock.ExpectQuery(`SELECT COUNT(*) FROM current_subscriptions WHERE ` + where).
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		suite.mock.ExpectQuery(`
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE `+where+`
+		ORDER BY start_date ASC, id ASC
+		LIMIT $2`).
+			WithArgs(userID, 21).
+			WillReturnRows(sqlmock.NewRows(subscriptionColumns))
+
+		_, _, err := suite.repo.ListSubscriptions(ctx, repository.SubscriptionFilter{UserID: userID, Status: status, Limit: 21})
+
+		assert.NoError(suite.T(), err, status)
+	}
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestListSubscriptions_FirstPageDefaultSort() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	suite.mock.ExpectQuery(`SELECT COUNT(*) FROM current_subscriptions WHERE user_id = $1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	expectedQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1
+		ORDER BY start_date DESC, id DESC
+		LIMIT $2`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}))
+
+	result, total, err := suite.repo.ListSubscriptions(ctx, repository.SubscriptionFilter{UserID: userID, Descending: true, Limit: 21})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, total)
+	assert.Empty(suite.T(), result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestListSubscriptions_DatabaseError() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	suite.mock.ExpectQuery(`SELECT COUNT(*) FROM current_subscriptions WHERE user_id = $1`).
+		WithArgs(userID).
+		WillReturnError(sql.ErrConnDone)
+
+	result, total, err := suite.repo.ListSubscriptions(ctx, repository.SubscriptionFilter{UserID: userID, Limit: 21})
+
+	assert.ErrorIs(suite.T(), err, ErrListSubscriptionsFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), 0, total)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByPeriod_WithCatalogFilter() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1
+		AND start_date <= $3
+		AND (end_date IS NULL OR end_date >= $2)
+		AND (service_name IN ($4,$5) OR service_id IN ($6))
+		ORDER BY start_date DESC`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, startDate, endDate, "Yandex Plus", "Яндекс Плюс", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+			AddRow(1, "Yandex Plus", 399, "RUB", "monthly", nil, userID, startDate, nil, nil, 5))
+
+	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, []string{"Yandex Plus", "Яндекс Плюс"}, []int{5}, startDate, endDate)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), 5, *result[0].ServiceID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
 func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionsByPeriod_DatabaseError() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
-	
+
 	expectedQuery := `
-		SELECT id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
 		WHERE user_id = $1
 		AND start_date <= $3
 		AND (end_date IS NULL OR end_date >= $2)
 		ORDER BY start_date DESC`
-	
+
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(userID, startDate, endDate).
 		WillReturnError(sql.ErrConnDone)
-	
-	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, nil, startDate, endDate)
-	
+
+	result, err := suite.repo.GetSubscriptionsByPeriod(ctx, userID, nil, nil, startDate, endDate)
+
+	assert.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, ErrGetSubscriptionsByPeriodFailed)
+	assert.ErrorIs(suite.T(), err, repository.ErrUnavailable)
+	assert.Nil(suite.T(), result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestGetExchangeRate_Success() {
+	ctx := context.Background()
+	updatedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2`
+
+	rows := sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate", "updated_at"}).
+		AddRow("USD", "RUB", 80.5, updatedAt)
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs("USD", "RUB").
+		WillReturnRows(rows)
+
+	result, err := suite.repo.GetExchangeRate(ctx, "USD", "RUB")
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Equal(suite.T(), "USD", result.BaseCurrency)
+	assert.Equal(suite.T(), "RUB", result.QuoteCurrency)
+	assert.Equal(suite.T(), 80.5, result.Rate)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestGetExchangeRate_NotFound() {
+	ctx := context.Background()
+
+	expectedQuery := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs("USD", "EUR").
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := suite.repo.GetExchangeRate(ctx, "USD", "EUR")
+
 	assert.Error(suite.T(), err)
-	assert.Equal(suite.T(), ErrGetSubscriptionsByPeriodFailed, err)
+	assert.Equal(suite.T(), ErrExchangeRateNotFound, err)
 	assert.Nil(suite.T(), result)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
+func (suite *PostgresRepositoryTestSuite) TestGetTrialsEndingBetween_Success() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+	startDate := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	trialEndDate := time.Date(2025, 7, 9, 0, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1
+		AND trial_end_date BETWEEN $2 AND $3
+		AND (end_date IS NULL OR end_date > trial_end_date)
+		ORDER BY trial_end_date ASC, id ASC`
+
+	rows := sqlmock.NewRows([]string{"id", "service_name", "price", "currency", "billing_cycle", "billing_interval_months", "user_id", "start_date", "end_date", "trial_end_date", "service_id"}).
+		AddRow(1, "Netflix", 599, "RUB", "monthly", nil, userID, startDate, nil, trialEndDate, nil)
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(userID, from, to).
+		WillReturnRows(rows)
+
+	result, err := suite.repo.GetTrialsEndingBetween(ctx, userID, from, to)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), trialEndDate, *result[0].TrialEndDate)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestAddSubscriptionPrice_Success() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	createdAt := time.Date(2025, 8, 15, 10, 0, 0, 0, time.UTC)
+
+	price := &repository.SubscriptionPrice{
+		Price:         499,
+		EffectiveFrom: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	expectedQuery := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+		RETURNING id, created_at`
+
+	expectedSelectQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE id = $1`
+
+	expectedVersionQuery := `UPDATE subscriptions SET version = version + 1 WHERE id = $1`
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 399, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 1))
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(subscriptionID, price.Price, price.EffectiveFrom).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, createdAt))
+	suite.mock.ExpectQuery(expectedSelectQuery).
+		WithArgs(subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 499, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 1))
+	suite.mock.ExpectExec(expectedVersionQuery).
+		WithArgs(subscriptionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(subscriptionID, userID, "anonymous", "", repository.AuditOperationPriceChange, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectCommit()
+
+	version, err := suite.repo.AddSubscriptionPrice(ctx, userID, subscriptionID, price)
+
+	assert.NoError(suite.T(), err)
+	// The current price changed, so the ETag of version 1 no longer matches
+	assert.Equal(suite.T(), 2, version)
+	assert.Equal(suite.T(), 5, price.ID)
+	assert.Equal(suite.T(), subscriptionID, price.SubscriptionID)
+	assert.Equal(suite.T(), createdAt, price.CreatedAt)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestAddSubscriptionPrice_FutureKeepsVersion() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	price := &repository.SubscriptionPrice{
+		Price:         499,
+		EffectiveFrom: time.Now().AddDate(1, 0, 0),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 399, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 3))
+	suite.mock.ExpectQuery(`
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+		RETURNING id, created_at`).
+		WithArgs(subscriptionID, price.Price, price.EffectiveFrom).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(6, time.Now()))
+	suite.mock.ExpectQuery(`
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE id = $1`).
+		WithArgs(subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+			AddRow(subscriptionID, "Netflix", 399, "RUB", "monthly", nil, userID, startDate, nil, nil, nil, 3))
+	// The current price stays 399 until the entry takes effect, so the version doesn't change
+	suite.mock.ExpectExec(expectedAuditQuery).
+		WithArgs(subscriptionID, userID, "anonymous", "", repository.AuditOperationPriceChange, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectCommit()
+
+	version, err := suite.repo.AddSubscriptionPrice(ctx, userID, subscriptionID, price)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, version)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestAddSubscriptionPrice_NotFound() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 999
+
+	expectedLockQuery := `
+		SELECT id, service_name, price, currency, billing_cycle, billing_interval_months, user_id, start_date, end_date, trial_end_date, service_id, version
+		FROM current_subscriptions
+		WHERE user_id = $1 AND id = $2
+		FOR UPDATE`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedLockQuery).
+		WithArgs(userID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows(subscriptionColumns))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repo.AddSubscriptionPrice(ctx, userID, subscriptionID, &repository.SubscriptionPrice{Price: 499, EffectiveFrom: time.Now()})
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionPrices_Success() {
+	ctx := context.Background()
+	createdAt := time.Date(2025, 8, 15, 10, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT id, subscription_id, price, effective_from, created_at
+		FROM subscription_prices
+		WHERE subscription_id IN ($1,$2)
+		ORDER BY subscription_id, effective_from`
+
+	rows := sqlmock.NewRows([]string{"id", "subscription_id", "price", "effective_from", "created_at"}).
+		AddRow(1, 1, 399, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), createdAt).
+		AddRow(5, 1, 499, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), createdAt).
+		AddRow(2, 2, 299, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), createdAt)
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(1, 2).
+		WillReturnRows(rows)
+
+	result, err := suite.repo.GetSubscriptionPrices(ctx, []int{1, 2})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 3)
+	assert.Equal(suite.T(), 499, result[1].Price)
+	assert.Equal(suite.T(), 2, result[2].SubscriptionID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresRepositoryTestSuite) TestGetSubscriptionPrices_Empty() {
+	result, err := suite.repo.GetSubscriptionPrices(context.Background(), nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
 // Custom matcher for time.Time arguments in mocks
 type AnyTime struct{}
 
@@ -520,4 +1221,4 @@ func (a AnyTime) Match(v driver.Value) bool {
 
 func TestPostgresRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(PostgresRepositoryTestSuite))
-}
\ No newline at end of file
+}
@@ -0,0 +1,361 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type servicesRepository struct {
+	db *sqlx.DB
+}
+
+// NewServicesRepository creates a new instance of PostgreSQL services catalog repository
+func NewServicesRepository(db *sqlx.DB) repository.ServicesRepository {
+	return &servicesRepository{
+		db: db,
+	}
+}
+
+// CreateService inserts a new catalog entry together with its aliases
+func (r *servicesRepository) CreateService(ctx context.Context, service *repository.Service) error {
+	query := `
+		INSERT INTO services (name, name_key, category, default_price, default_currency)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Creating service",
+		logger.String("name", service.Name))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err))
+		return wrapError(ErrCreateServiceFailed, err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query,
+		service.Name,
+		service.NameKey,
+		service.Category,
+		service.DefaultPrice,
+		service.DefaultCurrency).Scan(&service.ID, &service.CreatedAt, &service.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			log.Warn("Service already exists",
+				logger.String("name", service.Name))
+			return ErrServiceAlreadyExists
+		}
+		log.Error("Failed to create service",
+			logger.Error(err),
+			logger.String("name", service.Name))
+		return wrapError(ErrCreateServiceFailed, err)
+	}
+
+	if err := insertServiceAliases(ctx, tx, service); err != nil {
+		if isUniqueViolation(err) {
+			log.Warn("Service alias already exists",
+				logger.String("name", service.Name))
+			return ErrServiceAlreadyExists
+		}
+		log.Error("Failed to create service aliases",
+			logger.Error(err),
+			logger.Int("service_id", service.ID))
+		return wrapError(ErrCreateServiceFailed, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit service creation",
+			logger.Error(err))
+		return wrapError(ErrCreateServiceFailed, err)
+	}
+
+	log.Info("Service created successfully",
+		logger.Int("service_id", service.ID),
+		logger.String("name", service.Name))
+
+	return nil
+}
+
+// GetService retrieves a catalog entry by ID
+func (r *servicesRepository) GetService(ctx context.Context, serviceID int) (*repository.Service, error) {
+	query := `
+		SELECT id, name, name_key, category, default_price, default_currency, created_at, updated_at
+		FROM services
+		WHERE id = $1`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Getting service",
+		logger.Int("service_id", serviceID))
+
+	service := &repository.Service{}
+	if err := r.db.GetContext(ctx, service, query, serviceID); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn("Service not found",
+				logger.Int("service_id", serviceID))
+			return nil, ErrServiceNotFound
+		}
+		log.Error("Failed to get service",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		return nil, wrapError(ErrGetServiceFailed, err)
+	}
+
+	if err := r.loadAliases(ctx, []*repository.Service{service}); err != nil {
+		log.Error("Failed to get service aliases",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		return nil, wrapError(ErrGetServiceFailed, err)
+	}
+
+	return service, nil
+}
+
+// ListServices retrieves catalog entries ordered by name, optionally limited to one category
+func (r *servicesRepository) ListServices(ctx context.Context, category string) ([]*repository.Service, error) {
+	queryBuilder := strings.Builder{}
+	queryBuilder.WriteString(`
+		SELECT id, name, name_key, category, default_price, default_currency, created_at, updated_at
+		FROM services`)
+
+	var args []interface{}
+	if category != "" {
+		queryBuilder.WriteString(" WHERE category = $1")
+		args = append(args, category)
+	}
+	queryBuilder.WriteString(" ORDER BY name ASC")
+
+	log := logger.FromContext(ctx)
+	log.Debug("Listing services",
+		logger.String("category", category))
+
+	services := []*repository.Service{}
+	if err := r.db.SelectContext(ctx, &services, queryBuilder.String(), args...); err != nil {
+		log.Error("Failed to list services",
+			logger.Error(err))
+		return nil, wrapError(ErrListServicesFailed, err)
+	}
+
+	if err := r.loadAliases(ctx, services); err != nil {
+		log.Error("Failed to get service aliases",
+			logger.Error(err))
+		return nil, wrapError(ErrListServicesFailed, err)
+	}
+
+	log.Debug("Services listed successfully",
+		logger.Int("count", len(services)))
+
+	return services, nil
+}
+
+// UpdateService updates a catalog entry and replaces its aliases
+func (r *servicesRepository) UpdateService(ctx context.Context, service *repository.Service) error {
+	query := `
+		UPDATE services
+		SET name = $1, name_key = $2, category = $3, default_price = $4, default_currency = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING created_at, updated_at`
+
+	deleteAliasesQuery := `DELETE FROM service_aliases WHERE service_id = $1`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Updating service",
+		logger.Int("service_id", service.ID))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error("Failed to begin transaction",
+			logger.Error(err))
+		return wrapError(ErrUpdateServiceFailed, err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query,
+		service.Name,
+		service.NameKey,
+		service.Category,
+		service.DefaultPrice,
+		service.DefaultCurrency,
+		service.ID).Scan(&service.CreatedAt, &service.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn("Service not found for update",
+				logger.Int("service_id", service.ID))
+			return ErrServiceNotFound
+		}
+		if isUniqueViolation(err) {
+			log.Warn("Service already exists",
+				logger.String("name", service.Name))
+			return ErrServiceAlreadyExists
+		}
+		log.Error("Failed to update service",
+			logger.Error(err),
+			logger.Int("service_id", service.ID))
+		return wrapError(ErrUpdateServiceFailed, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteAliasesQuery, service.ID); err != nil {
+		log.Error("Failed to delete service aliases",
+			logger.Error(err),
+			logger.Int("service_id", service.ID))
+		return wrapError(ErrUpdateServiceFailed, err)
+	}
+
+	if err := insertServiceAliases(ctx, tx, service); err != nil {
+		if isUniqueViolation(err) {
+			log.Warn("Service alias already exists",
+				logger.Int("service_id", service.ID))
+			return ErrServiceAlreadyExists
+		}
+		log.Error("Failed to create service aliases",
+			logger.Error(err),
+			logger.Int("service_id", service.ID))
+		return wrapError(ErrUpdateServiceFailed, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("Failed to commit service update",
+			logger.Error(err))
+		return wrapError(ErrUpdateServiceFailed, err)
+	}
+
+	log.Info("Service updated successfully",
+		logger.Int("service_id", service.ID))
+
+	return nil
+}
+
+// DeleteService removes a catalog entry. Linked subscriptions keep their name and lose the link.
+func (r *servicesRepository) DeleteService(ctx context.Context, serviceID int) error {
+	query := `DELETE FROM services WHERE id = $1`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Deleting service",
+		logger.Int("service_id", serviceID))
+
+	result, err := r.db.ExecContext(ctx, query, serviceID)
+	if err != nil {
+		log.Error("Failed to delete service",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		return wrapError(ErrDeleteServiceFailed, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("Failed to get rows affected",
+			logger.Error(err))
+		return wrapError(ErrGetRowsAffectedFailed, err)
+	}
+
+	if rowsAffected == 0 {
+		log.Warn("Service not found for deletion",
+			logger.Int("service_id", serviceID))
+		return ErrServiceNotFound
+	}
+
+	log.Info("Service deleted successfully",
+		logger.Int("service_id", serviceID))
+
+	return nil
+}
+
+// ResolveService finds the catalog entry whose name or one of whose aliases has the given normalized key
+func (r *servicesRepository) ResolveService(ctx context.Context, key string) (*repository.Service, error) {
+	query := `
+		SELECT id, name, name_key, category, default_price, default_currency, created_at, updated_at
+		FROM services
+		WHERE name_key = $1
+		OR id = (SELECT service_id FROM service_aliases WHERE alias_key = $1)
+		LIMIT 1`
+
+	log := logger.FromContext(ctx)
+	log.Debug("Resolving service",
+		logger.String("key", key))
+
+	service := &repository.Service{}
+	if err := r.db.GetContext(ctx, service, query, key); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrServiceNotFound
+		}
+		log.Error("Failed to resolve service",
+			logger.Error(err),
+			logger.String("key", key))
+		return nil, wrapError(ErrResolveServiceFailed, err)
+	}
+
+	if err := r.loadAliases(ctx, []*repository.Service{service}); err != nil {
+		log.Error("Failed to get service aliases",
+			logger.Error(err),
+			logger.Int("service_id", service.ID))
+		return nil, wrapError(ErrResolveServiceFailed, err)
+	}
+
+	return service, nil
+}
+
+// loadAliases fills in the aliases of the given catalog entries
+func (r *servicesRepository) loadAliases(ctx context.Context, services []*repository.Service) error {
+	if len(services) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*repository.Service, len(services))
+	placeholders := make([]string, len(services))
+	args := make([]interface{}, len(services))
+	for i, service := range services {
+		service.Aliases = []repository.ServiceAlias{}
+		byID[service.ID] = service
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = service.ID
+	}
+
+	query := fmt.Sprintf(`
+		SELECT service_id, alias, alias_key
+		FROM service_aliases
+		WHERE service_id IN (%s)
+		ORDER BY service_id, alias`, strings.Join(placeholders, ","))
+
+	aliases := []repository.ServiceAlias{}
+	if err := r.db.SelectContext(ctx, &aliases, query, args...); err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		if service, ok := byID[alias.ServiceID]; ok {
+			service.Aliases = append(service.Aliases, alias)
+		}
+	}
+
+	return nil
+}
+
+// insertServiceAliases stores the aliases of a catalog entry
+func insertServiceAliases(ctx context.Context, tx *sqlx.Tx, service *repository.Service) error {
+	query := `
+		INSERT INTO service_aliases (service_id, alias, alias_key)
+		VALUES ($1, $2, $3)`
+
+	for i := range service.Aliases {
+		service.Aliases[i].ServiceID = service.ID
+		if _, err := tx.ExecContext(ctx, query, service.ID, service.Aliases[i].Alias, service.Aliases[i].AliasKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
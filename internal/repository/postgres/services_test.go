@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServicesRepositoryTestSuite struct {
+	suite.Suite
+	db   *sqlx.DB
+	mock sqlmock.Sqlmock
+	repo repository.ServicesRepository
+}
+
+func (suite *ServicesRepositoryTestSuite) SetupTest() {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(suite.T(), err)
+
+	suite.db = sqlx.NewDb(mockDB, "postgres")
+	suite.mock = mock
+	suite.repo = NewServicesRepository(suite.db)
+}
+
+func (suite *ServicesRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *ServicesRepositoryTestSuite) TestCreateService_Success() {
+	ctx := context.Background()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	price := 399
+
+	service := &repository.Service{
+		Name:            "Yandex Plus",
+		NameKey:         "yandex plus",
+		Category:        "music",
+		DefaultPrice:    &price,
+		DefaultCurrency: "RUB",
+		Aliases: []repository.ServiceAlias{
+			{Alias: "Яндекс Плюс", AliasKey: "яндекс плюс"},
+		},
+	}
+
+	expectedQuery := `
+		INSERT INTO services (name, name_key, category, default_price, default_currency)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	expectedAliasQuery := `
+		INSERT INTO service_aliases (service_id, alias, alias_key)
+		VALUES ($1, $2, $3)`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(service.Name, service.NameKey, service.Category, service.DefaultPrice, service.DefaultCurrency).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, now, now))
+	suite.mock.ExpectExec(expectedAliasQuery).
+		WithArgs(1, "Яндекс Плюс", "яндекс плюс").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.CreateService(ctx, service)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, service.ID)
+	assert.Equal(suite.T(), 1, service.Aliases[0].ServiceID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ServicesRepositoryTestSuite) TestCreateService_AlreadyExists() {
+	ctx := context.Background()
+	service := &repository.Service{
+		Name:            "Netflix",
+		NameKey:         "netflix",
+		DefaultCurrency: "RUB",
+	}
+
+	expectedQuery := `
+		INSERT INTO services (name, name_key, category, default_price, default_currency)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(service.Name, service.NameKey, service.Category, service.DefaultPrice, service.DefaultCurrency).
+		WillReturnError(&pq.Error{Code: "23505"})
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.CreateService(ctx, service)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), ErrServiceAlreadyExists, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ServicesRepositoryTestSuite) TestGetService_NotFound() {
+	ctx := context.Background()
+
+	expectedQuery := `
+		SELECT id, name, name_key, category, default_price, default_currency, created_at, updated_at
+		FROM services
+		WHERE id = $1`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs(999).
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := suite.repo.GetService(ctx, 999)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), ErrServiceNotFound, err)
+	assert.Nil(suite.T(), result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ServicesRepositoryTestSuite) TestResolveService_ByAlias() {
+	ctx := context.Background()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	expectedQuery := `
+		SELECT id, name, name_key, category, default_price, default_currency, created_at, updated_at
+		FROM services
+		WHERE name_key = $1
+		OR id = (SELECT service_id FROM service_aliases WHERE alias_key = $1)
+		LIMIT 1`
+
+	expectedAliasesQuery := `
+		SELECT service_id, alias, alias_key
+		FROM service_aliases
+		WHERE service_id IN ($1)
+		ORDER BY service_id, alias`
+
+	suite.mock.ExpectQuery(expectedQuery).
+		WithArgs("яндекс плюс").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "name_key", "category", "default_price", "default_currency", "created_at", "updated_at"}).
+			AddRow(1, "Yandex Plus", "yandex plus", "music", 399, "RUB", now, now))
+	suite.mock.ExpectQuery(expectedAliasesQuery).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"service_id", "alias", "alias_key"}).
+			AddRow(1, "Яндекс Плюс", "яндекс плюс"))
+
+	result, err := suite.repo.ResolveService(ctx, "яндекс плюс")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Yandex Plus", result.Name)
+	assert.Equal(suite.T(), 399, *result.DefaultPrice)
+	assert.Len(suite.T(), result.Aliases, 1)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ServicesRepositoryTestSuite) TestDeleteService_NotFound() {
+	ctx := context.Background()
+
+	suite.mock.ExpectExec(`DELETE FROM services WHERE id = $1`).
+		WithArgs(999).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.DeleteService(ctx, 999)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), ErrServiceNotFound, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func TestServicesRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ServicesRepositoryTestSuite))
+}
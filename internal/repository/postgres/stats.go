@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+type statsRepository struct {
+	db *sqlx.DB
+}
+
+// NewStatsRepository creates a new instance of PostgreSQL subscription stats repository
+func NewStatsRepository(db *sqlx.DB) repository.StatsRepository {
+	return &statsRepository{
+		db: db,
+	}
+}
+
+// GetSubscriptionStats counts active subscriptions, trials and users in one scan
+func (r *statsRepository) GetSubscriptionStats(ctx context.Context) (*repository.SubscriptionStats, error) {
+	query := `
+		SELECT
+			COUNT(*) AS active_subscriptions,
+			COUNT(*) FILTER (WHERE trial_end_date >= CURRENT_DATE) AS trial_subscriptions,
+			COUNT(DISTINCT user_id) AS active_users
+		FROM subscriptions
+		WHERE start_date <= CURRENT_DATE AND (end_date IS NULL OR end_date >= CURRENT_DATE)`
+
+	stats := &repository.SubscriptionStats{}
+	if err := r.db.GetContext(ctx, stats, query); err != nil {
+		logger.FromContext(ctx).Error("Failed to get subscription stats",
+			logger.Error(err))
+		return nil, wrapError(ErrGetSubscriptionStatsFailed, err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSubscriptionStats(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	db := sqlx.NewDb(mockDB, "postgres")
+	defer db.Close()
+
+	expectedQuery := `
+		SELECT
+			COUNT(*) AS active_subscriptions,
+			COUNT(*) FILTER (WHERE trial_end_date >= CURRENT_DATE) AS trial_subscriptions,
+			COUNT(DISTINCT user_id) AS active_users
+		FROM subscriptions
+		WHERE start_date <= CURRENT_DATE AND (end_date IS NULL OR end_date >= CURRENT_DATE)`
+
+	mock.ExpectQuery(expectedQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"active_subscriptions", "trial_subscriptions", "active_users"}).
+			AddRow(42, 3, 17))
+	mock.ExpectQuery(expectedQuery).
+		WillReturnError(sql.ErrConnDone)
+
+	repo := NewStatsRepository(db)
+
+	stats, err := repo.GetSubscriptionStats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &repository.SubscriptionStats{ActiveSubscriptions: 42, TrialSubscriptions: 3, ActiveUsers: 17}, stats)
+
+	stats, err = repo.GetSubscriptionStats(context.Background())
+	assert.Nil(t, stats)
+	assert.ErrorIs(t, err, ErrGetSubscriptionStatsFailed)
+	assert.ErrorIs(t, err, repository.ErrUnavailable)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/tracing"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes of the SQL statement spans
+const (
+	attributeQueryName    = attribute.Key("db.query.name")
+	attributeReturnedRows = attribute.Key("db.response.returned_rows")
+	attributeAffectedRows = attribute.Key("db.response.affected_rows")
+)
+
+// The helpers below run one statement within a span named after the statement, recording the
+// statement text, the number of rows and the error. They accept both *sqlx.DB and *sqlx.Tx.
+
+func getContext(ctx context.Context, q sqlx.QueryerContext, name string, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := startQuery(ctx, name, query)
+	err := sqlx.GetContext(ctx, q, dest, query, args...)
+
+	rows := 0
+	if err == nil {
+		rows = 1
+	}
+	span.SetAttributes(attributeReturnedRows.Int(rows))
+	endQuery(span, err)
+	return err
+}
+
+func selectContext(ctx context.Context, q sqlx.QueryerContext, name string, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := startQuery(ctx, name, query)
+	err := sqlx.SelectContext(ctx, q, dest, query, args...)
+
+	if err == nil {
+		span.SetAttributes(attributeReturnedRows.Int(reflect.Indirect(reflect.ValueOf(dest)).Len()))
+	}
+	endQuery(span, err)
+	return err
+}
+
+func execContext(ctx context.Context, e sqlx.ExecerContext, name string, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, name, query)
+	result, err := e.ExecContext(ctx, query, args...)
+
+	if err == nil {
+		if affected, rowsErr := result.RowsAffected(); rowsErr == nil {
+			span.SetAttributes(attributeAffectedRows.Int64(affected))
+		}
+	}
+	endQuery(span, err)
+	return result, err
+}
+
+func startQuery(ctx context.Context, name string, query string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+			attributeQueryName.String(name),
+		))
+}
+
+// endQuery ends the span. A missing row is an expected outcome, not an error of the statement.
+func endQuery(span trace.Span, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/tracing"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestQuerySpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(config.TracingConfig{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	db := sqlx.NewDb(mockDB, "postgres")
+	defer db.Close()
+
+	listQuery := `
+		SELECT id, price
+		FROM subscriptions
+		WHERE user_id = $1`
+	mock.ExpectQuery(listQuery).
+		WithArgs("user").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "price"}).AddRow(1, 399).AddRow(2, 199))
+	mock.ExpectQuery(`SELECT id, price FROM subscriptions WHERE id = $1`).
+		WithArgs(3).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`DELETE FROM subscriptions WHERE id = $1`).
+		WithArgs(3).
+		WillReturnError(sql.ErrConnDone)
+
+	var subscriptions []*repository.Subscription
+	require.NoError(t, selectContext(context.Background(), db, "subscriptions.list_by_user", &subscriptions, listQuery, "user"))
+	var subscription repository.Subscription
+	require.ErrorIs(t, getContext(context.Background(), db, "subscriptions.get", &subscription, `SELECT id, price FROM subscriptions WHERE id = $1`, 3), sql.ErrNoRows)
+	_, err = execContext(context.Background(), db, "subscriptions.delete", `DELETE FROM subscriptions WHERE id = $1`, 3)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	list := spanAttributes(spans[0])
+	assert.Equal(t, "subscriptions.list_by_user", spans[0].Name)
+	assert.Equal(t, "subscriptions.list_by_user", list[attributeQueryName].AsString())
+	assert.Equal(t, "SELECT id, price FROM subscriptions WHERE user_id = $1", list["db.query.text"].AsString())
+	assert.Equal(t, int64(2), list[attributeReturnedRows].AsInt64())
+
+	assert.Equal(t, int64(0), spanAttributes(spans[1])[attributeReturnedRows].AsInt64())
+	assert.Equal(t, codes.Unset, spans[1].Status.Code, "a missing row is not an error")
+
+	assert.Equal(t, codes.Error, spans[2].Status.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -8,11 +8,80 @@ import (
 // SubscriptionsRepository defines the interface for database interaction
 type SubscriptionsRepository interface {
 	Create(ctx context.Context, subscription *Subscription) error
+	// CreateSubscriptions creates all of the subscriptions or none of them
+	CreateSubscriptions(ctx context.Context, subscriptions []*Subscription) error
 	GetSubscription(ctx context.Context, userID string, subscriptionID int) (*Subscription, error)
-	UpdateSubscription(ctx context.Context, subscription *Subscription, userID string, subscriptionID int) error
-	DeleteSubscription(ctx context.Context, userID string, subscriptionID int) error
+	// UpdateSubscription and DeleteSubscription apply only to the expected version of the subscription,
+	// or to any version if it is 0
+	UpdateSubscription(ctx context.Context, subscription *Subscription, userID string, subscriptionID int, expectedVersion int) error
+	DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error
+	// PatchSubscription writes only the listed columns of the subscription, each one of the Column
+	// constants, and then sets the subscription to the stored state. It applies to the expected
+	// version like UpdateSubscription.
+	PatchSubscription(ctx context.Context, subscription *Subscription, columns []string, userID string, subscriptionID int, expectedVersion int) error
 	GetSubscriptionsByUserID(ctx context.Context, userID string) ([]*Subscription, error)
-	GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, startDate, endDate time.Time) ([]*Subscription, error)
+	// ListSubscriptions returns one page of subscriptions matching the filter and the number of all matching subscriptions
+	ListSubscriptions(ctx context.Context, filter SubscriptionFilter) ([]*Subscription, int, error)
+	GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, serviceIDs []int, startDate, endDate time.Time) ([]*Subscription, error)
+	GetTrialsEndingBetween(ctx context.Context, userID string, from, to time.Time) ([]*Subscription, error)
+	// AddSubscriptionPrice returns the version of the subscription after the change, which is new
+	// when the current price changes
+	AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, price *SubscriptionPrice) (int, error)
+	GetSubscriptionPrices(ctx context.Context, subscriptionIDs []int) ([]*SubscriptionPrice, error)
+	GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string) (*ExchangeRate, error)
 	Close() error
 	RunMigrations(migrationsFilePath string) error
-}
\ No newline at end of file
+}
+
+// ServicesRepository defines the interface for the services catalog
+type ServicesRepository interface {
+	CreateService(ctx context.Context, service *Service) error
+	GetService(ctx context.Context, serviceID int) (*Service, error)
+	ListServices(ctx context.Context, category string) ([]*Service, error)
+	UpdateService(ctx context.Context, service *Service) error
+	DeleteService(ctx context.Context, serviceID int) error
+	// ResolveService finds the catalog entry whose name or alias has the given normalized key
+	ResolveService(ctx context.Context, key string) (*Service, error)
+}
+
+// ExchangeRatesRepository maintains the exchange rates that cost reports convert with
+type ExchangeRatesRepository interface {
+	ListExchangeRates(ctx context.Context) ([]*ExchangeRate, error)
+	// SetExchangeRate inserts or replaces the rate of the currency pair and sets its UpdatedAt
+	SetExchangeRate(ctx context.Context, rate *ExchangeRate) error
+}
+
+// AuditRepository defines the interface for reading the audit log. Entries are written by
+// SubscriptionsRepository in the transaction of the mutation they describe.
+type AuditRepository interface {
+	GetSubscriptionHistory(ctx context.Context, userID string, subscriptionID int) ([]*AuditEntry, error)
+	ListAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+}
+
+// HealthRepository reports the state of the database for readiness checks
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	// MigrationVersion returns the applied schema version, 0 if no migration was applied. A dirty
+	// version means a migration failed halfway.
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// StatsRepository aggregates subscriptions across all users for monitoring
+type StatsRepository interface {
+	GetSubscriptionStats(ctx context.Context) (*SubscriptionStats, error)
+}
+
+// IdempotencyRepository stores the responses of requests made with idempotency keys
+type IdempotencyRepository interface {
+	// ReserveKey stores a record without a response that expires after lease and returns the token
+	// of the reservation, unless an unexpired record with the same scope and key exists. The
+	// existing record is returned in that case.
+	ReserveKey(ctx context.Context, scope, key, fingerprint string, lease time.Duration) (string, *IdempotencyRecord, error)
+	// CompleteKey stores the response of a reserved key, which then expires after ttl. The record is
+	// left as it is if it no longer has the given reservation.
+	CompleteKey(ctx context.Context, scope, key, reservation string, statusCode int, contentType, etag string, body []byte, ttl time.Duration) error
+	// ReleaseKey removes the reservation of a key whose request failed, so that it can be retried
+	ReleaseKey(ctx context.Context, scope, key, reservation string) error
+	// DeleteExpiredKeys removes expired records and returns their number
+	DeleteExpiredKeys(ctx context.Context) (int64, error)
+}
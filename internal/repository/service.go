@@ -0,0 +1,24 @@
+package repository
+
+import "time"
+
+// Service is a catalog entry that subscriptions link to. NameKey and AliasKey are the normalized
+// forms used for case-insensitive lookups.
+type Service struct {
+	ID              int            `db:"id" json:"id"`
+	Name            string         `db:"name" json:"name"`
+	NameKey         string         `db:"name_key" json:"-"`
+	Category        string         `db:"category" json:"category"`
+	DefaultPrice    *int           `db:"default_price" json:"default_price,omitempty"` // Nullable
+	DefaultCurrency string         `db:"default_currency" json:"default_currency"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+	Aliases         []ServiceAlias `db:"-" json:"aliases"`
+}
+
+// ServiceAlias is an alternative name of a catalog entry
+type ServiceAlias struct {
+	ServiceID int    `db:"service_id" json:"service_id"`
+	Alias     string `db:"alias" json:"alias"`
+	AliasKey  string `db:"alias_key" json:"-"`
+}
@@ -3,10 +3,90 @@ package repository
 import "time"
 
 type Subscription struct {
-    ID          int       `db:"id" json:"id"`
-    Price       int       `db:"price" json:"price"`
-    UserID      string    `db:"user_id" json:"user_id"`
-    ServiceName string    `db:"service_name" json:"service_name"`
-    StartDate   time.Time `db:"start_date" json:"start_date"`
-    EndDate     *time.Time `db:"end_date" json:"end_date,omitempty"` // Nullable
+	ID                    int        `db:"id" json:"id"`
+	Price                 int        `db:"price" json:"price"`
+	Currency              string     `db:"currency" json:"currency"`
+	BillingCycle          string     `db:"billing_cycle" json:"billing_cycle"`
+	BillingIntervalMonths *int       `db:"billing_interval_months" json:"billing_interval_months,omitempty"` // Only for custom cycles
+	UserID                string     `db:"user_id" json:"user_id"`
+	ServiceName           string     `db:"service_name" json:"service_name"`
+	ServiceID             *int       `db:"service_id" json:"service_id,omitempty"` // Catalog entry, nullable
+	StartDate             time.Time  `db:"start_date" json:"start_date"`
+	EndDate               *time.Time `db:"end_date" json:"end_date,omitempty"`             // Nullable
+	TrialEndDate          *time.Time `db:"trial_end_date" json:"trial_end_date,omitempty"` // Last free day, nullable
+	Version               int        `db:"version" json:"version"`                         // Incremented by every update
+}
+
+// Columns of a subscription that PatchSubscription can write
+const (
+	ColumnServiceName           = "service_name"
+	ColumnServiceID             = "service_id"
+	ColumnPrice                 = "price"
+	ColumnCurrency              = "currency"
+	ColumnBillingCycle          = "billing_cycle"
+	ColumnBillingIntervalMonths = "billing_interval_months"
+	ColumnStartDate             = "start_date"
+	ColumnEndDate               = "end_date"
+	ColumnTrialEndDate          = "trial_end_date"
+)
+
+// SubscriptionPrice is an entry of a subscription's price schedule. The price is in force from
+// EffectiveFrom until the next entry's EffectiveFrom.
+type SubscriptionPrice struct {
+	ID             int       `db:"id" json:"id"`
+	SubscriptionID int       `db:"subscription_id" json:"subscription_id"`
+	Price          int       `db:"price" json:"price"`
+	EffectiveFrom  time.Time `db:"effective_from" json:"effective_from"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// ExchangeRate is the amount of QuoteCurrency one unit of BaseCurrency is worth
+type ExchangeRate struct {
+	BaseCurrency  string    `db:"base_currency" json:"base_currency"`
+	QuoteCurrency string    `db:"quote_currency" json:"quote_currency"`
+	Rate          float64   `db:"rate" json:"rate"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Sort keys of the subscription list
+const (
+	SortByPrice       = "price"
+	SortByServiceName = "service_name"
+	SortByStartDate   = "start_date"
+	SortByEndDate     = "end_date" // Open-ended subscriptions sort as if they ended last
+)
+
+// Status filters of the subscription list
+const (
+	StatusActive = "active" // Started and not ended as of today
+	StatusEnded  = "ended"  // Ended before today, so subscriptions that haven't started match neither status
+)
+
+// SubscriptionFilter selects one page of a user's subscriptions. Empty fields don't filter.
+type SubscriptionFilter struct {
+	UserID            string
+	Status            string
+	ServiceNamePrefix string     // Matched ignoring case
+	MinPrice          *int       // Inclusive
+	MaxPrice          *int       // Inclusive
+	ActiveFrom        *time.Time // Subscription overlaps [ActiveFrom, ActiveTo]
+	ActiveTo          *time.Time
+	SortBy            string // One of the SortBy constants, defaults to SortByStartDate
+	Descending        bool
+	After             *SubscriptionCursor // Keyset position, nil for the first page
+	Limit             int
+}
+
+// SubscriptionCursor is the position right after the last row of a page: the sort key of that row
+// and its ID as a tie-breaker. Dates are formatted as YYYY-MM-DD, a missing end date as "infinity".
+type SubscriptionCursor struct {
+	Value string
+	ID    int
+}
+
+// SubscriptionStats are counts over all users as of today
+type SubscriptionStats struct {
+	ActiveSubscriptions int `db:"active_subscriptions"` // Started and not ended, same as StatusActive
+	TrialSubscriptions  int `db:"trial_subscriptions"`  // Active and still in the free trial
+	ActiveUsers         int `db:"active_users"`         // Users with at least one active subscription
 }
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+)
+
+// Server is the HTTP server of the API. On shutdown it stops accepting connections, waits for
+// in-flight requests and then releases the registered resources.
+type Server struct {
+	httpServer *http.Server
+	cfg        config.ServerConfig
+	closers    []closer
+}
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New creates a server for the handler with the address and timeouts from the configuration
+func New(cfg config.ServerConfig, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+		cfg: cfg,
+	}
+}
+
+// OnShutdown registers a resource to release after the connections are drained. Resources are
+// released in registration order, so register them from the first to stop to the last.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.closers = append(s.closers, closer{name: name, fn: fn})
+}
+
+// Run serves requests until ctx is done or the server fails, then shuts down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, listener)
+}
+
+func (s *Server) serve(ctx context.Context, listener net.Listener) error {
+	log := logger.Global()
+	log.Info("starting HTTP server",
+		logger.String("address", listener.Addr().String()))
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Error("HTTP server failed", logger.Error(err))
+			runErr = err
+		}
+	case <-ctx.Done():
+		log.Info("shutting down HTTP server")
+	}
+
+	return errors.Join(runErr, s.shutdown())
+}
+
+// shutdown drains the connections within the shutdown timeout, then releases the resources. Each
+// stage gets its own deadline so that a slow drain doesn't leave no time for the cleanup.
+func (s *Server) shutdown() error {
+	log := logger.Global()
+
+	var errs []error
+
+	drainCtx, cancel := s.shutdownContext()
+	err := s.httpServer.Shutdown(drainCtx)
+	cancel()
+	if err != nil {
+		// Requests still running past the deadline are cut off
+		log.Error("failed to drain HTTP connections", logger.Error(err))
+		errs = append(errs, err)
+		_ = s.httpServer.Close()
+	} else {
+		log.Info("HTTP connections drained")
+	}
+
+	for _, c := range s.closers {
+		closeCtx, cancel := s.shutdownContext()
+		err := c.fn(closeCtx)
+		cancel()
+		if err != nil {
+			log.Error("failed to close resource",
+				logger.Error(err),
+				logger.String("resource", c.name))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) shutdownContext() (context.Context, context.CancelFunc) {
+	if s.cfg.ShutdownTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+}
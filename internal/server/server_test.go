@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_DrainsRequestsThenClosesInOrder(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})
+
+	srv := New(config.ServerConfig{ShutdownTimeout: time.Second}, handler)
+	var closed []string
+	srv.OnShutdown("workers", func(context.Context) error {
+		closed = append(closed, "workers")
+		return nil
+	})
+	srv.OnShutdown("database", func(context.Context) error {
+		closed = append(closed, "database")
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- srv.serve(ctx, listener)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	res := <-response
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+	assert.NoError(t, <-runErr)
+	assert.Equal(t, []string{"workers", "database"}, closed)
+}
+
+func TestServer_DrainDeadlineExceeded(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	defer close(release)
+
+	srv := New(config.ServerConfig{ShutdownTimeout: 50 * time.Millisecond}, handler)
+	closeErr := errors.New("close failed")
+	databaseClosed := false
+	srv.OnShutdown("workers", func(context.Context) error {
+		return closeErr
+	})
+	srv.OnShutdown("database", func(context.Context) error {
+		databaseClosed = true
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- srv.serve(ctx, listener)
+	}()
+
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	err = <-runErr
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, closeErr)
+	assert.True(t, databaseClosed, "later resources are closed even if an earlier one fails")
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultAuditPageSize is used for audit log pages that don't specify a limit
+const DefaultAuditPageSize = 50
+
+type auditService struct {
+	repo      repository.AuditRepository
+	validator *validator.Validate
+}
+
+// NewAuditService creates a new instance of audit log service
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{
+		repo:      repo,
+		validator: validation.New(),
+	}
+}
+
+// GetSubscriptionHistory retrieves the change history of a subscription. The history outlives
+// the subscription itself, so deleted subscriptions can still be inspected.
+func (s *auditService) GetSubscriptionHistory(ctx context.Context, userID string, subscriptionID int) ([]*repository.AuditEntry, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("getting subscription history",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID))
+
+	// Validate user ID
+	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
+		log.Error("invalid user ID format",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, ErrInvalidUserID
+	}
+
+	// Validate subscription ID
+	if subscriptionID <= 0 {
+		log.Error("invalid subscription ID",
+			logger.Int("subscription_id", subscriptionID))
+		return nil, ErrInvalidSubscriptionID
+	}
+
+	entries, err := s.repo.GetSubscriptionHistory(ctx, userID, subscriptionID)
+	if err != nil {
+		log.Error("failed to get subscription history from repository",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, repositoryError(err, nil)
+	}
+
+	// Every subscription has at least its creation recorded
+	if len(entries) == 0 {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return entries, nil
+}
+
+// ListAuditEntries retrieves one page of the audit log
+func (s *auditService) ListAuditEntries(ctx context.Context, req *ListAuditEntriesRequest) (*AuditPage, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("listing audit entries",
+		logger.String("user_id", req.UserID),
+		logger.String("actor", req.Actor))
+
+	// Validate request
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("audit log request validation failed",
+			logger.Error(err))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	filter, err := req.ToAuditFilter()
+	if err != nil {
+		log.Error("invalid audit log filters",
+			logger.Error(err))
+		return nil, err
+	}
+
+	// Fetch one extra entry to know whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+
+	entries, err := s.repo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		log.Error("failed to list audit entries from repository",
+			logger.Error(err))
+		return nil, repositoryError(err, nil)
+	}
+
+	page := &AuditPage{Entries: entries}
+	if len(entries) > pageSize {
+		page.Entries = entries[:pageSize]
+		page.NextBeforeID = page.Entries[pageSize-1].ID
+	}
+
+	return page, nil
+}
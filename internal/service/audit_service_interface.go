@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// AuditService defines the interface for reading the audit log of subscription changes
+type AuditService interface {
+	// GetSubscriptionHistory returns every recorded change of a subscription, oldest first
+	GetSubscriptionHistory(ctx context.Context, userID string, subscriptionID int) ([]*repository.AuditEntry, error)
+
+	// ListAuditEntries returns one page of the audit log, newest first
+	ListAuditEntries(ctx context.Context, req *ListAuditEntriesRequest) (*AuditPage, error)
+}
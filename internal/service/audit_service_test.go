@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// MockAuditRepository is a mock implementation of AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) GetSubscriptionHistory(ctx context.Context, userID string, subscriptionID int) ([]*repository.AuditEntry, error) {
+	args := m.Called(ctx, userID, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.AuditEntry), args.Error(1)
+}
+
+func (m *MockAuditRepository) ListAuditEntries(ctx context.Context, filter repository.AuditFilter) ([]*repository.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.AuditEntry), args.Error(1)
+}
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+	mockRepo *MockAuditRepository
+	service  AuditService
+}
+
+func (suite *AuditServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(MockAuditRepository)
+	suite.service = NewAuditService(suite.mockRepo)
+}
+
+func (suite *AuditServiceTestSuite) TestGetSubscriptionHistory_Success() {
+	ctx := context.Background()
+	userID := "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	entries := []*repository.AuditEntry{
+		{ID: 1, SubscriptionID: 5, UserID: userID, Operation: repository.AuditOperationCreate},
+		{ID: 4, SubscriptionID: 5, UserID: userID, Operation: repository.AuditOperationDelete},
+	}
+
+	suite.mockRepo.On("GetSubscriptionHistory", ctx, userID, 5).Return(entries, nil)
+
+	result, err := suite.service.GetSubscriptionHistory(ctx, userID, 5)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entries, result)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuditServiceTestSuite) TestGetSubscriptionHistory_NotFound() {
+	ctx := context.Background()
+	userID := "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+	suite.mockRepo.On("GetSubscriptionHistory", ctx, userID, 999).Return([]*repository.AuditEntry{}, nil)
+
+	result, err := suite.service.GetSubscriptionHistory(ctx, userID, 999)
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
+}
+
+func (suite *AuditServiceTestSuite) TestGetSubscriptionHistory_InvalidUserID() {
+	result, err := suite.service.GetSubscriptionHistory(context.Background(), "invalid-uuid", 1)
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrInvalidUserID, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetSubscriptionHistory", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AuditServiceTestSuite) TestListAuditEntries_NextPage() {
+	ctx := context.Background()
+	req := &ListAuditEntriesRequest{
+		Actor:     "admin-user",
+		Operation: repository.AuditOperationPriceChange,
+		From:      "2025-03-01",
+		To:        "03-2025",
+		BeforeID:  100,
+		Limit:     2,
+	}
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := GetLastDayOfMonth(from)
+	expectedFilter := repository.AuditFilter{
+		Actor:     "admin-user",
+		Operation: repository.AuditOperationPriceChange,
+		From:      &from,
+		To:        &to,
+		BeforeID:  100,
+		Limit:     3,
+	}
+	entries := []*repository.AuditEntry{{ID: 99}, {ID: 97}, {ID: 90}}
+
+	suite.mockRepo.On("ListAuditEntries", ctx, expectedFilter).Return(entries, nil)
+
+	result, err := suite.service.ListAuditEntries(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Entries, 2)
+	assert.Equal(suite.T(), int64(97), result.NextBeforeID)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuditServiceTestSuite) TestListAuditEntries_LastPage() {
+	ctx := context.Background()
+	entries := []*repository.AuditEntry{{ID: 3}, {ID: 1}}
+
+	suite.mockRepo.On("ListAuditEntries", ctx, repository.AuditFilter{Limit: DefaultAuditPageSize + 1}).Return(entries, nil)
+
+	result, err := suite.service.ListAuditEntries(ctx, &ListAuditEntriesRequest{})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Entries, 2)
+	assert.Zero(suite.T(), result.NextBeforeID)
+}
+
+func (suite *AuditServiceTestSuite) TestListAuditEntries_InvalidDateRange() {
+	req := &ListAuditEntriesRequest{From: "2025-03-10", To: "2025-03-01"}
+
+	result, err := suite.service.ListAuditEntries(context.Background(), req)
+
+	assert.Nil(suite.T(), result)
+	assert.True(suite.T(), errors.Is(err, ErrInvalidDateRange))
+	suite.mockRepo.AssertNotCalled(suite.T(), "ListAuditEntries", mock.Anything, mock.Anything)
+}
+
+func TestAuditServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
@@ -0,0 +1,129 @@
+package service
+
+import "time"
+
+// Supported billing cycles
+const (
+	BillingCycleWeekly    = "weekly"
+	BillingCycleMonthly   = "monthly"
+	BillingCycleQuarterly = "quarterly"
+	BillingCycleYearly    = "yearly"
+	BillingCycleCustom    = "custom" // Every BillingIntervalMonths months
+)
+
+// DefaultBillingCycle is used for subscriptions that don't specify a billing cycle
+const DefaultBillingCycle = BillingCycleMonthly
+
+func billingCycleOrDefault(cycle string) string {
+	if cycle == "" {
+		return DefaultBillingCycle
+	}
+	return cycle
+}
+
+func customIntervalOrNil(cycle string, months int) *int {
+	if cycle != BillingCycleCustom {
+		return nil
+	}
+	return &months
+}
+
+// billingIntervalMonths returns the number of months between two charges,
+// or 0 for cycles that are not expressed in months
+func billingIntervalMonths(cycle string, customMonths *int) int {
+	switch billingCycleOrDefault(cycle) {
+	case BillingCycleWeekly:
+		return 0
+	case BillingCycleQuarterly:
+		return 3
+	case BillingCycleYearly:
+		return 12
+	case BillingCycleCustom:
+		if customMonths != nil && *customMonths > 0 {
+			return *customMonths
+		}
+		return 1
+	default:
+		return 1
+	}
+}
+
+// AddMonthsClamped adds months to t, clamping the day to the last day of the resulting month
+// so that a charge anchored on Jan 31 falls on Feb 28/29 instead of early March
+func AddMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := GetLastDayOfMonth(firstOfTarget).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// nthChargeDate returns the date of the n-th charge (starting from 0) of a subscription anchored at start
+func nthChargeDate(start time.Time, cycle string, customMonths *int, n int) time.Time {
+	interval := billingIntervalMonths(cycle, customMonths)
+	if interval == 0 {
+		return start.AddDate(0, 0, 7*n)
+	}
+	return AddMonthsClamped(start, n*interval)
+}
+
+// firstChargeIndex returns the index of a charge that happened no later than the earliest charge at or
+// after from, so that callers can skip past charges without walking through them one by one
+func firstChargeIndex(subStart time.Time, cycle string, customMonths *int, from time.Time) int {
+	if !from.After(subStart) {
+		return 0
+	}
+
+	var n int
+	interval := billingIntervalMonths(cycle, customMonths)
+	if interval == 0 {
+		n = int(from.Sub(subStart).Hours()/24) / 7
+	} else {
+		monthsDiff := (from.Year()-subStart.Year())*12 + int(from.Month()) - int(subStart.Month())
+		n = monthsDiff/interval - 1
+	}
+
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// monthlyEquivalentPrice converts a per-charge price into the price of one month of service
+func monthlyEquivalentPrice(price int, cycle string, customMonths *int) float64 {
+	interval := billingIntervalMonths(cycle, customMonths)
+	if interval == 0 {
+		return float64(price) * 52 / 12
+	}
+	return float64(price) / float64(interval)
+}
+
+// CalculateChargeDates returns the dates of every charge of a subscription that fall
+// within the given period and while the subscription is active
+func CalculateChargeDates(subStart time.Time, subEnd *time.Time, cycle string, customMonths *int, periodStart, periodEnd time.Time) []time.Time {
+	actualEnd := periodEnd
+	if subEnd != nil && subEnd.Before(periodEnd) {
+		actualEnd = *subEnd
+	}
+
+	if subStart.After(actualEnd) {
+		return nil
+	}
+
+	n := firstChargeIndex(subStart, cycle, customMonths, periodStart)
+	var dates []time.Time
+	for {
+		chargeDate := nthChargeDate(subStart, cycle, customMonths, n)
+		if chargeDate.After(actualEnd) {
+			break
+		}
+		if !chargeDate.Before(periodStart) {
+			dates = append(dates, chargeDate)
+		}
+		n++
+	}
+
+	return dates
+}
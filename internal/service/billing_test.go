@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddMonthsClamped(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    time.Time
+		months   int
+		expected time.Time
+	}{
+		{
+			name:     "Regular month",
+			input:    time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			months:   1,
+			expected: time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "End of month clamped to February",
+			input:    time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			months:   1,
+			expected: time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Leap year",
+			input:    time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			months:   1,
+			expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Across years",
+			input:    time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC),
+			months:   15,
+			expected: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AddMonthsClamped(tt.input, tt.months)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCalculateChargeDates(t *testing.T) {
+	periodStart := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC)
+	six := 6
+
+	tests := []struct {
+		name         string
+		subStart     time.Time
+		subEnd       *time.Time
+		cycle        string
+		customMonths *int
+		expected     int
+	}{
+		{
+			name:     "Monthly for a full year",
+			subStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleMonthly,
+			expected: 12,
+		},
+		{
+			name:     "Default cycle is monthly",
+			subStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			cycle:    "",
+			expected: 12,
+		},
+		{
+			name:     "Monthly started before the period",
+			subStart: time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleMonthly,
+			expected: 12,
+		},
+		{
+			name:     "Monthly ended mid-period",
+			subStart: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			subEnd:   timePtr(time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)),
+			cycle:    BillingCycleMonthly,
+			expected: 3,
+		},
+		{
+			name:     "Quarterly",
+			subStart: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleQuarterly,
+			expected: 4, // Feb, May, Aug, Nov
+		},
+		{
+			name:     "Yearly charged once",
+			subStart: time.Date(2023, 9, 15, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleYearly,
+			expected: 1,
+		},
+		{
+			name:     "Yearly charged outside the period",
+			subStart: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleYearly,
+			expected: 0,
+		},
+		{
+			name:     "Weekly",
+			subStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleWeekly,
+			expected: 53,
+		},
+		{
+			name:     "Weekly started long before the period",
+			subStart: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC),
+			cycle:    BillingCycleWeekly,
+			expected: 52,
+		},
+		{
+			name:         "Every six months",
+			subStart:     time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC),
+			cycle:        BillingCycleCustom,
+			customMonths: &six,
+			expected:     2, // Apr and Oct
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateChargeDates(tt.subStart, tt.subEnd, tt.cycle, tt.customMonths, periodStart, periodEnd)
+			assert.Len(t, result, tt.expected)
+			for _, date := range result {
+				assert.False(t, date.Before(periodStart))
+				assert.False(t, date.After(periodEnd))
+			}
+		})
+	}
+}
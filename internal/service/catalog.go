@@ -0,0 +1,40 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// NormalizeServiceName turns a service name or alias into the key used for case-insensitive
+// catalog lookups: surrounding and repeated whitespace is collapsed and letters are lower-cased
+func NormalizeServiceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// serviceAliases builds the alias list of a catalog entry, dropping blanks, duplicates
+// and aliases equal to the canonical name
+func serviceAliases(name string, aliases []string) []repository.ServiceAlias {
+	seen := map[string]bool{NormalizeServiceName(name): true}
+	result := make([]repository.ServiceAlias, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.Join(strings.Fields(alias), " ")
+		key := NormalizeServiceName(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, repository.ServiceAlias{Alias: alias, AliasKey: key})
+	}
+	return result
+}
+
+// catalogNames returns the canonical name and every alias of a catalog entry
+func catalogNames(service *repository.Service) []string {
+	names := make([]string, 0, len(service.Aliases)+1)
+	names = append(names, service.Name)
+	for _, alias := range service.Aliases {
+		names = append(names, alias.Alias)
+	}
+	return names
+}
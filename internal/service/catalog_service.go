@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+type catalogService struct {
+	repo      repository.ServicesRepository
+	validator *validator.Validate
+}
+
+// NewCatalogService creates a new instance of services catalog service
+func NewCatalogService(repo repository.ServicesRepository) CatalogService {
+	return &catalogService{
+		repo:      repo,
+		validator: validation.New(),
+	}
+}
+
+// CreateService creates a new catalog entry
+func (s *catalogService) CreateService(ctx context.Context, req *CreateServiceRequest) (*repository.Service, error) {
+	log := logger.FromContext(ctx)
+	log.Info("creating new service",
+		logger.String("name", req.Name))
+
+	req.DefaultCurrency = NormalizeCurrency(req.DefaultCurrency)
+
+	// Validate request
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("service creation validation failed",
+			logger.Error(err),
+			logger.String("name", req.Name))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	service := req.ToServiceModel()
+
+	if err := s.checkNamesAvailable(ctx, service); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateService(ctx, service); err != nil {
+		log.Error("failed to create service in repository",
+			logger.Error(err),
+			logger.String("name", service.Name))
+		if errors.Is(err, repository.ErrServiceAlreadyExists) {
+			return nil, ErrServiceAlreadyExists
+		}
+		return nil, repositoryError(err, nil)
+	}
+
+	log.Info("service created successfully",
+		logger.Int("service_id", service.ID),
+		logger.String("name", service.Name))
+
+	return service, nil
+}
+
+// GetService retrieves a catalog entry
+func (s *catalogService) GetService(ctx context.Context, serviceID int) (*repository.Service, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("getting service",
+		logger.Int("service_id", serviceID))
+
+	if serviceID <= 0 {
+		log.Error("invalid service ID",
+			logger.Int("service_id", serviceID))
+		return nil, ErrInvalidServiceID
+	}
+
+	service, err := s.repo.GetService(ctx, serviceID)
+	if err != nil {
+		log.Error("failed to get service from repository",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		if errors.Is(err, repository.ErrServiceNotFound) {
+			return nil, ErrServiceNotFound
+		}
+		return nil, repositoryError(err, nil)
+	}
+
+	return service, nil
+}
+
+// ListServices retrieves the catalog, optionally limited to one category
+func (s *catalogService) ListServices(ctx context.Context, category string) ([]*repository.Service, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("listing services",
+		logger.String("category", category))
+
+	services, err := s.repo.ListServices(ctx, category)
+	if err != nil {
+		log.Error("failed to list services from repository",
+			logger.Error(err))
+		return nil, repositoryError(err, nil)
+	}
+
+	return services, nil
+}
+
+// UpdateService updates a catalog entry, replacing its aliases
+func (s *catalogService) UpdateService(ctx context.Context, serviceID int, req *UpdateServiceRequest) (*repository.Service, error) {
+	log := logger.FromContext(ctx)
+	log.Info("updating service",
+		logger.Int("service_id", serviceID))
+
+	if serviceID <= 0 {
+		log.Error("invalid service ID",
+			logger.Int("service_id", serviceID))
+		return nil, ErrInvalidServiceID
+	}
+
+	req.DefaultCurrency = NormalizeCurrency(req.DefaultCurrency)
+
+	// Validate request
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("service update validation failed",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	service := req.ToServiceModel()
+	service.ID = serviceID
+
+	if err := s.checkNamesAvailable(ctx, service); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateService(ctx, service); err != nil {
+		log.Error("failed to update service in repository",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		switch {
+		case errors.Is(err, repository.ErrServiceNotFound):
+			return nil, ErrServiceNotFound
+		case errors.Is(err, repository.ErrServiceAlreadyExists):
+			return nil, ErrServiceAlreadyExists
+		}
+		return nil, repositoryError(err, nil)
+	}
+
+	log.Info("service updated successfully",
+		logger.Int("service_id", serviceID))
+
+	return service, nil
+}
+
+// DeleteService deletes a catalog entry
+func (s *catalogService) DeleteService(ctx context.Context, serviceID int) error {
+	log := logger.FromContext(ctx)
+	log.Info("deleting service",
+		logger.Int("service_id", serviceID))
+
+	if serviceID <= 0 {
+		log.Error("invalid service ID",
+			logger.Int("service_id", serviceID))
+		return ErrInvalidServiceID
+	}
+
+	if err := s.repo.DeleteService(ctx, serviceID); err != nil {
+		log.Error("failed to delete service from repository",
+			logger.Error(err),
+			logger.Int("service_id", serviceID))
+		if errors.Is(err, repository.ErrServiceNotFound) {
+			return ErrServiceNotFound
+		}
+		return repositoryError(err, nil)
+	}
+
+	log.Info("service deleted successfully",
+		logger.Int("service_id", serviceID))
+
+	return nil
+}
+
+// ResolveService finds the catalog entry by its name or any alias, ignoring case
+func (s *catalogService) ResolveService(ctx context.Context, name string) (*repository.Service, error) {
+	log := logger.FromContext(ctx)
+	key := NormalizeServiceName(name)
+	if key == "" {
+		return nil, ErrInvalidServiceName
+	}
+
+	service, err := s.repo.ResolveService(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrServiceNotFound) {
+			return nil, ErrServiceNotFound
+		}
+		log.Error("failed to resolve service",
+			logger.Error(err),
+			logger.String("name", name))
+		return nil, repositoryError(err, nil)
+	}
+
+	return service, nil
+}
+
+// checkNamesAvailable makes sure neither the name nor the aliases of a catalog entry already
+// belong to a different entry. Names and aliases share one namespace, which the database
+// only enforces within each table.
+func (s *catalogService) checkNamesAvailable(ctx context.Context, service *repository.Service) error {
+	log := logger.FromContext(ctx)
+	keys := []string{service.NameKey}
+	for _, alias := range service.Aliases {
+		keys = append(keys, alias.AliasKey)
+	}
+
+	for _, key := range keys {
+		existing, err := s.repo.ResolveService(ctx, key)
+		if errors.Is(err, repository.ErrServiceNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Error("failed to check service name availability",
+				logger.Error(err),
+				logger.String("key", key))
+			return repositoryError(err, nil)
+		}
+		if existing.ID != service.ID {
+			log.Warn("service name or alias already taken",
+				logger.String("key", key),
+				logger.Int("service_id", existing.ID))
+			return ErrServiceAlreadyExists
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// CatalogService defines the interface for services catalog business logic
+type CatalogService interface {
+	CreateService(ctx context.Context, req *CreateServiceRequest) (*repository.Service, error)
+	GetService(ctx context.Context, serviceID int) (*repository.Service, error)
+	ListServices(ctx context.Context, category string) ([]*repository.Service, error)
+	UpdateService(ctx context.Context, serviceID int, req *UpdateServiceRequest) (*repository.Service, error)
+	DeleteService(ctx context.Context, serviceID int) error
+
+	// ResolveService finds the catalog entry by its name or any alias, ignoring case
+	ResolveService(ctx context.Context, name string) (*repository.Service, error)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// MockServicesRepository is a mock implementation of ServicesRepository
+type MockServicesRepository struct {
+	mock.Mock
+}
+
+func (m *MockServicesRepository) CreateService(ctx context.Context, service *repository.Service) error {
+	args := m.Called(ctx, service)
+	if args.Error(0) == nil {
+		service.ID = 1 // Simulate auto-generated ID
+	}
+	return args.Error(0)
+}
+
+func (m *MockServicesRepository) GetService(ctx context.Context, serviceID int) (*repository.Service, error) {
+	args := m.Called(ctx, serviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Service), args.Error(1)
+}
+
+func (m *MockServicesRepository) ListServices(ctx context.Context, category string) ([]*repository.Service, error) {
+	args := m.Called(ctx, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Service), args.Error(1)
+}
+
+func (m *MockServicesRepository) UpdateService(ctx context.Context, service *repository.Service) error {
+	args := m.Called(ctx, service)
+	return args.Error(0)
+}
+
+func (m *MockServicesRepository) DeleteService(ctx context.Context, serviceID int) error {
+	args := m.Called(ctx, serviceID)
+	return args.Error(0)
+}
+
+func (m *MockServicesRepository) ResolveService(ctx context.Context, key string) (*repository.Service, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Service), args.Error(1)
+}
+
+type CatalogServiceTestSuite struct {
+	suite.Suite
+	mockRepo *MockServicesRepository
+	service  CatalogService
+}
+
+func (suite *CatalogServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(MockServicesRepository)
+	suite.service = NewCatalogService(suite.mockRepo)
+}
+
+func (suite *CatalogServiceTestSuite) TestCreateService_Success() {
+	ctx := context.Background()
+	price := 399
+	req := &CreateServiceRequest{
+		Name:         "  Yandex   Plus ",
+		Category:     "music",
+		DefaultPrice: &price,
+		Aliases:      []string{"yandex plus", "Яндекс Плюс", "ЯНДЕКС ПЛЮС", " "},
+	}
+
+	suite.mockRepo.On("ResolveService", ctx, "yandex plus").Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("ResolveService", ctx, "яндекс плюс").Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("CreateService", ctx, mock.AnythingOfType("*repository.Service")).Return(nil)
+
+	result, err := suite.service.CreateService(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, result.ID)
+	assert.Equal(suite.T(), "Yandex Plus", result.Name)
+	assert.Equal(suite.T(), "yandex plus", result.NameKey)
+	assert.Equal(suite.T(), "RUB", result.DefaultCurrency)
+	assert.Equal(suite.T(), []repository.ServiceAlias{{Alias: "Яндекс Плюс", AliasKey: "яндекс плюс"}}, result.Aliases)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CatalogServiceTestSuite) TestCreateService_AliasTaken() {
+	ctx := context.Background()
+	req := &CreateServiceRequest{
+		Name:    "Kinopoisk",
+		Aliases: []string{"Yandex Plus"},
+	}
+
+	suite.mockRepo.On("ResolveService", ctx, "kinopoisk").Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("ResolveService", ctx, "yandex plus").Return(&repository.Service{ID: 7, Name: "Yandex Plus"}, nil)
+
+	result, err := suite.service.CreateService(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrServiceAlreadyExists, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateService", mock.Anything, mock.Anything)
+}
+
+func (suite *CatalogServiceTestSuite) TestCreateService_InvalidCurrency() {
+	ctx := context.Background()
+	req := &CreateServiceRequest{
+		Name:            "Netflix",
+		DefaultCurrency: "XYZ",
+	}
+
+	result, err := suite.service.CreateService(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "validation failed")
+}
+
+func (suite *CatalogServiceTestSuite) TestGetService_NotFound() {
+	ctx := context.Background()
+
+	suite.mockRepo.On("GetService", ctx, 999).Return(nil, repository.ErrServiceNotFound)
+
+	result, err := suite.service.GetService(ctx, 999)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrServiceNotFound, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CatalogServiceTestSuite) TestGetService_InvalidID() {
+	result, err := suite.service.GetService(context.Background(), 0)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrInvalidServiceID, err)
+}
+
+func (suite *CatalogServiceTestSuite) TestUpdateService_KeepsOwnName() {
+	ctx := context.Background()
+	req := &UpdateServiceRequest{
+		Name:     "Netflix",
+		Category: "video",
+	}
+
+	suite.mockRepo.On("ResolveService", ctx, "netflix").Return(&repository.Service{ID: 3, Name: "netflix"}, nil)
+	suite.mockRepo.On("UpdateService", ctx, mock.AnythingOfType("*repository.Service")).Return(nil)
+
+	result, err := suite.service.UpdateService(ctx, 3, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, result.ID)
+	assert.Equal(suite.T(), "Netflix", result.Name)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CatalogServiceTestSuite) TestUpdateService_NotFound() {
+	ctx := context.Background()
+	req := &UpdateServiceRequest{
+		Name: "Netflix",
+	}
+
+	suite.mockRepo.On("ResolveService", ctx, "netflix").Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("UpdateService", ctx, mock.AnythingOfType("*repository.Service")).Return(repository.ErrServiceNotFound)
+
+	result, err := suite.service.UpdateService(ctx, 999, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrServiceNotFound, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CatalogServiceTestSuite) TestDeleteService_RepositoryError() {
+	ctx := context.Background()
+
+	suite.mockRepo.On("DeleteService", ctx, 1).Return(errors.New("database error"))
+
+	err := suite.service.DeleteService(ctx, 1)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), ErrInternalServer, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CatalogServiceTestSuite) TestResolveService_IgnoresCase() {
+	ctx := context.Background()
+	expected := &repository.Service{ID: 1, Name: "Yandex Plus"}
+
+	suite.mockRepo.On("ResolveService", ctx, "яндекс плюс").Return(expected, nil)
+
+	result, err := suite.service.ResolveService(ctx, " ЯНДЕКС  Плюс")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), expected, result)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func TestCatalogServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CatalogServiceTestSuite))
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// Proration modes supported by cost reports
+const (
+	ProrationChargeDate    = "charge_date"    // Full price for every charge that falls inside the period
+	ProrationWholeMonths   = "whole_months"   // Monthly equivalent price for every started month
+	ProrationDailyProrated = "daily_prorated" // Price of each billing period split by the days covered
+)
+
+// DefaultProration is used for cost reports that don't specify a proration mode
+const DefaultProration = ProrationChargeDate
+
+func prorationOrDefault(mode string) string {
+	if mode == "" {
+		return DefaultProration
+	}
+	return mode
+}
+
+// priceSchedule is the price history of one subscription ordered by effective date
+type priceSchedule []*repository.SubscriptionPrice
+
+// newPriceSchedule builds the schedule of a subscription, falling back to its current price
+// for subscriptions that have no price history
+func newPriceSchedule(sub *repository.Subscription, prices []*repository.SubscriptionPrice) priceSchedule {
+	if len(prices) == 0 {
+		return priceSchedule{{SubscriptionID: sub.ID, Price: sub.Price, EffectiveFrom: TruncateToDay(sub.StartDate)}}
+	}
+
+	schedule := make(priceSchedule, len(prices))
+	copy(schedule, prices)
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].EffectiveFrom.Before(schedule[j].EffectiveFrom)
+	})
+	return schedule
+}
+
+// indexAt returns the index of the price in force on date. Dates before the first
+// entry use the earliest known price.
+func (p priceSchedule) indexAt(date time.Time) int {
+	day := TruncateToDay(date)
+	idx := 0
+	for i, entry := range p {
+		if entry.EffectiveFrom.After(day) {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+// entryEnd returns the last day entry i is in force, or nil for the latest entry
+func (p priceSchedule) entryEnd(i int) *time.Time {
+	if i+1 >= len(p) {
+		return nil
+	}
+	end := TruncateToDay(p[i+1].EffectiveFrom).AddDate(0, 0, -1)
+	return &end
+}
+
+// costSegment is the part of a subscription's cost billed at one price of its schedule
+type costSegment struct {
+	Price        int
+	From         time.Time
+	To           time.Time
+	ChargesCount int
+	Amount       float64
+}
+
+// subscriptionCost is the cost of one subscription within a report period, in the subscription's currency
+type subscriptionCost struct {
+	ChargesCount      int // Paid charges only
+	MonthsCount       int
+	TrialChargesCount int
+	TrialMonthsCount  int
+	Amount            float64
+	Segments          []costSegment
+	Monthly           map[string]float64 // Unrounded amount per calendar month, keyed by MM-YYYY
+}
+
+// inTrial reports whether date falls into the subscription's free trial
+func inTrial(sub *repository.Subscription, date time.Time) bool {
+	return sub.TrialEndDate != nil && !TruncateToDay(date).After(TruncateToDay(*sub.TrialEndDate))
+}
+
+// calculateSubscriptionCost prices a subscription for the given period according to the proration mode,
+// splitting the cost into one segment per price of the schedule that was in force during the period.
+// Charges, months and days that fall into the free trial cost nothing.
+func calculateSubscriptionCost(sub *repository.Subscription, schedule priceSchedule, periodStart, periodEnd time.Time, mode string) subscriptionCost {
+	chargeDates := CalculateChargeDates(sub.StartDate, sub.EndDate, sub.BillingCycle, sub.BillingIntervalMonths, periodStart, periodEnd)
+	cost := subscriptionCost{
+		MonthsCount: CalculateSubscriptionMonthsInPeriod(&sub.StartDate, sub.EndDate, periodStart, periodEnd),
+		Monthly:     make(map[string]float64),
+	}
+
+	activeStart, activeEnd, ok := activeWindow(sub, periodStart, periodEnd)
+	if !ok {
+		return cost
+	}
+
+	// One segment per schedule entry overlapping the active window
+	segments := make(map[int]*costSegment)
+	var order []int
+	for i, entry := range schedule {
+		// Days before the first entry are billed at the earliest known price
+		from := activeStart
+		if i > 0 {
+			from = maxTime(TruncateToDay(entry.EffectiveFrom), activeStart)
+		}
+		to := activeEnd
+		if end := schedule.entryEnd(i); end != nil && end.Before(to) {
+			to = *end
+		}
+		if from.After(to) {
+			continue
+		}
+		segments[i] = &costSegment{Price: entry.Price, From: from, To: to}
+		order = append(order, i)
+	}
+
+	for _, chargeDate := range chargeDates {
+		if inTrial(sub, chargeDate) {
+			cost.TrialChargesCount++
+			continue
+		}
+		cost.ChargesCount++
+		if segment, ok := segments[schedule.indexAt(chargeDate)]; ok {
+			segment.ChargesCount++
+		}
+	}
+
+	for m := 0; m < cost.MonthsCount; m++ {
+		if inTrial(sub, AddMonthsClamped(activeStart, m)) {
+			cost.TrialMonthsCount++
+		}
+	}
+
+	switch prorationOrDefault(mode) {
+	case ProrationWholeMonths:
+		for m := 0; m < cost.MonthsCount; m++ {
+			monthStart := AddMonthsClamped(activeStart, m)
+			if inTrial(sub, monthStart) {
+				continue
+			}
+			if segment, ok := segments[schedule.indexAt(monthStart)]; ok {
+				amount := monthlyEquivalentPrice(segment.Price, sub.BillingCycle, sub.BillingIntervalMonths)
+				segment.Amount += amount
+				cost.Monthly[FormatMonthYear(monthStart)] += amount
+			}
+		}
+	case ProrationDailyProrated:
+		for _, segment := range segments {
+			from := segment.From
+			if sub.TrialEndDate != nil {
+				from = maxTime(from, TruncateToDay(*sub.TrialEndDate).AddDate(0, 0, 1))
+			}
+			// Split by calendar month so that the monthly amounts add up to the segment's amount
+			for monthStart := GetFirstDayOfMonth(from); !monthStart.After(segment.To); monthStart = monthStart.AddDate(0, 1, 0) {
+				amount := proratedAmount(sub, segment.Price, maxTime(monthStart, from), minTime(TruncateToDay(GetLastDayOfMonth(monthStart)), segment.To))
+				segment.Amount += amount
+				cost.Monthly[FormatMonthYear(monthStart)] += amount
+			}
+		}
+	default:
+		for _, chargeDate := range chargeDates {
+			if inTrial(sub, chargeDate) {
+				continue
+			}
+			if segment, ok := segments[schedule.indexAt(chargeDate)]; ok {
+				segment.Amount += float64(segment.Price)
+				cost.Monthly[FormatMonthYear(chargeDate)] += float64(segment.Price)
+			}
+		}
+	}
+
+	for _, i := range order {
+		segment := segments[i]
+		cost.Amount += segment.Amount
+		segment.Amount = RoundMoney(segment.Amount)
+		cost.Segments = append(cost.Segments, *segment)
+	}
+
+	cost.Amount = RoundMoney(cost.Amount)
+	return cost
+}
+
+// activeWindow returns the days of the report period during which the subscription is active
+func activeWindow(sub *repository.Subscription, periodStart, periodEnd time.Time) (time.Time, time.Time, bool) {
+	activeStart := TruncateToDay(periodStart)
+	if sub.StartDate.After(activeStart) {
+		activeStart = TruncateToDay(sub.StartDate)
+	}
+
+	activeEnd := TruncateToDay(periodEnd)
+	if sub.EndDate != nil && sub.EndDate.Before(periodEnd) {
+		activeEnd = TruncateToDay(*sub.EndDate)
+	}
+
+	return activeStart, activeEnd, !activeStart.After(activeEnd)
+}
+
+// proratedAmount sums, for every billing period overlapping the given days, the share of the
+// price proportional to the number of days of that billing period covered
+func proratedAmount(sub *repository.Subscription, price int, activeStart, activeEnd time.Time) float64 {
+	var amount float64
+	for n := firstChargeIndex(sub.StartDate, sub.BillingCycle, sub.BillingIntervalMonths, activeStart); ; n++ {
+		cycleStart := TruncateToDay(nthChargeDate(sub.StartDate, sub.BillingCycle, sub.BillingIntervalMonths, n))
+		if cycleStart.After(activeEnd) {
+			break
+		}
+		cycleEnd := TruncateToDay(nthChargeDate(sub.StartDate, sub.BillingCycle, sub.BillingIntervalMonths, n+1)).AddDate(0, 0, -1)
+
+		coveredDays := DaysInPeriod(maxTime(cycleStart, activeStart), minTime(cycleEnd, activeEnd))
+		if coveredDays == 0 {
+			continue
+		}
+		amount += float64(price) * float64(coveredDays) / float64(DaysInPeriod(cycleStart, cycleEnd))
+	}
+
+	return amount
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
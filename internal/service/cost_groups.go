@@ -0,0 +1,157 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Groupings supported by cost reports
+const (
+	GroupByService      = "service"       // One subtotal per service
+	GroupByMonth        = "month"         // One subtotal per calendar month
+	GroupByMonthService = "month,service" // One subtotal per calendar month and service
+)
+
+// ParseGroupBy normalizes the group_by parameter of a cost report. The pair may be given
+// in either order; an empty value means the report is not grouped.
+func ParseGroupBy(groupBy string) (string, error) {
+	var hasService, hasMonth bool
+	for _, part := range strings.Split(groupBy, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "":
+			continue
+		case GroupByService:
+			hasService = true
+		case GroupByMonth:
+			hasMonth = true
+		default:
+			return "", ErrInvalidGroupBy
+		}
+	}
+
+	switch {
+	case hasMonth && hasService:
+		return GroupByMonthService, nil
+	case hasMonth:
+		return GroupByMonth, nil
+	case hasService:
+		return GroupByService, nil
+	default:
+		return "", nil
+	}
+}
+
+// groupedCost is one breakdown line of a cost report as seen by the grouping, in the report currency
+type groupedCost struct {
+	ServiceName string
+	TotalCost   float64
+	Monthly     map[string]float64 // Keyed by MM-YYYY
+}
+
+// reportMonths returns the first day of every calendar month touched by the period
+func reportMonths(periodStart, periodEnd time.Time) []time.Time {
+	var months []time.Time
+	for month := GetFirstDayOfMonth(periodStart); !month.After(periodEnd); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	return months
+}
+
+// costTimeSeries sums the spend of every month of the period. Months without spend are reported as zero.
+func costTimeSeries(months []time.Time, costs []groupedCost) []CostPoint {
+	series := make([]CostPoint, len(months))
+	for i, month := range months {
+		var amount float64
+		for _, cost := range costs {
+			amount += cost.Monthly[FormatMonthYear(month)]
+		}
+		series[i] = CostPoint{
+			Month:     FormatMonthYear(month),
+			TotalCost: RoundMoney(amount),
+		}
+	}
+	return series
+}
+
+// groupCosts builds the subtotals of a cost report. Services are matched ignoring case and extra spaces,
+// month groups are zero-filled so that every month of the period is present.
+func groupCosts(groupBy string, months []time.Time, costs []groupedCost) []CostGroup {
+	// Distinct services keyed by normalized name, ordered by name
+	var serviceKeys []string
+	serviceNames := make(map[string]string)
+	for _, cost := range costs {
+		key := NormalizeServiceName(cost.ServiceName)
+		if _, ok := serviceNames[key]; !ok {
+			serviceNames[key] = cost.ServiceName
+			serviceKeys = append(serviceKeys, key)
+		}
+	}
+	sort.Slice(serviceKeys, func(i, j int) bool {
+		return serviceNames[serviceKeys[i]] < serviceNames[serviceKeys[j]]
+	})
+
+	switch groupBy {
+	case GroupByService:
+		groups := make([]CostGroup, 0, len(serviceKeys))
+		for _, key := range serviceKeys {
+			group := CostGroup{ServiceName: serviceNames[key]}
+			var amount float64
+			for _, cost := range costs {
+				if NormalizeServiceName(cost.ServiceName) == key {
+					group.SubscriptionsCount++
+					amount += cost.TotalCost
+				}
+			}
+			group.TotalCost = RoundMoney(amount)
+			groups = append(groups, group)
+		}
+		// Largest spend first
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].TotalCost > groups[j].TotalCost
+		})
+		return groups
+
+	case GroupByMonth:
+		groups := make([]CostGroup, len(months))
+		for i, month := range months {
+			groups[i] = monthGroup(month, "", costs)
+		}
+		return groups
+
+	case GroupByMonthService:
+		groups := make([]CostGroup, 0, len(months)*len(serviceKeys))
+		for _, month := range months {
+			for _, key := range serviceKeys {
+				groups = append(groups, monthGroup(month, serviceNames[key], costs))
+			}
+		}
+		return groups
+
+	default:
+		return nil
+	}
+}
+
+// monthGroup sums the spend of one calendar month, optionally limited to one service.
+// SubscriptionsCount counts the subscriptions billed in that month.
+func monthGroup(month time.Time, serviceName string, costs []groupedCost) CostGroup {
+	group := CostGroup{
+		Month:       FormatMonthYear(month),
+		ServiceName: serviceName,
+	}
+
+	var amount float64
+	for _, cost := range costs {
+		if serviceName != "" && NormalizeServiceName(cost.ServiceName) != NormalizeServiceName(serviceName) {
+			continue
+		}
+		if monthly, ok := cost.Monthly[group.Month]; ok {
+			group.SubscriptionsCount++
+			amount += monthly
+		}
+	}
+	group.TotalCost = RoundMoney(amount)
+
+	return group
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// NormalizeCurrency trims and upper-cases an ISO 4217 currency code
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return NormalizeCurrency(currency)
+}
+
+// RoundMoney rounds an amount to two decimal places
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// rateCache memoizes exchange rates to a single target currency for the duration of one calculation
+type rateCache struct {
+	repo   repository.SubscriptionsRepository
+	target string
+	rates  map[string]float64
+}
+
+func newRateCache(repo repository.SubscriptionsRepository, target string) *rateCache {
+	return &rateCache{
+		repo:   repo,
+		target: target,
+		rates:  map[string]float64{target: 1},
+	}
+}
+
+// rate returns how many units of the target currency one unit of currency is worth. Without a rate
+// for that direction the inverse of the opposite one is used.
+func (c *rateCache) rate(ctx context.Context, currency string) (float64, error) {
+	if rate, ok := c.rates[currency]; ok {
+		return rate, nil
+	}
+
+	rate, err := c.lookup(ctx, currency, c.target)
+	if errors.Is(err, repository.ErrExchangeRateNotFound) {
+		var inverse float64
+		inverse, err = c.lookup(ctx, c.target, currency)
+		rate = 1 / inverse
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrExchangeRateNotFound) {
+			return 0, fmt.Errorf("%w: %s to %s", ErrExchangeRateNotFound, currency, c.target)
+		}
+		return 0, err
+	}
+
+	c.rates[currency] = rate
+	return rate, nil
+}
+
+func (c *rateCache) lookup(ctx context.Context, base, quote string) (float64, error) {
+	exchangeRate, err := c.repo.GetExchangeRate(ctx, base, quote)
+	if err != nil {
+		return 0, err
+	}
+	return exchangeRate.Rate, nil
+}
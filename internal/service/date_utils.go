@@ -32,12 +32,47 @@ func ParseMonthYear(dateStr string) (time.Time, error) {
 	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), nil
 }
 
+// isoDateLayout is the full date format accepted alongside MM-YYYY
+const isoDateLayout = "2006-01-02"
+
+// ParseStartDate parses a date in "YYYY-MM-DD" or legacy "MM-YYYY" format.
+// For MM-YYYY the first day of the month is returned.
+func ParseStartDate(dateStr string) (time.Time, error) {
+	if t, ok := parseISODate(dateStr); ok {
+		return t, nil
+	}
+	return ParseMonthYear(dateStr)
+}
+
+// ParseEndDate parses a date in "YYYY-MM-DD" or legacy "MM-YYYY" format and returns the last moment
+// of that day, or of the last day of the month for MM-YYYY, so that the end date is inclusive
+func ParseEndDate(dateStr string) (time.Time, error) {
+	if t, ok := parseISODate(dateStr); ok {
+		return EndOfDay(t), nil
+	}
+
+	t, err := ParseMonthYear(dateStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return GetLastDayOfMonth(t), nil
+}
+
+func parseISODate(dateStr string) (time.Time, bool) {
+	t, err := time.Parse(isoDateLayout, dateStr)
+	if err != nil || t.Year() < 1900 || t.Year() > 3000 {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
 // FormatMonthYear formats time.Time to "MM-YYYY" string
 func FormatMonthYear(t time.Time) string {
 	return fmt.Sprintf("%02d-%04d", t.Month(), t.Year())
 }
 
-// CalculateMonthsInPeriod calculates the number of full months between two dates
+// CalculateMonthsInPeriod calculates the number of months between two dates, counting from the day
+// of month of startDate. A partially elapsed last month counts as a whole one.
 func CalculateMonthsInPeriod(startDate, endDate time.Time) int {
 	if endDate.Before(startDate) {
 		return 0
@@ -48,7 +83,8 @@ func CalculateMonthsInPeriod(startDate, endDate time.Time) int {
 
 	totalMonths := years*12 + months
 
-	if endDate.Day() >= startDate.Day() {
+	// The anchor day is clamped like charge dates, so that a month anchored on Jan 31 starts on Feb 28
+	if !endDate.Before(AddMonthsClamped(startDate, totalMonths)) {
 		totalMonths++
 	} else if totalMonths > 0 {
 		totalMonths++
@@ -81,8 +117,74 @@ func CalculateSubscriptionMonthsInPeriod(subStart, subEnd *time.Time, periodStar
 	return CalculateMonthsInPeriod(actualStart, actualEnd)
 }
 
+// GetFirstDayOfMonth returns midnight of the first day of the month for given time
+func GetFirstDayOfMonth(t time.Time) time.Time {
+	year, month, _ := t.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+}
+
 // GetLastDayOfMonth returns the last day of the month for given time
 func GetLastDayOfMonth(t time.Time) time.Time {
 	year, month, _ := t.Date()
 	return time.Date(year, month+1, 0, 23, 59, 59, 999999999, t.Location())
 }
+
+// EndOfDay returns the last moment of the day of t
+func EndOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 23, 59, 59, 999999999, t.Location())
+}
+
+// TruncateToDay returns midnight of the day of t
+func TruncateToDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// DaysInPeriod returns the number of calendar days between two dates, both inclusive
+func DaysInPeriod(startDate, endDate time.Time) int {
+	start := TruncateToDay(startDate)
+	end := TruncateToDay(endDate)
+	if end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start).Round(24*time.Hour).Hours()/24) + 1
+}
+
+// DefaultDayWindow is used when no window is given to ParseDayWindow
+const DefaultDayWindow = 30
+
+// maxDayWindow caps look-ahead windows at one year
+const maxDayWindow = 366
+
+// ParseDayWindow parses a look-ahead window such as "30d", "2w" or "30" into a number of days
+func ParseDayWindow(window string) (int, error) {
+	window = strings.TrimSpace(strings.ToLower(window))
+	if window == "" {
+		return DefaultDayWindow, nil
+	}
+
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(window, "d"):
+		window = strings.TrimSuffix(window, "d")
+	case strings.HasSuffix(window, "w"):
+		window = strings.TrimSuffix(window, "w")
+		multiplier = 7
+	}
+
+	n, err := strconv.Atoi(window)
+	if err != nil || n < 0 || n*multiplier > maxDayWindow {
+		return 0, ErrInvalidDayWindow
+	}
+
+	return n * multiplier, nil
+}
+
+// formatOptionalDate formats a nullable date as YYYY-MM-DD, or returns an empty string
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(isoDateLayout)
+}
@@ -154,6 +154,18 @@ func TestCalculateMonthsInPeriod(t *testing.T) {
 			name:      "Partial months",
 			startDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
 			endDate:   time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+			expected:  3,
+		},
+		{
+			name:      "Partially elapsed last month",
+			startDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC),
+			expected:  2,
+		},
+		{
+			name:      "Anchor day clamped to the end of a shorter month",
+			startDate: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
 			expected:  2,
 		},
 		{
@@ -172,7 +184,156 @@ func TestCalculateMonthsInPeriod(t *testing.T) {
 	}
 }
 
+func TestCalculateMonthsInPeriod_MatchesMonthlyCharges(t *testing.T) {
+	// whole_months bills as many months as charge_date bills charges of a monthly subscription
+	startDate := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 2, 28, 23, 59, 59, 999999999, time.UTC)
+
+	charges := CalculateChargeDates(startDate, nil, BillingCycleMonthly, nil, startDate, endDate)
+
+	assert.Len(t, charges, 2)
+	assert.Equal(t, len(charges), CalculateMonthsInPeriod(startDate, endDate))
+}
+
+func TestParseStartDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{
+			name:     "ISO date",
+			input:    "2025-01-20",
+			expected: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Legacy month",
+			input:    "01-2025",
+			expected: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseStartDate(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseEndDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{
+			name:     "ISO date",
+			input:    "2025-02-10",
+			expected: time.Date(2025, 2, 10, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:     "Legacy month",
+			input:    "02-2025",
+			expected: time.Date(2025, 2, 28, 23, 59, 59, 999999999, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseEndDate(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseDate_InvalidFormat(t *testing.T) {
+	inputs := []string{"", "2025-13-01", "2025-02-30", "20-01-2025", "2025/01/20", "0025-01-01"}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseStartDate(input)
+			assert.Equal(t, ErrInvalidDateFormat, err)
+
+			_, err = ParseEndDate(input)
+			assert.Equal(t, ErrInvalidDateFormat, err)
+		})
+	}
+}
+
+func TestDaysInPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate time.Time
+		endDate   time.Time
+		expected  int
+	}{
+		{
+			name:      "Same day",
+			startDate: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2025, 1, 20, 23, 59, 59, 0, time.UTC),
+			expected:  1,
+		},
+		{
+			name:      "Rest of January",
+			startDate: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC),
+			expected:  12,
+		},
+		{
+			name:      "Leap year",
+			startDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			expected:  366,
+		},
+		{
+			name:      "End before start",
+			startDate: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC),
+			expected:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DaysInPeriod(tt.startDate, tt.endDate))
+		})
+	}
+}
+
+func TestParseDayWindow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{input: "", expected: DefaultDayWindow},
+		{input: "30d", expected: 30},
+		{input: "2w", expected: 14},
+		{input: "7", expected: 7},
+		{input: "0d", expected: 0},
+		{input: "-1d", wantErr: true},
+		{input: "60w", wantErr: true},
+		{input: "1m", wantErr: true},
+		{input: "d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseDayWindow(tt.input)
+			if tt.wantErr {
+				assert.Equal(t, ErrInvalidDayWindow, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 // Helper function to create a time pointer
 func timePtr(t time.Time) *time.Time {
 	return &t
-}
\ No newline at end of file
+}
@@ -0,0 +1,116 @@
+package service
+
+import "errors"
+
+// ErrorCode is a stable machine-readable identifier of an error. Unlike error messages, codes
+// never change, so clients may match on them.
+type ErrorCode string
+
+const (
+	// Subscription errors
+	CodeSubscriptionNotFound  ErrorCode = "SUBSCRIPTION_NOT_FOUND"
+	CodeInvalidSubscriptionID ErrorCode = "INVALID_SUBSCRIPTION_ID"
+	CodePreconditionFailed    ErrorCode = "PRECONDITION_FAILED"
+
+	// Validation errors
+	CodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	CodeInvalidUserID      ErrorCode = "INVALID_USER_ID"
+	CodeInvalidServiceName ErrorCode = "INVALID_SERVICE_NAME"
+	CodeInvalidPrice       ErrorCode = "INVALID_PRICE"
+	CodeInvalidDateFormat  ErrorCode = "INVALID_DATE_FORMAT"
+	CodeEndDateBeforeStart ErrorCode = "END_DATE_BEFORE_START"
+	CodeInvalidDateRange   ErrorCode = "INVALID_DATE_RANGE"
+	CodeInvalidDayWindow   ErrorCode = "INVALID_WINDOW"
+	CodeInvalidGroupBy     ErrorCode = "INVALID_GROUP_BY"
+	CodeInvalidPriceRange  ErrorCode = "INVALID_PRICE_RANGE"
+	CodeInvalidCursor      ErrorCode = "INVALID_CURSOR"
+
+	// Trial errors
+	CodeTrialEndBeforeStart ErrorCode = "TRIAL_END_BEFORE_START"
+
+	// Services catalog errors
+	CodeServiceNotFound      ErrorCode = "SERVICE_NOT_FOUND"
+	CodeServiceAlreadyExists ErrorCode = "SERVICE_ALREADY_EXISTS"
+	CodeInvalidServiceID     ErrorCode = "INVALID_SERVICE_ID"
+
+	// Currency errors
+	CodeExchangeRateNotFound ErrorCode = "EXCHANGE_RATE_NOT_FOUND"
+
+	// Idempotency key errors
+	CodeInvalidIdempotencyKey    ErrorCode = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused     ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress ErrorCode = "IDEMPOTENCY_KEY_IN_PROGRESS"
+
+	// Logging errors
+	CodeInvalidLogLevel ErrorCode = "INVALID_LOG_LEVEL"
+
+	// Import errors
+	CodeInvalidImport  ErrorCode = "INVALID_IMPORT"
+	CodeImportTooLarge ErrorCode = "IMPORT_TOO_LARGE"
+
+	// Storage errors
+	CodeConflict            ErrorCode = "CONFLICT"
+	CodeConstraintViolation ErrorCode = "CONSTRAINT_VIOLATION"
+	CodeStorageUnavailable  ErrorCode = "STORAGE_UNAVAILABLE"
+	CodeStorageTimeout      ErrorCode = "STORAGE_TIMEOUT"
+
+	// General errors
+	CodeInternal ErrorCode = "INTERNAL_ERROR"
+
+	// Errors reported by the HTTP layer
+	CodeUnauthorized  ErrorCode = "UNAUTHORIZED"
+	CodeForbidden     ErrorCode = "FORBIDDEN"
+	CodeRouteNotFound ErrorCode = "ROUTE_NOT_FOUND"
+	// CodeUnsupportedMediaType is returned for a request body of a media type the endpoint doesn't accept
+	CodeUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	// CodeRequestTooLarge is returned for a request body larger than the endpoint accepts
+	CodeRequestTooLarge ErrorCode = "REQUEST_TOO_LARGE"
+)
+
+// errorCodes lists the codes of the service errors
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrSubscriptionNotFound, CodeSubscriptionNotFound},
+	{ErrInvalidSubscriptionID, CodeInvalidSubscriptionID},
+	{ErrPreconditionFailed, CodePreconditionFailed},
+	{ErrValidation, CodeValidationFailed},
+	{ErrInvalidUserID, CodeInvalidUserID},
+	{ErrInvalidServiceName, CodeInvalidServiceName},
+	{ErrInvalidPrice, CodeInvalidPrice},
+	{ErrInvalidDateFormat, CodeInvalidDateFormat},
+	{ErrEndDateBeforeStart, CodeEndDateBeforeStart},
+	{ErrInvalidDateRange, CodeInvalidDateRange},
+	{ErrInvalidDayWindow, CodeInvalidDayWindow},
+	{ErrInvalidGroupBy, CodeInvalidGroupBy},
+	{ErrInvalidPriceRange, CodeInvalidPriceRange},
+	{ErrInvalidCursor, CodeInvalidCursor},
+	{ErrTrialEndBeforeStart, CodeTrialEndBeforeStart},
+	{ErrServiceNotFound, CodeServiceNotFound},
+	{ErrServiceAlreadyExists, CodeServiceAlreadyExists},
+	{ErrInvalidServiceID, CodeInvalidServiceID},
+	{ErrExchangeRateNotFound, CodeExchangeRateNotFound},
+	{ErrInvalidIdempotencyKey, CodeInvalidIdempotencyKey},
+	{ErrIdempotencyKeyReused, CodeIdempotencyKeyReused},
+	{ErrIdempotencyKeyInProgress, CodeIdempotencyKeyInProgress},
+	{ErrInvalidLogLevel, CodeInvalidLogLevel},
+	{ErrInvalidImport, CodeInvalidImport},
+	{ErrImportTooLarge, CodeImportTooLarge},
+	{ErrForeignImportRow, CodeForbidden},
+	{ErrConflict, CodeConflict},
+	{ErrConstraintViolation, CodeConstraintViolation},
+	{ErrUnavailable, CodeStorageUnavailable},
+	{ErrTimeout, CodeStorageTimeout},
+	{ErrInternalServer, CodeInternal},
+}
+
+// ErrorCodeOf returns the code of a service error, or CodeInternal if the error is unknown
+func ErrorCodeOf(err error) ErrorCode {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return CodeInternal
+}
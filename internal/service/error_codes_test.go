@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorCode
+	}{
+		{
+			name:     "sentinel error",
+			err:      ErrSubscriptionNotFound,
+			expected: CodeSubscriptionNotFound,
+		},
+		{
+			name:     "wrapped error",
+			err:      fmt.Errorf("%w: %w", ErrValidation, errors.New("user_id: is required")),
+			expected: CodeValidationFailed,
+		},
+		{
+			name:     "repository error",
+			err:      repositoryError(fmt.Errorf("query failed: %w", repository.ErrUnavailable), ErrSubscriptionNotFound),
+			expected: CodeStorageUnavailable,
+		},
+		{
+			name:     "unknown error",
+			err:      errors.New("boom"),
+			expected: CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ErrorCodeOf(tt.err))
+		})
+	}
+}
+
+func TestErrorCodes_Unique(t *testing.T) {
+	seen := make(map[ErrorCode]bool, len(errorCodes))
+	for _, c := range errorCodes {
+		assert.False(t, seen[c.code], "duplicate code %s", c.code)
+		seen[c.code] = true
+	}
+}
@@ -1,22 +1,83 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
 
 var (
 	// Subscription related errors
-	ErrSubscriptionNotFound   = errors.New("subscription not found")
-	ErrSubscriptionExists     = errors.New("subscription already exists")
-	ErrInvalidSubscriptionID  = errors.New("invalid subscription ID")
-	
+	ErrSubscriptionNotFound  = errors.New("subscription not found")
+	ErrSubscriptionExists    = errors.New("subscription already exists")
+	ErrInvalidSubscriptionID = errors.New("invalid subscription ID")
+	ErrPreconditionFailed    = errors.New("subscription was changed since it was read")
+
 	// Validation errors
-	ErrInvalidUserID         = errors.New("invalid user ID format")
-	ErrInvalidServiceName    = errors.New("service name cannot be empty")
-	ErrInvalidPrice          = errors.New("price must be greater than or equal to zero")
-	ErrInvalidDateFormat     = errors.New("invalid date format, expected MM-YYYY")
-	ErrEndDateBeforeStart    = errors.New("end date must be after start date")
-	ErrInvalidDateRange      = errors.New("invalid date range")
-	
+	ErrValidation         = errors.New("validation failed")
+	ErrInvalidUserID      = errors.New("invalid user ID format")
+	ErrInvalidServiceName = errors.New("service name cannot be empty")
+	ErrInvalidPrice       = errors.New("price must be greater than or equal to zero")
+	ErrInvalidDateFormat  = errors.New("invalid date format, expected YYYY-MM-DD or MM-YYYY")
+	ErrEndDateBeforeStart = errors.New("end date must be after start date")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+	ErrInvalidDayWindow   = errors.New("invalid window, expected a number of days such as 30d or weeks such as 2w")
+	ErrInvalidGroupBy     = errors.New("invalid group_by, expected service, month or month,service")
+	ErrInvalidPriceRange  = errors.New("min price must not be greater than max price")
+	ErrInvalidCursor      = errors.New("invalid cursor, it must be the next_cursor of a list with the same sorting")
+
+	// Trial errors
+	ErrTrialEndBeforeStart = errors.New("trial end date must not be before start date")
+
+	// Services catalog errors
+	ErrServiceNotFound      = errors.New("service not found")
+	ErrServiceAlreadyExists = errors.New("service with this name or alias already exists")
+	ErrInvalidServiceID     = errors.New("invalid service ID")
+
+	// Currency errors
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+	// Idempotency key errors
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be 1 to 255 printable ASCII characters")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+
+	// Logging errors
+	ErrInvalidLogLevel = errors.New("invalid log level, expected debug, info, warn or error")
+
+	// Import errors
+	ErrInvalidImport    = errors.New("invalid import")
+	ErrImportTooLarge   = errors.New("import is too large")
+	ErrForeignImportRow = errors.New("user_id does not match the authenticated user")
+
+	// Storage errors
+	ErrConflict            = errors.New("the change conflicts with a concurrent change")
+	ErrConstraintViolation = errors.New("the change violates a data constraint")
+	ErrUnavailable         = errors.New("storage is temporarily unavailable")
+	ErrTimeout             = errors.New("storage did not respond in time")
+
 	// General errors
-	ErrEmptyResult          = errors.New("no subscriptions found")
-	ErrInternalServer       = errors.New("internal server error")
-)
\ No newline at end of file
+	ErrEmptyResult    = errors.New("no subscriptions found")
+	ErrInternalServer = errors.New("internal server error")
+)
+
+// repositoryError translates a repository failure into a service error. A missing row becomes
+// notFound when it is set, and failures without a known kind become ErrInternalServer.
+func repositoryError(err error, notFound error) error {
+	switch {
+	case notFound != nil && errors.Is(err, repository.ErrNotFound):
+		return notFound
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return ErrPreconditionFailed
+	case errors.Is(err, repository.ErrConflict):
+		return ErrConflict
+	case errors.Is(err, repository.ErrConstraintViolation):
+		return ErrConstraintViolation
+	case errors.Is(err, repository.ErrTimeout):
+		return ErrTimeout
+	case errors.Is(err, repository.ErrUnavailable):
+		return ErrUnavailable
+	default:
+		return ErrInternalServer
+	}
+}
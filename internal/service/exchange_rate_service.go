@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+type exchangeRateService struct {
+	repo      repository.ExchangeRatesRepository
+	validator *validator.Validate
+}
+
+// NewExchangeRateService creates a new instance of exchange rate service
+func NewExchangeRateService(repo repository.ExchangeRatesRepository) ExchangeRateService {
+	return &exchangeRateService{
+		repo:      repo,
+		validator: validation.New(),
+	}
+}
+
+// ListExchangeRates retrieves all stored rates
+func (s *exchangeRateService) ListExchangeRates(ctx context.Context) ([]*repository.ExchangeRate, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("listing exchange rates")
+
+	rates, err := s.repo.ListExchangeRates(ctx)
+	if err != nil {
+		log.Error("failed to list exchange rates from repository",
+			logger.Error(err))
+		return nil, repositoryError(err, nil)
+	}
+
+	return rates, nil
+}
+
+// SetExchangeRate validates the currency pair and stores its rate
+func (s *exchangeRateService) SetExchangeRate(ctx context.Context, req *SetExchangeRateRequest) (*repository.ExchangeRate, error) {
+	log := logger.FromContext(ctx)
+	log.Info("setting exchange rate",
+		logger.String("base_currency", req.BaseCurrency),
+		logger.String("quote_currency", req.QuoteCurrency))
+
+	req.BaseCurrency = NormalizeCurrency(req.BaseCurrency)
+	req.QuoteCurrency = NormalizeCurrency(req.QuoteCurrency)
+
+	// Validate request
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("exchange rate validation failed",
+			logger.Error(err),
+			logger.String("base_currency", req.BaseCurrency),
+			logger.String("quote_currency", req.QuoteCurrency))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	rate := &repository.ExchangeRate{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+	}
+	if err := s.repo.SetExchangeRate(ctx, rate); err != nil {
+		log.Error("failed to set exchange rate in repository",
+			logger.Error(err),
+			logger.String("base_currency", req.BaseCurrency),
+			logger.String("quote_currency", req.QuoteCurrency))
+		return nil, repositoryError(err, nil)
+	}
+
+	log.Info("exchange rate set successfully",
+		logger.String("base_currency", rate.BaseCurrency),
+		logger.String("quote_currency", rate.QuoteCurrency))
+
+	return rate, nil
+}
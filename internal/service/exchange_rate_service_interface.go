@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// ExchangeRateService defines the interface for maintaining the exchange rates of cost reports
+type ExchangeRateService interface {
+	ListExchangeRates(ctx context.Context) ([]*repository.ExchangeRate, error)
+
+	// SetExchangeRate inserts or replaces the rate of a currency pair. Reports converting the other
+	// way use its inverse unless that direction has a rate of its own.
+	SetExchangeRate(ctx context.Context, req *SetExchangeRateRequest) (*repository.ExchangeRate, error)
+}
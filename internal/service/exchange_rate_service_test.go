@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// MockExchangeRatesRepository is a mock implementation of ExchangeRatesRepository
+type MockExchangeRatesRepository struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRatesRepository) ListExchangeRates(ctx context.Context) ([]*repository.ExchangeRate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRatesRepository) SetExchangeRate(ctx context.Context, rate *repository.ExchangeRate) error {
+	args := m.Called(ctx, rate)
+	return args.Error(0)
+}
+
+type ExchangeRateServiceTestSuite struct {
+	suite.Suite
+	mockRepo *MockExchangeRatesRepository
+	service  ExchangeRateService
+}
+
+func (suite *ExchangeRateServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(MockExchangeRatesRepository)
+	suite.service = NewExchangeRateService(suite.mockRepo)
+}
+
+func (suite *ExchangeRateServiceTestSuite) TestListExchangeRates_Success() {
+	ctx := context.Background()
+	rates := []*repository.ExchangeRate{{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 80.5}}
+
+	suite.mockRepo.On("ListExchangeRates", ctx).Return(rates, nil)
+
+	result, err := suite.service.ListExchangeRates(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), rates, result)
+}
+
+func (suite *ExchangeRateServiceTestSuite) TestSetExchangeRate_Success() {
+	ctx := context.Background()
+	updatedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.mockRepo.On("SetExchangeRate", ctx, &repository.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 80.5}).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*repository.ExchangeRate).UpdatedAt = updatedAt
+		}).
+		Return(nil)
+
+	result, err := suite.service.SetExchangeRate(ctx, &SetExchangeRateRequest{BaseCurrency: " usd", QuoteCurrency: "rub", Rate: 80.5})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "USD", result.BaseCurrency)
+	assert.Equal(suite.T(), "RUB", result.QuoteCurrency)
+	assert.Equal(suite.T(), updatedAt, result.UpdatedAt)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ExchangeRateServiceTestSuite) TestSetExchangeRate_ValidationError() {
+	result, err := suite.service.SetExchangeRate(context.Background(), &SetExchangeRateRequest{BaseCurrency: "USD", QuoteCurrency: "usd", Rate: 0})
+
+	assert.Nil(suite.T(), result)
+	require.ErrorIs(suite.T(), err, ErrValidation)
+	fields := validation.Fields(err)
+	require.Len(suite.T(), fields, 2)
+	assert.Equal(suite.T(), "quote_currency", fields[0].Field)
+	assert.Equal(suite.T(), "nefield", fields[0].Code)
+	assert.Equal(suite.T(), "rate", fields[1].Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "SetExchangeRate", mock.Anything, mock.Anything)
+}
+
+func (suite *ExchangeRateServiceTestSuite) TestSetExchangeRate_DatabaseUnavailable() {
+	ctx := context.Background()
+
+	suite.mockRepo.On("SetExchangeRate", ctx, mock.AnythingOfType("*repository.ExchangeRate")).Return(repository.ErrUnavailable)
+
+	result, err := suite.service.SetExchangeRate(ctx, &SetExchangeRateRequest{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 80.5})
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrUnavailable, err)
+}
+
+func TestExchangeRateServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ExchangeRateServiceTestSuite))
+}
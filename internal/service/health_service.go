@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// Component statuses of the readiness report
+const (
+	ComponentUp   = "up"
+	ComponentDown = "down"
+)
+
+type healthService struct {
+	repo             repository.HealthRepository
+	migrationVersion uint
+	timeout          time.Duration
+}
+
+// NewHealthService creates a new instance of health service. migrationVersion is the schema
+// version the database must be at, timeout limits the duration of a readiness check.
+func NewHealthService(repo repository.HealthRepository, migrationVersion uint, timeout time.Duration) HealthService {
+	return &healthService{
+		repo:             repo,
+		migrationVersion: migrationVersion,
+		timeout:          timeout,
+	}
+}
+
+// Readiness pings the database and checks the schema version within the timeout
+func (s *healthService) Readiness(ctx context.Context) *ReadinessReport {
+	log := logger.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	report := &ReadinessReport{
+		Database: s.checkDatabase(ctx),
+		Build:    buildinfo.Get(),
+	}
+	if report.Database.Status == ComponentUp {
+		report.Migrations = s.checkMigrations(ctx)
+	} else {
+		report.Migrations = MigrationsStatus{
+			Status:          ComponentDown,
+			Error:           "database is unreachable",
+			ExpectedVersion: s.migrationVersion,
+		}
+	}
+	report.Ready = report.Database.Status == ComponentUp && report.Migrations.Status == ComponentUp
+
+	if !report.Ready {
+		log.Warn("service is not ready",
+			logger.String("database", report.Database.Status),
+			logger.String("migrations", report.Migrations.Status))
+	}
+
+	return report
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) DatabaseStatus {
+	start := time.Now()
+	err := s.repo.Ping(ctx)
+	status := DatabaseStatus{
+		Status:  ComponentUp,
+		Latency: time.Since(start),
+	}
+	if err != nil {
+		status.Status = ComponentDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) MigrationsStatus {
+	status := MigrationsStatus{
+		Status:          ComponentUp,
+		ExpectedVersion: s.migrationVersion,
+	}
+
+	version, dirty, err := s.repo.MigrationVersion(ctx)
+	status.Version = version
+	status.Dirty = dirty
+	switch {
+	case err != nil:
+		status.Status = ComponentDown
+		status.Error = err.Error()
+	case dirty:
+		status.Status = ComponentDown
+		status.Error = fmt.Sprintf("migration %d failed and left the schema dirty", version)
+	case version != s.migrationVersion:
+		status.Status = ComponentDown
+		status.Error = fmt.Sprintf("schema is at version %d, expected %d", version, s.migrationVersion)
+	}
+	return status
+}
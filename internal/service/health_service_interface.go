@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// HealthService defines the interface for liveness and readiness checks
+type HealthService interface {
+	// Readiness checks the dependencies needed to serve requests
+	Readiness(ctx context.Context) *ReadinessReport
+}
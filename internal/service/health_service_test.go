@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// MockHealthRepository is a mock implementation of HealthRepository
+type MockHealthRepository struct {
+	mock.Mock
+}
+
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockHealthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint), args.Bool(1), args.Error(2)
+}
+
+type HealthServiceTestSuite struct {
+	suite.Suite
+	mockRepo *MockHealthRepository
+	service  HealthService
+}
+
+func (suite *HealthServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(MockHealthRepository)
+	suite.service = NewHealthService(suite.mockRepo, 8, time.Second)
+}
+
+func (suite *HealthServiceTestSuite) TestReadiness_Ready() {
+	suite.mockRepo.On("Ping", mock.Anything).Return(nil)
+	suite.mockRepo.On("MigrationVersion", mock.Anything).Return(uint(8), false, nil)
+
+	report := suite.service.Readiness(context.Background())
+
+	assert.True(suite.T(), report.Ready)
+	assert.Equal(suite.T(), ComponentUp, report.Database.Status)
+	assert.Equal(suite.T(), ComponentUp, report.Migrations.Status)
+	assert.Equal(suite.T(), uint(8), report.Migrations.Version)
+	assert.NotEmpty(suite.T(), report.Build.Version)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *HealthServiceTestSuite) TestReadiness_DatabaseDown() {
+	suite.mockRepo.On("Ping", mock.Anything).Return(assert.AnError)
+
+	report := suite.service.Readiness(context.Background())
+
+	assert.False(suite.T(), report.Ready)
+	assert.Equal(suite.T(), ComponentDown, report.Database.Status)
+	assert.Equal(suite.T(), ComponentDown, report.Migrations.Status)
+	suite.mockRepo.AssertNotCalled(suite.T(), "MigrationVersion", mock.Anything)
+}
+
+func (suite *HealthServiceTestSuite) TestReadiness_SchemaBehind() {
+	suite.mockRepo.On("Ping", mock.Anything).Return(nil)
+	suite.mockRepo.On("MigrationVersion", mock.Anything).Return(uint(7), false, nil)
+
+	report := suite.service.Readiness(context.Background())
+
+	assert.False(suite.T(), report.Ready)
+	assert.Equal(suite.T(), ComponentUp, report.Database.Status)
+	assert.Equal(suite.T(), ComponentDown, report.Migrations.Status)
+	assert.Equal(suite.T(), "schema is at version 7, expected 8", report.Migrations.Error)
+}
+
+func (suite *HealthServiceTestSuite) TestReadiness_DirtySchema() {
+	suite.mockRepo.On("Ping", mock.Anything).Return(nil)
+	suite.mockRepo.On("MigrationVersion", mock.Anything).Return(uint(8), true, nil)
+
+	report := suite.service.Readiness(context.Background())
+
+	assert.False(suite.T(), report.Ready)
+	assert.True(suite.T(), report.Migrations.Dirty)
+	assert.Equal(suite.T(), ComponentDown, report.Migrations.Status)
+}
+
+func (suite *HealthServiceTestSuite) TestReadiness_AppliesTimeout() {
+	suite.mockRepo.On("Ping", mock.Anything).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		deadline, ok := ctx.Deadline()
+		assert.True(suite.T(), ok)
+		assert.WithinDuration(suite.T(), time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	}).Return(nil)
+	suite.mockRepo.On("MigrationVersion", mock.Anything).Return(uint(8), false, nil)
+
+	suite.service.Readiness(context.Background())
+
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func TestHealthServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthServiceTestSuite))
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// MaxIdempotencyKeyLength is the length limit of idempotency keys
+const MaxIdempotencyKeyLength = 255
+
+type idempotencyService struct {
+	repo  repository.IdempotencyRepository
+	ttl   time.Duration
+	lease time.Duration
+}
+
+// NewIdempotencyService creates a new instance of idempotency key service. Responses are kept
+// for ttl after they are stored, while a key whose request is still being processed is reserved
+// for lease, after which a retry may take it over.
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl, lease time.Duration) IdempotencyService {
+	return &idempotencyService{
+		repo:  repo,
+		ttl:   ttl,
+		lease: lease,
+	}
+}
+
+// Begin reserves the key, or returns the response stored for it. A key used with another request
+// is rejected, as is a key whose first request hasn't completed yet.
+func (s *idempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (string, *StoredResponse, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("beginning idempotent request",
+		logger.String("idempotency_key", key))
+
+	if !validIdempotencyKey(key) {
+		log.Error("invalid idempotency key",
+			logger.String("idempotency_key", key))
+		return "", nil, ErrInvalidIdempotencyKey
+	}
+
+	reservation, record, err := s.repo.ReserveKey(ctx, scope, key, fingerprint, s.lease)
+	if err != nil {
+		log.Error("failed to reserve idempotency key in repository",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return "", nil, repositoryError(err, nil)
+	}
+
+	// The key is new
+	if record == nil {
+		return reservation, nil, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		log.Warn("idempotency key reused with a different request",
+			logger.String("idempotency_key", key))
+		return "", nil, ErrIdempotencyKeyReused
+	}
+	if record.StatusCode == 0 {
+		log.Warn("idempotency key is still in use",
+			logger.String("idempotency_key", key))
+		return "", nil, ErrIdempotencyKeyInProgress
+	}
+
+	log.Info("replaying idempotent response",
+		logger.String("idempotency_key", key),
+		logger.Int("status", record.StatusCode))
+
+	return "", &StoredResponse{
+		StatusCode:  record.StatusCode,
+		ContentType: record.ContentType,
+		ETag:        record.ETag,
+		Body:        record.ResponseBody,
+	}, nil
+}
+
+// Complete stores the response for the retries of the request
+func (s *idempotencyService) Complete(ctx context.Context, scope, key, reservation string, response *StoredResponse) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.repo.CompleteKey(ctx, scope, key, reservation, response.StatusCode, response.ContentType, response.ETag, response.Body, s.ttl); err != nil {
+		log.Error("failed to store idempotent response in repository",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return repositoryError(err, nil)
+	}
+
+	return nil
+}
+
+// Release removes the reservation of the key
+func (s *idempotencyService) Release(ctx context.Context, scope, key, reservation string) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.repo.ReleaseKey(ctx, scope, key, reservation); err != nil {
+		log.Error("failed to release idempotency key in repository",
+			logger.Error(err),
+			logger.String("idempotency_key", key))
+		return repositoryError(err, nil)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the expired responses
+func (s *idempotencyService) DeleteExpired(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+
+	deleted, err := s.repo.DeleteExpiredKeys(ctx)
+	if err != nil {
+		log.Error("failed to delete expired idempotency keys from repository",
+			logger.Error(err))
+		return repositoryError(err, nil)
+	}
+
+	if deleted > 0 {
+		log.Info("expired idempotency keys deleted",
+			logger.Any("count", deleted))
+	}
+
+	return nil
+}
+
+// validIdempotencyKey reports whether a key is non-empty printable ASCII within the length limit
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < ' ' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,22 @@
+package service
+
+import "context"
+
+// IdempotencyService defines the interface for idempotency keys, which let clients retry a request
+// without repeating its effect
+type IdempotencyService interface {
+	// Begin reserves a key of the scope for a request with the given fingerprint and returns the
+	// token of the reservation. If the key was already used for the same request, its stored
+	// response is returned to be replayed instead.
+	Begin(ctx context.Context, scope, key, fingerprint string) (string, *StoredResponse, error)
+
+	// Complete stores the response of the request a key was reserved for, unless the reservation
+	// was taken over by a retry after its lease
+	Complete(ctx context.Context, scope, key, reservation string, response *StoredResponse) error
+
+	// Release frees a key whose request failed, so that a retry is processed again
+	Release(ctx context.Context, scope, key, reservation string) error
+
+	// DeleteExpired removes the responses whose TTL has passed
+	DeleteExpired(ctx context.Context) error
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// MockIdempotencyRepository is a mock implementation of IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) ReserveKey(ctx context.Context, scope, key, fingerprint string, lease time.Duration) (string, *repository.IdempotencyRecord, error) {
+	args := m.Called(ctx, scope, key, fingerprint, lease)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*repository.IdempotencyRecord), args.Error(2)
+}
+
+func (m *MockIdempotencyRepository) CompleteKey(ctx context.Context, scope, key, reservation string, statusCode int, contentType, etag string, body []byte, ttl time.Duration) error {
+	args := m.Called(ctx, scope, key, reservation, statusCode, contentType, etag, body, ttl)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) ReleaseKey(ctx context.Context, scope, key, reservation string) error {
+	args := m.Called(ctx, scope, key, reservation)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type IdempotencyServiceTestSuite struct {
+	suite.Suite
+	mockRepo *MockIdempotencyRepository
+	service  IdempotencyService
+}
+
+func (suite *IdempotencyServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(MockIdempotencyRepository)
+	suite.service = NewIdempotencyService(suite.mockRepo, time.Hour, time.Minute)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_NewKey() {
+	ctx := context.Background()
+
+	suite.mockRepo.On("ReserveKey", ctx, "user-1", "key-1", "abc", time.Minute).Return("token-1", nil, nil)
+
+	reservation, stored, err := suite.service.Begin(ctx, "user-1", "key-1", "abc")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "token-1", reservation)
+	assert.Nil(suite.T(), stored)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_Replay() {
+	ctx := context.Background()
+	record := &repository.IdempotencyRecord{
+		Scope:        "user-1",
+		Key:          "key-1",
+		Fingerprint:  "abc",
+		StatusCode:   201,
+		ContentType:  "application/json",
+		ETag:         `"1"`,
+		ResponseBody: []byte(`{"id":5}`),
+	}
+
+	suite.mockRepo.On("ReserveKey", ctx, "user-1", "key-1", "abc", time.Minute).Return("", record, nil)
+
+	_, stored, err := suite.service.Begin(ctx, "user-1", "key-1", "abc")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &StoredResponse{StatusCode: 201, ContentType: "application/json", ETag: `"1"`, Body: []byte(`{"id":5}`)}, stored)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_DifferentRequest() {
+	ctx := context.Background()
+	record := &repository.IdempotencyRecord{Fingerprint: "abc", StatusCode: 201}
+
+	suite.mockRepo.On("ReserveKey", ctx, "user-1", "key-1", "def", time.Minute).Return("", record, nil)
+
+	_, stored, err := suite.service.Begin(ctx, "user-1", "key-1", "def")
+
+	assert.Nil(suite.T(), stored)
+	assert.Equal(suite.T(), ErrIdempotencyKeyReused, err)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_InProgress() {
+	ctx := context.Background()
+	record := &repository.IdempotencyRecord{Fingerprint: "abc"}
+
+	suite.mockRepo.On("ReserveKey", ctx, "user-1", "key-1", "abc", time.Minute).Return("", record, nil)
+
+	_, stored, err := suite.service.Begin(ctx, "user-1", "key-1", "abc")
+
+	assert.Nil(suite.T(), stored)
+	assert.Equal(suite.T(), ErrIdempotencyKeyInProgress, err)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_InvalidKey() {
+	for _, key := range []string{"", strings.Repeat("k", MaxIdempotencyKeyLength+1), "key\n"} {
+		_, stored, err := suite.service.Begin(context.Background(), "user-1", key, "abc")
+
+		assert.Nil(suite.T(), stored)
+		assert.Equal(suite.T(), ErrInvalidIdempotencyKey, err)
+	}
+	suite.mockRepo.AssertNotCalled(suite.T(), "ReserveKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_DatabaseUnavailable() {
+	ctx := context.Background()
+
+	suite.mockRepo.On("ReserveKey", ctx, "user-1", "key-1", "abc", time.Minute).Return("", nil, repository.ErrUnavailable)
+
+	_, stored, err := suite.service.Begin(ctx, "user-1", "key-1", "abc")
+
+	assert.Nil(suite.T(), stored)
+	assert.Equal(suite.T(), ErrUnavailable, err)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestComplete_Success() {
+	ctx := context.Background()
+
+	// Stored responses are kept for the TTL, not the lease of the reservation
+	suite.mockRepo.On("CompleteKey", ctx, "user-1", "key-1", "token-1", 201, "application/json", `"1"`, []byte(`{"id":5}`), time.Hour).Return(nil)
+
+	err := suite.service.Complete(ctx, "user-1", "key-1", "token-1", &StoredResponse{StatusCode: 201, ContentType: "application/json", ETag: `"1"`, Body: []byte(`{"id":5}`)})
+
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyServiceTestSuite) TestRelease_Success() {
+	ctx := context.Background()
+
+	suite.mockRepo.On("ReleaseKey", ctx, "user-1", "key-1", "token-1").Return(nil)
+
+	err := suite.service.Release(ctx, "user-1", "key-1", "token-1")
+
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func TestIdempotencyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyServiceTestSuite))
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+)
+
+// settableLogLevels are the levels the logger may be set to at runtime. Higher levels would hide
+// errors, so they can only be configured.
+var settableLogLevels = map[logger.Level]bool{
+	logger.DebugLevel: true,
+	logger.InfoLevel:  true,
+	logger.WarnLevel:  true,
+	logger.ErrorLevel: true,
+}
+
+type logLevelService struct {
+	log logger.Logger
+}
+
+// NewLogLevelService creates a new instance of log level service controlling the level of the given
+// logger and the loggers derived from it
+func NewLogLevelService(log logger.Logger) LogLevelService {
+	return &logLevelService{
+		log: log,
+	}
+}
+
+// Level returns the current level of the logger
+func (s *logLevelService) Level() logger.Level {
+	return s.log.Level()
+}
+
+// SetLevel parses the name of the level and sets it. The change is not persisted.
+func (s *logLevelService) SetLevel(ctx context.Context, name string) (logger.Level, error) {
+	log := logger.FromContext(ctx)
+
+	level, err := logger.ParseLevel(name)
+	if err != nil || !settableLogLevels[level] {
+		log.Error("invalid log level",
+			logger.String("level", name))
+		return 0, ErrInvalidLogLevel
+	}
+
+	previous := s.log.Level()
+	s.log.SetLevel(level)
+
+	// Logged as an error, the highest settable level, so that the change is recorded at any level
+	log.Error("log level changed",
+		logger.String("from", previous.String()),
+		logger.String("to", level.String()))
+
+	return level, nil
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+)
+
+// LogLevelService defines the interface for changing the level of the application logger at runtime
+type LogLevelService interface {
+	// Level returns the current level
+	Level() logger.Level
+
+	// SetLevel sets the level with the given name, one of debug, info, warn and error
+	SetLevel(ctx context.Context, name string) (logger.Level, error)
+}
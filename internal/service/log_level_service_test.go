@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type LogLevelServiceTestSuite struct {
+	suite.Suite
+	log     logger.Logger
+	service LogLevelService
+}
+
+func (suite *LogLevelServiceTestSuite) SetupTest() {
+	suite.log = logger.New(io.Discard)
+	suite.log.SetLevel(logger.InfoLevel)
+	suite.service = NewLogLevelService(suite.log)
+}
+
+func (suite *LogLevelServiceTestSuite) TestSetLevel_Success() {
+	level, err := suite.service.SetLevel(context.Background(), "DEBUG")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), logger.DebugLevel, level)
+	assert.Equal(suite.T(), logger.DebugLevel, suite.log.Level())
+	assert.Equal(suite.T(), logger.DebugLevel, suite.service.Level())
+}
+
+func (suite *LogLevelServiceTestSuite) TestSetLevel_LoggedAtErrorLevel() {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	ctx := logger.WithContext(context.Background(), logger.NewWithCore(core))
+
+	_, err := suite.service.SetLevel(ctx, "error")
+
+	assert.NoError(suite.T(), err)
+	entries := logs.FilterMessage("log level changed").AllUntimed()
+	if assert.Len(suite.T(), entries, 1) {
+		assert.Equal(suite.T(), zapcore.ErrorLevel, entries[0].Level)
+		assert.Equal(suite.T(), map[string]interface{}{"from": "info", "to": "error"}, entries[0].ContextMap())
+	}
+}
+
+func (suite *LogLevelServiceTestSuite) TestSetLevel_Invalid() {
+	// Levels above error can only be configured, since they would hide errors
+	for _, name := range []string{"", "verbose", "fatal", "panic"} {
+		_, err := suite.service.SetLevel(context.Background(), name)
+
+		assert.Equal(suite.T(), ErrInvalidLogLevel, err, "level %q", name)
+		assert.Equal(suite.T(), logger.InfoLevel, suite.log.Level())
+	}
+}
+
+func TestLogLevelServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LogLevelServiceTestSuite))
+}
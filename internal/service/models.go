@@ -1,64 +1,334 @@
 package service
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
 )
 
+// DefaultCurrency is used for subscriptions and cost reports that don't specify a currency
+const DefaultCurrency = "RUB"
+
 type CreateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" validate:"required,min=1,max=255"`
-	Price       int    `json:"price" validate:"required,min=0"`
-	UserID      string `json:"user_id" validate:"required,uuid4"`
-	StartDate   string `json:"start_date" validate:"required"` // Format: MM-YYYY
-	EndDate     string `json:"end_date,omitempty"`             // Format: MM-YYYY, optional
+	ServiceName           string `json:"service_name" validate:"required_without=ServiceID,omitempty,min=1,max=255"`
+	ServiceID             int    `json:"service_id,omitempty" validate:"omitempty,min=1"` // Catalog entry, takes precedence over ServiceName
+	Price                 int    `json:"price" validate:"min=0"`
+	Currency              string `json:"currency,omitempty" validate:"omitempty,iso4217"`                                                      // ISO 4217 code, defaults to RUB
+	BillingCycle          string `json:"billing_cycle,omitempty" validate:"omitempty,oneof=weekly monthly quarterly yearly custom"`            // Defaults to monthly
+	BillingIntervalMonths int    `json:"billing_interval_months,omitempty" validate:"required_if=BillingCycle custom,omitempty,min=1,max=120"` // Only for custom cycles
+	UserID                string `json:"user_id" validate:"required,uuid4"`
+	StartDate             string `json:"start_date" validate:"required"` // Format: YYYY-MM-DD or MM-YYYY
+	EndDate               string `json:"end_date,omitempty"`             // Format: YYYY-MM-DD or MM-YYYY, optional
+	TrialEndDate          string `json:"trial_end_date,omitempty"`       // Last free day, format: YYYY-MM-DD or MM-YYYY, optional
 }
 
 type UpdateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" validate:"required,min=1,max=255"`
-	Price       int    `json:"price" validate:"required,min=0"`
-	StartDate   string `json:"start_date" validate:"required"` // Format: MM-YYYY
-	EndDate     string `json:"end_date,omitempty"`             // Format: MM-YYYY, optional
+	ServiceName           string `json:"service_name" validate:"required_without=ServiceID,omitempty,min=1,max=255"`
+	ServiceID             int    `json:"service_id,omitempty" validate:"omitempty,min=1"` // Catalog entry, takes precedence over ServiceName
+	Price                 int    `json:"price" validate:"min=0"`
+	Currency              string `json:"currency,omitempty" validate:"omitempty,iso4217"`                                                      // ISO 4217 code, defaults to RUB
+	BillingCycle          string `json:"billing_cycle,omitempty" validate:"omitempty,oneof=weekly monthly quarterly yearly custom"`            // Defaults to monthly
+	BillingIntervalMonths int    `json:"billing_interval_months,omitempty" validate:"required_if=BillingCycle custom,omitempty,min=1,max=120"` // Only for custom cycles
+	StartDate             string `json:"start_date" validate:"required"`                                                                       // Format: YYYY-MM-DD or MM-YYYY
+	EndDate               string `json:"end_date,omitempty"`                                                                                   // Format: YYYY-MM-DD or MM-YYYY, optional
+	TrialEndDate          string `json:"trial_end_date,omitempty"`                                                                             // Last free day, format: YYYY-MM-DD or MM-YYYY, optional
+}
+
+// PatchSubscriptionRequest is a JSON merge patch (RFC 7396) of a subscription. Absent fields are left
+// as they are, while null clears a field: optional ones are removed, currency and billing cycle are
+// reset to their defaults, and required ones fail validation.
+type PatchSubscriptionRequest struct {
+	ServiceName           Optional[string] `json:"service_name"` // Unlinks the catalog entry unless ServiceID is set too
+	ServiceID             Optional[int]    `json:"service_id"`
+	Price                 Optional[int]    `json:"price"`
+	Currency              Optional[string] `json:"currency"`
+	BillingCycle          Optional[string] `json:"billing_cycle"`
+	BillingIntervalMonths Optional[int]    `json:"billing_interval_months"`
+	StartDate             Optional[string] `json:"start_date"`
+	EndDate               Optional[string] `json:"end_date"`
+	TrialEndDate          Optional[string] `json:"trial_end_date"`
+}
+
+// Optional is a field of a merge patch. Set tells a field that is present from an absent one, and
+// Value of a null field is the zero value.
+type Optional[T any] struct {
+	Set   bool
+	Value T
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		var zero T
+		o.Value = zero
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// Import modes
+const (
+	ImportModeAtomic     = "atomic"      // Nothing is created unless every row is valid
+	ImportModeBestEffort = "best_effort" // Valid rows are created and invalid ones are reported
+)
+
+// Import formats
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// Outcomes of an import row
+const (
+	ImportRowCreated = "created"
+	ImportRowValid   = "valid" // Not created because of a dry run or of invalid rows in an atomic import
+	ImportRowFailed  = "failed"
+)
+
+// MaxImportRows limits the number of subscriptions in one import
+const MaxImportRows = 1000
+
+// ImportSubscriptionsRequest is a batch of subscriptions to create. CSV data starts with a header
+// row naming a CreateSubscriptionRequest field for every column; Mapping renames headers that
+// aren't field names. JSON data is an array of CreateSubscriptionRequest objects.
+type ImportSubscriptionsRequest struct {
+	Format  string            `json:"format" validate:"required,oneof=csv json"`
+	Mode    string            `json:"mode,omitempty" validate:"omitempty,oneof=atomic best_effort"` // Defaults to atomic
+	DryRun  bool              `json:"dry_run,omitempty"`                                            // Only validate the rows
+	Mapping map[string]string `json:"mapping,omitempty"`                                            // CSV header -> field name
+	Data    []byte            `json:"-"`
+	UserID  string            `json:"-"` // Rows of other users fail when set
+}
+
+type ImportReport struct {
+	Mode    string            `json:"mode"`
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+type ImportRowResult struct {
+	Row    int                     `json:"row"`    // 1-based, not counting the CSV header
+	Status string                  `json:"status"` // One of the ImportRow constants
+	ID     int                     `json:"id,omitempty"`
+	Code   ErrorCode               `json:"code,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+	Fields []validation.FieldError `json:"fields,omitempty"`
+}
+
+type CreateServiceRequest struct {
+	Name            string   `json:"name" validate:"required,min=1,max=255"`
+	Category        string   `json:"category,omitempty" validate:"omitempty,max=64"`
+	DefaultPrice    *int     `json:"default_price,omitempty" validate:"omitempty,min=0"`
+	DefaultCurrency string   `json:"default_currency,omitempty" validate:"omitempty,iso4217"` // Defaults to RUB
+	Aliases         []string `json:"aliases,omitempty" validate:"omitempty,max=50,dive,max=255"`
+}
+
+type UpdateServiceRequest struct {
+	Name            string   `json:"name" validate:"required,min=1,max=255"`
+	Category        string   `json:"category,omitempty" validate:"omitempty,max=64"`
+	DefaultPrice    *int     `json:"default_price,omitempty" validate:"omitempty,min=0"`
+	DefaultCurrency string   `json:"default_currency,omitempty" validate:"omitempty,iso4217"`    // Defaults to RUB
+	Aliases         []string `json:"aliases,omitempty" validate:"omitempty,max=50,dive,max=255"` // Replaces existing aliases
+}
+
+type AddSubscriptionPriceRequest struct {
+	Price         int    `json:"price" validate:"min=0"`
+	EffectiveFrom string `json:"effective_from" validate:"required"` // Format: YYYY-MM-DD or MM-YYYY
+}
+
+type ListSubscriptionsRequest struct {
+	UserID            string `json:"user_id" validate:"required,uuid4"`
+	Limit             int    `json:"limit,omitempty" validate:"omitempty,min=1,max=100"` // Defaults to DefaultPageSize
+	Cursor            string `json:"cursor,omitempty"`                                   // NextCursor of the previous page
+	SortBy            string `json:"sort_by,omitempty" validate:"omitempty,oneof=price name start_date end_date"`
+	Order             string `json:"order,omitempty" validate:"omitempty,oneof=asc desc"` // Defaults to desc
+	Status            string `json:"status,omitempty" validate:"omitempty,oneof=active ended"`
+	ServiceNamePrefix string `json:"service_name_prefix,omitempty" validate:"omitempty,max=255"`
+	MinPrice          *int   `json:"min_price,omitempty" validate:"omitempty,min=0"`
+	MaxPrice          *int   `json:"max_price,omitempty" validate:"omitempty,min=0"`
+	ActiveFrom        string `json:"active_from,omitempty"` // Format: YYYY-MM-DD or MM-YYYY
+	ActiveTo          string `json:"active_to,omitempty"`   // Format: YYYY-MM-DD or MM-YYYY, inclusive
+}
+
+type SubscriptionsPage struct {
+	Subscriptions []*repository.Subscription `json:"subscriptions"`
+	TotalCount    int                        `json:"total_count"`           // All subscriptions matching the filters
+	NextCursor    string                     `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+type ListAuditEntriesRequest struct {
+	UserID         string `json:"user_id,omitempty" validate:"omitempty,uuid4"`
+	SubscriptionID int    `json:"subscription_id,omitempty" validate:"omitempty,min=1"`
+	Actor          string `json:"actor,omitempty"`
+	Operation      string `json:"operation,omitempty" validate:"omitempty,oneof=create update delete price_change"`
+	From           string `json:"from,omitempty"`                                     // Format: YYYY-MM-DD or MM-YYYY
+	To             string `json:"to,omitempty"`                                       // Format: YYYY-MM-DD or MM-YYYY, inclusive
+	BeforeID       int64  `json:"before_id,omitempty" validate:"omitempty,min=1"`     // NextBeforeID of the previous page
+	Limit          int    `json:"limit,omitempty" validate:"omitempty,min=1,max=500"` // Defaults to DefaultAuditPageSize
+}
+
+type AuditPage struct {
+	Entries      []*repository.AuditEntry `json:"entries"`
+	NextBeforeID int64                    `json:"next_before_id,omitempty"` // Zero on the last page
+}
+
+type ReadinessReport struct {
+	Ready      bool             `json:"ready"`
+	Database   DatabaseStatus   `json:"database"`
+	Migrations MigrationsStatus `json:"migrations"`
+	Build      buildinfo.Info   `json:"build"`
+}
+
+type DatabaseStatus struct {
+	Status  string        `json:"status"` // ComponentUp or ComponentDown
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+type MigrationsStatus struct {
+	Status          string `json:"status"` // ComponentUp or ComponentDown
+	Error           string `json:"error,omitempty"`
+	Version         uint   `json:"version"`
+	ExpectedVersion uint   `json:"expected_version"`
+	Dirty           bool   `json:"dirty"`
+}
+
+// StoredResponse is a response kept for the retries of a request with an idempotency key
+type StoredResponse struct {
+	StatusCode  int
+	ContentType string
+	ETag        string
+	Body        []byte
 }
 
 type GetCostRequest struct {
 	UserID       string   `json:"user_id" validate:"required,uuid4"`
-	ServiceNames []string `json:"service_names,omitempty"`        // Optional filter
-	StartDate    string   `json:"start_date" validate:"required"` // Format: MM-YYYY
-	EndDate      string   `json:"end_date" validate:"required"`   // Format: MM-YYYY
+	ServiceNames []string `json:"service_names,omitempty"`                                                                // Optional filter
+	StartDate    string   `json:"start_date" validate:"required"`                                                         // Format: YYYY-MM-DD or MM-YYYY
+	EndDate      string   `json:"end_date" validate:"required"`                                                           // Format: YYYY-MM-DD or MM-YYYY
+	Currency     string   `json:"currency,omitempty" validate:"omitempty,iso4217"`                                        // Target currency, defaults to RUB
+	Proration    string   `json:"proration,omitempty" validate:"omitempty,oneof=whole_months daily_prorated charge_date"` // Defaults to charge_date
+	GroupBy      string   `json:"group_by,omitempty"`                                                                     // service, month or month,service
+}
+
+// SetExchangeRateRequest sets how many units of QuoteCurrency one unit of BaseCurrency is worth
+type SetExchangeRateRequest struct {
+	BaseCurrency  string  `json:"base_currency" validate:"required,iso4217"`
+	QuoteCurrency string  `json:"quote_currency" validate:"required,iso4217,nefield=BaseCurrency"`
+	Rate          float64 `json:"rate" validate:"gt=0,lt=10000000000"` // Fits NUMERIC(20, 10)
 }
 
 type CostResponse struct {
-	UserID    string                      `json:"user_id"`
-	StartDate string                      `json:"start_date"`
-	EndDate   string                      `json:"end_date"`
-	TotalCost int                         `json:"total_cost"`
-	Breakdown []SubscriptionCostBreakdown `json:"breakdown"`
+	UserID     string                      `json:"user_id"`
+	StartDate  string                      `json:"start_date"`
+	EndDate    string                      `json:"end_date"`
+	Currency   string                      `json:"currency"`
+	Proration  string                      `json:"proration"`
+	TotalCost  float64                     `json:"total_cost"` // In Currency
+	Breakdown  []SubscriptionCostBreakdown `json:"breakdown"`
+	GroupBy    string                      `json:"group_by,omitempty"`
+	Groups     []CostGroup                 `json:"groups,omitempty"`      // Only for grouped reports
+	TimeSeries []CostPoint                 `json:"time_series,omitempty"` // Only for grouped reports, one point per month of the period
+}
+
+type CostGroup struct {
+	ServiceName        string  `json:"service_name,omitempty"` // Set when grouped by service
+	Month              string  `json:"month,omitempty"`        // Format: MM-YYYY, set when grouped by month
+	SubscriptionsCount int     `json:"subscriptions_count"`
+	TotalCost          float64 `json:"total_cost"` // In CostResponse.Currency
+}
+
+type CostPoint struct {
+	Month     string  `json:"month"`      // Format: MM-YYYY
+	TotalCost float64 `json:"total_cost"` // In CostResponse.Currency
 }
 
 type SubscriptionCostBreakdown struct {
-	SubscriptionID int    `json:"subscription_id"`
-	ServiceName    string `json:"service_name"`
-	MonthlyPrice   int    `json:"monthly_price"`
-	MonthsCount    int    `json:"months_count"`
-	TotalCost      int    `json:"total_cost"`
+	SubscriptionID        int           `json:"subscription_id"`
+	ServiceName           string        `json:"service_name"`
+	Currency              string        `json:"currency"` // Subscription's own currency
+	BillingCycle          string        `json:"billing_cycle"`
+	BillingIntervalMonths int           `json:"billing_interval_months,omitempty"` // Only for custom cycles
+	Price                 int           `json:"price"`                             // Current price per charge
+	ChargesCount          int           `json:"charges_count"`                     // Charges falling inside the period
+	MonthsCount           int           `json:"months_count"`                      // Months the subscription is active inside the period
+	TrialEndDate          string        `json:"trial_end_date,omitempty"`          // Format: YYYY-MM-DD
+	TrialChargesCount     int           `json:"trial_charges_count"`               // Charges waived by the free trial, not included in ChargesCount
+	TrialMonthsCount      int           `json:"trial_months_count"`                // Months of MonthsCount that fall into the free trial
+	TotalCost             float64       `json:"total_cost"`                        // In Currency
+	ExchangeRate          float64       `json:"exchange_rate"`                     // Currency -> CostResponse.Currency
+	ConvertedCost         float64       `json:"converted_cost"`                    // In CostResponse.Currency
+	Segments              []CostSegment `json:"segments"`                          // One per price in force during the period
+}
+
+type CostSegment struct {
+	Price        int     `json:"price"`
+	From         string  `json:"from"` // Format: YYYY-MM-DD
+	To           string  `json:"to"`   // Format: YYYY-MM-DD, inclusive
+	ChargesCount int     `json:"charges_count"`
+	TotalCost    float64 `json:"total_cost"` // In the subscription's currency
+}
+
+// ToServiceModel converts CreateServiceRequest to Service model
+func (r *CreateServiceRequest) ToServiceModel() *repository.Service {
+	name := strings.Join(strings.Fields(r.Name), " ")
+	return &repository.Service{
+		Name:            name,
+		NameKey:         NormalizeServiceName(name),
+		Category:        strings.TrimSpace(r.Category),
+		DefaultPrice:    r.DefaultPrice,
+		DefaultCurrency: currencyOrDefault(r.DefaultCurrency),
+		Aliases:         serviceAliases(name, r.Aliases),
+	}
+}
+
+// ToServiceModel converts UpdateServiceRequest to Service model
+func (r *UpdateServiceRequest) ToServiceModel() *repository.Service {
+	name := strings.Join(strings.Fields(r.Name), " ")
+	return &repository.Service{
+		Name:            name,
+		NameKey:         NormalizeServiceName(name),
+		Category:        strings.TrimSpace(r.Category),
+		DefaultPrice:    r.DefaultPrice,
+		DefaultCurrency: currencyOrDefault(r.DefaultCurrency),
+		Aliases:         serviceAliases(name, r.Aliases),
+	}
+}
+
+// ToSubscriptionPriceModel converts AddSubscriptionPriceRequest to SubscriptionPrice model
+func (r *AddSubscriptionPriceRequest) ToSubscriptionPriceModel() (*repository.SubscriptionPrice, error) {
+	effectiveFrom, err := ParseStartDate(r.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.SubscriptionPrice{
+		Price:         r.Price,
+		EffectiveFrom: effectiveFrom,
+	}, nil
 }
 
 func (r *CreateSubscriptionRequest) ToSubscriptionModel() (*repository.Subscription, error) {
-	startDate, err := ParseMonthYear(r.StartDate)
+	startDate, err := ParseStartDate(r.StartDate)
 	if err != nil {
 		return nil, err
 	}
 
 	var endDate *time.Time
 	if r.EndDate != "" {
-		endDateTime, err := ParseMonthYear(r.EndDate)
+		// Inclusive: last day of the month for MM-YYYY
+		endDateTime, err := ParseEndDate(r.EndDate)
 		if err != nil {
 			return nil, err
 		}
-		// Set end date to last day of the month
-		lastDay := GetLastDayOfMonth(endDateTime)
-		endDate = &lastDay
+		endDate = &endDateTime
 	}
 
 	// Validate that end date is after start date
@@ -66,40 +336,251 @@ func (r *CreateSubscriptionRequest) ToSubscriptionModel() (*repository.Subscript
 		return nil, ErrEndDateBeforeStart
 	}
 
+	trialEndDate, err := parseTrialEndDate(r.TrialEndDate, startDate)
+	if err != nil {
+		return nil, err
+	}
+
 	return &repository.Subscription{
-		ServiceName: r.ServiceName,
-		Price:       r.Price,
-		UserID:      r.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		ServiceName:           r.ServiceName,
+		Price:                 r.Price,
+		Currency:              currencyOrDefault(r.Currency),
+		UserID:                r.UserID,
+		BillingCycle:          billingCycleOrDefault(r.BillingCycle),
+		BillingIntervalMonths: customIntervalOrNil(r.BillingCycle, r.BillingIntervalMonths),
+		StartDate:             startDate,
+		EndDate:               endDate,
+		TrialEndDate:          trialEndDate,
 	}, nil
 }
 
 // ToSubscriptionModel converts UpdateSubscriptionRequest to Subscription model
 func (r *UpdateSubscriptionRequest) ToSubscriptionModel() (*repository.Subscription, error) {
-	startDate, err := ParseMonthYear(r.StartDate)
+	startDate, err := ParseStartDate(r.StartDate)
 	if err != nil {
 		return nil, err
 	}
 
 	var endDate *time.Time
 	if r.EndDate != "" {
-		endDateTime, err := ParseMonthYear(r.EndDate)
+		endDateTime, err := ParseEndDate(r.EndDate)
 		if err != nil {
 			return nil, err
 		}
-		lastDay := GetLastDayOfMonth(endDateTime)
-		endDate = &lastDay
+		endDate = &endDateTime
 	}
 
 	if endDate != nil && endDate.Before(startDate) {
 		return nil, ErrEndDateBeforeStart
 	}
 
+	trialEndDate, err := parseTrialEndDate(r.TrialEndDate, startDate)
+	if err != nil {
+		return nil, err
+	}
+
 	return &repository.Subscription{
-		ServiceName: r.ServiceName,
-		Price:       r.Price,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		ServiceName:           r.ServiceName,
+		Price:                 r.Price,
+		Currency:              currencyOrDefault(r.Currency),
+		BillingCycle:          billingCycleOrDefault(r.BillingCycle),
+		BillingIntervalMonths: customIntervalOrNil(r.BillingCycle, r.BillingIntervalMonths),
+		StartDate:             startDate,
+		EndDate:               endDate,
+		TrialEndDate:          trialEndDate,
 	}, nil
 }
+
+// subscriptionUpdateRequest returns the update request that would leave the subscription as it is
+func subscriptionUpdateRequest(sub *repository.Subscription) *UpdateSubscriptionRequest {
+	req := &UpdateSubscriptionRequest{
+		ServiceName:  sub.ServiceName,
+		Price:        sub.Price,
+		Currency:     sub.Currency,
+		BillingCycle: sub.BillingCycle,
+		StartDate:    sub.StartDate.Format(isoDateLayout),
+	}
+	if sub.ServiceID != nil {
+		req.ServiceID = *sub.ServiceID
+	}
+	if sub.BillingIntervalMonths != nil {
+		req.BillingIntervalMonths = *sub.BillingIntervalMonths
+	}
+	if sub.EndDate != nil {
+		req.EndDate = sub.EndDate.Format(isoDateLayout)
+	}
+	if sub.TrialEndDate != nil {
+		req.TrialEndDate = sub.TrialEndDate.Format(isoDateLayout)
+	}
+	return req
+}
+
+// apply merges the patch into an update request
+func (p *PatchSubscriptionRequest) apply(req *UpdateSubscriptionRequest) {
+	if p.ServiceName.Set {
+		req.ServiceName = p.ServiceName.Value
+		req.ServiceID = 0
+	}
+	if p.ServiceID.Set {
+		req.ServiceID = p.ServiceID.Value
+	}
+	if p.Price.Set {
+		req.Price = p.Price.Value
+	}
+	if p.Currency.Set {
+		req.Currency = p.Currency.Value
+	}
+	if p.BillingCycle.Set {
+		req.BillingCycle = p.BillingCycle.Value
+	}
+	if p.BillingIntervalMonths.Set {
+		req.BillingIntervalMonths = p.BillingIntervalMonths.Value
+	}
+	if p.StartDate.Set {
+		req.StartDate = p.StartDate.Value
+	}
+	if p.EndDate.Set {
+		req.EndDate = p.EndDate.Value
+	}
+	if p.TrialEndDate.Set {
+		req.TrialEndDate = p.TrialEndDate.Value
+	}
+}
+
+// columns returns the subscription columns the patch changes. The service name and ID are linked, as
+// are the billing cycle and interval.
+func (p *PatchSubscriptionRequest) columns() []string {
+	var columns []string
+	if p.ServiceName.Set || p.ServiceID.Set {
+		columns = append(columns, repository.ColumnServiceName, repository.ColumnServiceID)
+	}
+	if p.Price.Set {
+		columns = append(columns, repository.ColumnPrice)
+	}
+	if p.Currency.Set {
+		columns = append(columns, repository.ColumnCurrency)
+	}
+	if p.BillingCycle.Set {
+		columns = append(columns, repository.ColumnBillingCycle, repository.ColumnBillingIntervalMonths)
+	} else if p.BillingIntervalMonths.Set {
+		columns = append(columns, repository.ColumnBillingIntervalMonths)
+	}
+	if p.StartDate.Set {
+		columns = append(columns, repository.ColumnStartDate)
+	}
+	if p.EndDate.Set {
+		columns = append(columns, repository.ColumnEndDate)
+	}
+	if p.TrialEndDate.Set {
+		columns = append(columns, repository.ColumnTrialEndDate)
+	}
+	return columns
+}
+
+// ToSubscriptionFilter converts ListSubscriptionsRequest to the repository filter of one page
+func (r *ListSubscriptionsRequest) ToSubscriptionFilter() (repository.SubscriptionFilter, error) {
+	filter := repository.SubscriptionFilter{
+		UserID:            r.UserID,
+		Status:            r.Status,
+		ServiceNamePrefix: strings.TrimSpace(r.ServiceNamePrefix),
+		MinPrice:          r.MinPrice,
+		MaxPrice:          r.MaxPrice,
+		SortBy:            repository.SortByStartDate,
+		Descending:        r.Order != OrderAsc,
+		Limit:             r.Limit,
+	}
+	if sortBy, ok := sortKeys[r.SortBy]; ok {
+		filter.SortBy = sortBy
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+
+	if r.MinPrice != nil && r.MaxPrice != nil && *r.MinPrice > *r.MaxPrice {
+		return filter, ErrInvalidPriceRange
+	}
+
+	if r.ActiveFrom != "" {
+		activeFrom, err := ParseStartDate(r.ActiveFrom)
+		if err != nil {
+			return filter, err
+		}
+		filter.ActiveFrom = &activeFrom
+	}
+	if r.ActiveTo != "" {
+		activeTo, err := ParseEndDate(r.ActiveTo)
+		if err != nil {
+			return filter, err
+		}
+		filter.ActiveTo = &activeTo
+	}
+	if filter.ActiveFrom != nil && filter.ActiveTo != nil && filter.ActiveTo.Before(*filter.ActiveFrom) {
+		return filter, ErrInvalidDateRange
+	}
+
+	if r.Cursor != "" {
+		cursor, err := decodeCursor(r.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		if cursor.SortBy != filter.SortBy || (cursor.Order == OrderDesc) != filter.Descending {
+			return filter, ErrInvalidCursor
+		}
+		filter.After = &repository.SubscriptionCursor{Value: cursor.Value, ID: cursor.ID}
+	}
+
+	return filter, nil
+}
+
+// ToAuditFilter converts ListAuditEntriesRequest to the repository filter of one page
+func (r *ListAuditEntriesRequest) ToAuditFilter() (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{
+		UserID:         r.UserID,
+		SubscriptionID: r.SubscriptionID,
+		Actor:          strings.TrimSpace(r.Actor),
+		Operation:      r.Operation,
+		BeforeID:       r.BeforeID,
+		Limit:          r.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAuditPageSize
+	}
+
+	if r.From != "" {
+		from, err := ParseStartDate(r.From)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = &from
+	}
+	if r.To != "" {
+		to, err := ParseEndDate(r.To)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, ErrInvalidDateRange
+	}
+
+	return filter, nil
+}
+
+// parseTrialEndDate parses an optional inclusive trial end date and checks it doesn't precede the start date
+func parseTrialEndDate(dateStr string, startDate time.Time) (*time.Time, error) {
+	if dateStr == "" {
+		return nil, nil
+	}
+
+	trialEndDate, err := ParseEndDate(dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if trialEndDate.Before(startDate) {
+		return nil, ErrTrialEndBeforeStart
+	}
+
+	return &trialEndDate, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+)
+
+// DefaultPageSize is used for subscription lists that don't specify a limit
+const DefaultPageSize = 20
+
+// Sort orders of the subscription list
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// sortKeys maps the sort_by values of the API to repository sort keys
+var sortKeys = map[string]string{
+	"price":      repository.SortByPrice,
+	"name":       repository.SortByServiceName,
+	"start_date": repository.SortByStartDate,
+	"end_date":   repository.SortByEndDate,
+}
+
+// pageCursor is the opaque position of the next page. It remembers the sort it was issued for
+// so that it can't be replayed against a differently ordered list.
+type pageCursor struct {
+	SortBy string `json:"s"`
+	Order  string `json:"o"`
+	Value  string `json:"v"`
+	ID     int    `json:"id"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// cursorValue returns the sort key of a subscription in the format expected by repository.SubscriptionCursor
+func cursorValue(sub *repository.Subscription, sortBy string) string {
+	switch sortBy {
+	case repository.SortByPrice:
+		return strconv.Itoa(sub.Price)
+	case repository.SortByServiceName:
+		return sub.ServiceName
+	case repository.SortByEndDate:
+		if sub.EndDate == nil {
+			return "infinity"
+		}
+		return sub.EndDate.Format(isoDateLayout)
+	default:
+		return sub.StartDate.Format(isoDateLayout)
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
+)
+
+// importFields are the CreateSubscriptionRequest fields a CSV column may hold, mapped to whether
+// the field is a number
+var importFields = map[string]bool{
+	"service_name":            false,
+	"service_id":              true,
+	"price":                   true,
+	"currency":                false,
+	"billing_cycle":           false,
+	"billing_interval_months": true,
+	"user_id":                 false,
+	"start_date":              false,
+	"end_date":                false,
+	"trial_end_date":          false,
+}
+
+// importRow is a decoded row of an import, or the reason it couldn't be decoded
+type importRow struct {
+	req CreateSubscriptionRequest
+	err error
+}
+
+// ImportSubscriptions validates every row like CreateSubscription does. An atomic import creates the
+// rows in one transaction and only if all of them are valid, while a best effort import creates every
+// valid row on its own. A dry run only validates the rows.
+func (s *subscriptionService) ImportSubscriptions(ctx context.Context, req *ImportSubscriptionsRequest) (*ImportReport, error) {
+	log := logger.FromContext(ctx)
+	log.Info("importing subscriptions",
+		logger.String("format", req.Format),
+		logger.String("mode", req.Mode),
+		logger.Any("dry_run", req.DryRun))
+
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("import validation failed",
+			logger.Error(err))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+	if req.Mode == "" {
+		req.Mode = ImportModeAtomic
+	}
+
+	rows, err := parseImport(req)
+	if err != nil {
+		log.Error("failed to parse import",
+			logger.Error(err))
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: there are no subscriptions to import", ErrInvalidImport)
+	}
+	if len(rows) > MaxImportRows {
+		log.Error("import is too large",
+			logger.Int("rows", len(rows)))
+		return nil, fmt.Errorf("%w: at most %d subscriptions may be imported at once", ErrImportTooLarge, MaxImportRows)
+	}
+
+	report := &ImportReport{
+		Mode:   req.Mode,
+		DryRun: req.DryRun,
+		Total:  len(rows),
+		Rows:   make([]ImportRowResult, len(rows)),
+	}
+	subscriptions := make([]*repository.Subscription, len(rows))
+	for i, row := range rows {
+		report.Rows[i] = ImportRowResult{Row: i + 1, Status: ImportRowValid}
+		if row.err == nil {
+			subscriptions[i], row.err = s.newSubscription(ctx, &row.req)
+		}
+		if row.err == nil && req.UserID != "" && !strings.EqualFold(row.req.UserID, req.UserID) {
+			subscriptions[i], row.err = nil, ErrForeignImportRow
+		}
+		if row.err != nil {
+			report.Rows[i].fail(row.err)
+			report.Failed++
+		}
+	}
+
+	switch {
+	case req.DryRun:
+	case req.Mode == ImportModeAtomic:
+		if report.Failed > 0 {
+			break
+		}
+		if err := s.repo.CreateSubscriptions(ctx, subscriptions); err != nil {
+			log.Error("failed to create imported subscriptions in repository",
+				logger.Error(err))
+			return nil, repositoryError(err, nil)
+		}
+		for i, subscription := range subscriptions {
+			report.Rows[i].Status = ImportRowCreated
+			report.Rows[i].ID = subscription.ID
+		}
+		report.Created = len(subscriptions)
+	default:
+		for i, subscription := range subscriptions {
+			if subscription == nil {
+				continue
+			}
+			if err := s.repo.Create(ctx, subscription); err != nil {
+				log.Error("failed to create imported subscription in repository",
+					logger.Error(err),
+					logger.Int("row", i+1))
+				report.Rows[i].fail(repositoryError(err, nil))
+				report.Failed++
+				continue
+			}
+			report.Rows[i].Status = ImportRowCreated
+			report.Rows[i].ID = subscription.ID
+			report.Created++
+		}
+	}
+
+	log.Info("subscriptions imported",
+		logger.Int("created", report.Created),
+		logger.Int("failed", report.Failed))
+
+	return report, nil
+}
+
+// fail records why the row wasn't created
+func (r *ImportRowResult) fail(err error) {
+	r.Status = ImportRowFailed
+	r.Code = ErrorCodeOf(err)
+	r.Error = err.Error()
+	r.Fields = validation.Fields(err)
+}
+
+func parseImport(req *ImportSubscriptionsRequest) ([]importRow, error) {
+	if req.Format == ImportFormatCSV {
+		return parseCSVImport(req.Data, req.Mapping)
+	}
+	return parseJSONImport(req.Data)
+}
+
+func parseJSONImport(data []byte) ([]importRow, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%w: data must be a JSON array of subscriptions", ErrInvalidImport)
+	}
+
+	rows := make([]importRow, len(items))
+	for i, item := range items {
+		rows[i] = decodeImportRow(item)
+	}
+	return rows, nil
+}
+
+// parseCSVImport converts every record to a JSON object, so that its values are decoded and reported
+// the same way as those of JSON imports
+func parseCSVImport(data []byte, mapping map[string]string) ([]importRow, error) {
+	for header, field := range mapping {
+		if _, ok := importFields[field]; !ok {
+			return nil, fmt.Errorf("%w: column %q is mapped to unknown field %q", ErrInvalidImport, header, field)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: CSV data must start with a header row", ErrInvalidImport)
+	}
+
+	header := records[0]
+	fields := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		// Spreadsheets may start the file with a byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if field, ok := mapping[name]; ok {
+			name = field
+		}
+		if _, ok := importFields[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown column %q, map it to a field with the mapping parameter", ErrInvalidImport, header[i])
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: more than one column holds %s", ErrInvalidImport, name)
+		}
+		seen[name] = true
+		fields[i] = name
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		item := make(map[string]json.RawMessage, len(record))
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			// Values that aren't numbers are left as strings for the decoding to report
+			if n, err := strconv.Atoi(value); err == nil && importFields[fields[i]] {
+				item[fields[i]] = json.RawMessage(strconv.Itoa(n))
+				continue
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+			}
+			item[fields[i]] = encoded
+		}
+
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+		}
+		rows = append(rows, decodeImportRow(encoded))
+	}
+	return rows, nil
+}
+
+func decodeImportRow(item []byte) importRow {
+	var row importRow
+	if err := json.Unmarshal(item, &row.req); err != nil {
+		row.err = fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+	return row
+}
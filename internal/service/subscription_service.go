@@ -2,76 +2,104 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/logger"
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
 	"github.com/go-playground/validator/v10"
 )
 
+// maxPatchAttempts limits how many times a patch without an expected version is applied again to a
+// subscription that keeps changing under it
+const maxPatchAttempts = 3
+
 type subscriptionService struct {
 	repo      repository.SubscriptionsRepository
-	log       logger.Logger
+	catalog   repository.ServicesRepository
 	validator *validator.Validate
 }
 
-// NewSubscriptionService creates a new instance of subscription service
-func NewSubscriptionService(repo repository.SubscriptionsRepository) SubscriptionService {
+// NewSubscriptionService creates a new instance of subscription service. The services catalog
+// is used to link subscriptions to catalog entries and to resolve service name aliases.
+func NewSubscriptionService(repo repository.SubscriptionsRepository, catalog repository.ServicesRepository) SubscriptionService {
 	return &subscriptionService{
 		repo:      repo,
-		log:       logger.Global(),
-		validator: validator.New(),
+		catalog:   catalog,
+		validator: validation.New(),
 	}
 }
 
 // CreateSubscription creates a new subscription
 func (s *subscriptionService) CreateSubscription(ctx context.Context, req *CreateSubscriptionRequest) (*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
 
-	s.log.Info("creating new subscription",
+	log.Info("creating new subscription",
 		logger.String("user_id", req.UserID),
 		logger.String("service_name", req.ServiceName))
 
+	subscription, err := s.newSubscription(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create subscription
+	if err := s.repo.Create(ctx, subscription); err != nil {
+		log.Error("failed to create subscription in repository",
+			logger.Error(err),
+			logger.String("user_id", req.UserID))
+		return nil, repositoryError(err, nil)
+	}
+
+	log.Info("subscription created successfully",
+		logger.Int("subscription_id", subscription.ID),
+		logger.String("user_id", req.UserID))
+
+	return subscription, nil
+}
+
+// newSubscription validates a creation request and converts it to a subscription linked to the catalog
+func (s *subscriptionService) newSubscription(ctx context.Context, req *CreateSubscriptionRequest) (*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
+
+	req.Currency = NormalizeCurrency(req.Currency)
+
 	// Validate request
 	if err := s.validator.Struct(req); err != nil {
-		s.log.Error("subscription creation validation failed",
+		log.Error("subscription creation validation failed",
 			logger.Error(err),
 			logger.String("user_id", req.UserID))
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
 	}
 
 	// Convert to model
 	subscription, err := req.ToSubscriptionModel()
 	if err != nil {
-		s.log.Error("failed to convert request to subscription model",
+		log.Error("failed to convert request to subscription model",
 			logger.Error(err),
 			logger.String("user_id", req.UserID))
 		return nil, err
 	}
 
-	// Create subscription
-	if err := s.repo.Create(ctx, subscription); err != nil {
-		s.log.Error("failed to create subscription in repository",
-			logger.Error(err),
-			logger.String("user_id", req.UserID))
-		return nil, ErrInternalServer
+	if err := s.linkService(ctx, subscription, req.ServiceID); err != nil {
+		return nil, err
 	}
 
-	s.log.Info("subscription created successfully",
-		logger.Int("subscription_id", subscription.ID),
-		logger.String("user_id", req.UserID))
-
 	return subscription, nil
 }
 
 // GetSubscription retrieves a specific subscription
 func (s *subscriptionService) GetSubscription(ctx context.Context, userID string, subscriptionID int) (*repository.Subscription, error) {
-	s.log.Debug("getting subscription",
+	log := logger.FromContext(ctx)
+	log.Debug("getting subscription",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
 	// Validate user ID
 	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
-		s.log.Error("invalid user ID format",
+		log.Error("invalid user ID format",
 			logger.Error(err),
 			logger.String("user_id", userID))
 		return nil, ErrInvalidUserID
@@ -79,36 +107,37 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, userID string
 
 	// Validate subscription ID
 	if subscriptionID <= 0 {
-		s.log.Error("invalid subscription ID",
+		log.Error("invalid subscription ID",
 			logger.Int("subscription_id", subscriptionID))
 		return nil, ErrInvalidSubscriptionID
 	}
 
 	subscription, err := s.repo.GetSubscription(ctx, userID, subscriptionID)
 	if err != nil {
-		s.log.Error("failed to get subscription from repository",
+		log.Error("failed to get subscription from repository",
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return nil, ErrSubscriptionNotFound
+		return nil, repositoryError(err, ErrSubscriptionNotFound)
 	}
 
-	s.log.Debug("subscription retrieved successfully",
+	log.Debug("subscription retrieved successfully",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
 	return subscription, nil
 }
 
-// UpdateSubscription updates an existing subscription
-func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID string, subscriptionID int, req *UpdateSubscriptionRequest) (*repository.Subscription, error) {
-	s.log.Info("updating subscription",
+// UpdateSubscription updates an existing subscription of the expected version
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID string, subscriptionID int, req *UpdateSubscriptionRequest, expectedVersion int) (*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
+	log.Info("updating subscription",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
 	// Validate user ID
 	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
-		s.log.Error("invalid user ID format",
+		log.Error("invalid user ID format",
 			logger.Error(err),
 			logger.String("user_id", userID))
 		return nil, ErrInvalidUserID
@@ -116,24 +145,26 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID str
 
 	// Validate subscription ID
 	if subscriptionID <= 0 {
-		s.log.Error("invalid subscription ID",
+		log.Error("invalid subscription ID",
 			logger.Int("subscription_id", subscriptionID))
 		return nil, ErrInvalidSubscriptionID
 	}
 
+	req.Currency = NormalizeCurrency(req.Currency)
+
 	// Validate request
 	if err := s.validator.Struct(req); err != nil {
-		s.log.Error("subscription update validation failed",
+		log.Error("subscription update validation failed",
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
 	}
 
 	// Convert to model
 	subscription, err := req.ToSubscriptionModel()
 	if err != nil {
-		s.log.Error("failed to convert request to subscription model",
+		log.Error("failed to convert request to subscription model",
 			logger.Error(err),
 			logger.String("user_id", userID))
 		return nil, err
@@ -143,31 +174,153 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID str
 	subscription.ID = subscriptionID
 	subscription.UserID = userID
 
+	if err := s.linkService(ctx, subscription, req.ServiceID); err != nil {
+		return nil, err
+	}
+
 	// Update subscription
-	if err := s.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID); err != nil {
-		s.log.Error("failed to update subscription in repository",
+	if err := s.repo.UpdateSubscription(ctx, subscription, userID, subscriptionID, expectedVersion); err != nil {
+		log.Error("failed to update subscription in repository",
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return nil, ErrSubscriptionNotFound
+		return nil, repositoryError(err, ErrSubscriptionNotFound)
 	}
 
-	s.log.Info("subscription updated successfully",
+	log.Info("subscription updated successfully",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
 	return subscription, nil
 }
 
-// DeleteSubscription deletes a subscription
-func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID string, subscriptionID int) error {
-	s.log.Info("deleting subscription",
+// PatchSubscription applies a merge patch to a subscription. The patched subscription is validated as
+// a whole, as if it was sent with UpdateSubscription, but only the columns in the patch are written.
+// Without an expected version the patch is written over the version it was validated against, and
+// applied again if that version changes meanwhile.
+func (s *subscriptionService) PatchSubscription(ctx context.Context, userID string, subscriptionID int, req *PatchSubscriptionRequest, expectedVersion int) (*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
+	log.Info("patching subscription",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
 	// Validate user ID
 	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
-		s.log.Error("invalid user ID format",
+		log.Error("invalid user ID format",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, ErrInvalidUserID
+	}
+
+	// Validate subscription ID
+	if subscriptionID <= 0 {
+		log.Error("invalid subscription ID",
+			logger.Int("subscription_id", subscriptionID))
+		return nil, ErrInvalidSubscriptionID
+	}
+
+	for attempt := 1; ; attempt++ {
+		subscription, err := s.patchSubscription(ctx, userID, subscriptionID, req, expectedVersion)
+		if errors.Is(err, ErrPreconditionFailed) && expectedVersion == 0 && attempt < maxPatchAttempts {
+			log.Warn("subscription changed while patching, retrying",
+				logger.String("user_id", userID),
+				logger.Int("subscription_id", subscriptionID),
+				logger.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info("subscription patched successfully",
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+
+		return subscription, nil
+	}
+}
+
+// patchSubscription reads the subscription, merges the patch into it and writes the result once
+func (s *subscriptionService) patchSubscription(ctx context.Context, userID string, subscriptionID int, req *PatchSubscriptionRequest, expectedVersion int) (*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
+
+	current, err := s.repo.GetSubscription(ctx, userID, subscriptionID)
+	if err != nil {
+		log.Error("failed to get subscription from repository",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, repositoryError(err, ErrSubscriptionNotFound)
+	}
+
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		log.Warn("subscription version mismatch",
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID),
+			logger.Int("expected_version", expectedVersion),
+			logger.Int("version", current.Version))
+		return nil, ErrPreconditionFailed
+	}
+
+	columns := req.columns()
+	if len(columns) == 0 {
+		return current, nil
+	}
+
+	update := subscriptionUpdateRequest(current)
+	req.apply(update)
+	update.Currency = NormalizeCurrency(update.Currency)
+
+	// Validate the patched subscription
+	if err := s.validator.Struct(update); err != nil {
+		log.Error("subscription patch validation failed",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	subscription, err := update.ToSubscriptionModel()
+	if err != nil {
+		log.Error("failed to convert patched subscription to model",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, err
+	}
+	subscription.ID = subscriptionID
+	subscription.UserID = userID
+
+	if req.ServiceName.Set || req.ServiceID.Set {
+		if err := s.linkService(ctx, subscription, update.ServiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	version := expectedVersion
+	if version == 0 {
+		version = current.Version
+	}
+	if err := s.repo.PatchSubscription(ctx, subscription, columns, userID, subscriptionID, version); err != nil {
+		log.Error("failed to patch subscription in repository",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, repositoryError(err, ErrSubscriptionNotFound)
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription deletes a subscription of the expected version
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error {
+	log := logger.FromContext(ctx)
+	log.Info("deleting subscription",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID))
+
+	// Validate user ID
+	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
+		log.Error("invalid user ID format",
 			logger.Error(err),
 			logger.String("user_id", userID))
 		return ErrInvalidUserID
@@ -175,20 +328,20 @@ func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID str
 
 	// Validate subscription ID
 	if subscriptionID <= 0 {
-		s.log.Error("invalid subscription ID",
+		log.Error("invalid subscription ID",
 			logger.Int("subscription_id", subscriptionID))
 		return ErrInvalidSubscriptionID
 	}
 
-	if err := s.repo.DeleteSubscription(ctx, userID, subscriptionID); err != nil {
-		s.log.Error("failed to delete subscription from repository",
+	if err := s.repo.DeleteSubscription(ctx, userID, subscriptionID, expectedVersion); err != nil {
+		log.Error("failed to delete subscription from repository",
 			logger.Error(err),
 			logger.String("user_id", userID),
 			logger.Int("subscription_id", subscriptionID))
-		return ErrSubscriptionNotFound
+		return repositoryError(err, ErrSubscriptionNotFound)
 	}
 
-	s.log.Info("subscription deleted successfully",
+	log.Info("subscription deleted successfully",
 		logger.String("user_id", userID),
 		logger.Int("subscription_id", subscriptionID))
 
@@ -197,12 +350,13 @@ func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID str
 
 // GetUserSubscriptions retrieves all subscriptions for a user
 func (s *subscriptionService) GetUserSubscriptions(ctx context.Context, userID string) ([]*repository.Subscription, error) {
-	s.log.Debug("getting user subscriptions",
+	log := logger.FromContext(ctx)
+	log.Debug("getting user subscriptions",
 		logger.String("user_id", userID))
 
 	// Validate user ID
 	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
-		s.log.Error("invalid user ID format",
+		log.Error("invalid user ID format",
 			logger.Error(err),
 			logger.String("user_id", userID))
 		return nil, ErrInvalidUserID
@@ -210,13 +364,203 @@ func (s *subscriptionService) GetUserSubscriptions(ctx context.Context, userID s
 
 	subscriptions, err := s.repo.GetSubscriptionsByUserID(ctx, userID)
 	if err != nil {
-		s.log.Error("failed to get user subscriptions from repository",
+		log.Error("failed to get user subscriptions from repository",
 			logger.Error(err),
 			logger.String("user_id", userID))
-		return nil, ErrInternalServer
+		return nil, repositoryError(err, nil)
 	}
 
-	s.log.Debug("user subscriptions retrieved successfully",
+	log.Debug("user subscriptions retrieved successfully",
+		logger.String("user_id", userID),
+		logger.Int("count", len(subscriptions)))
+
+	return subscriptions, nil
+}
+
+// ListUserSubscriptions retrieves one page of a user's subscriptions matching the request filters
+func (s *subscriptionService) ListUserSubscriptions(ctx context.Context, req *ListSubscriptionsRequest) (*SubscriptionsPage, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("listing user subscriptions",
+		logger.String("user_id", req.UserID),
+		logger.String("sort_by", req.SortBy),
+		logger.Int("limit", req.Limit))
+
+	// Validate request
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("list subscriptions validation failed",
+			logger.Error(err),
+			logger.String("user_id", req.UserID))
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	filter, err := req.ToSubscriptionFilter()
+	if err != nil {
+		log.Error("invalid list subscriptions request",
+			logger.Error(err),
+			logger.String("user_id", req.UserID))
+		return nil, err
+	}
+
+	// One extra row tells whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+
+	subscriptions, total, err := s.repo.ListSubscriptions(ctx, filter)
+	if err != nil {
+		log.Error("failed to list user subscriptions from repository",
+			logger.Error(err),
+			logger.String("user_id", req.UserID))
+		return nil, repositoryError(err, nil)
+	}
+
+	page := &SubscriptionsPage{
+		Subscriptions: subscriptions,
+		TotalCount:    total,
+	}
+	if len(subscriptions) > pageSize {
+		page.Subscriptions = subscriptions[:pageSize]
+		last := page.Subscriptions[pageSize-1]
+
+		order := OrderAsc
+		if filter.Descending {
+			order = OrderDesc
+		}
+		page.NextCursor = encodeCursor(pageCursor{
+			SortBy: filter.SortBy,
+			Order:  order,
+			Value:  cursorValue(last, filter.SortBy),
+			ID:     last.ID,
+		})
+	}
+
+	log.Debug("user subscriptions listed successfully",
+		logger.String("user_id", req.UserID),
+		logger.Int("count", len(page.Subscriptions)),
+		logger.Int("total", total))
+
+	return page, nil
+}
+
+// AddSubscriptionPrice schedules a new price for a subscription and returns the updated price history
+// and the version of the subscription
+func (s *subscriptionService) AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, req *AddSubscriptionPriceRequest) ([]*repository.SubscriptionPrice, int, error) {
+	log := logger.FromContext(ctx)
+	log.Info("adding subscription price",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID))
+
+	// Validate user ID
+	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
+		log.Error("invalid user ID format",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, 0, ErrInvalidUserID
+	}
+
+	// Validate subscription ID
+	if subscriptionID <= 0 {
+		log.Error("invalid subscription ID",
+			logger.Int("subscription_id", subscriptionID))
+		return nil, 0, ErrInvalidSubscriptionID
+	}
+
+	// Validate request
+	if err := s.validator.Struct(req); err != nil {
+		log.Error("subscription price validation failed",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, 0, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
+	}
+
+	price, err := req.ToSubscriptionPriceModel()
+	if err != nil {
+		log.Error("failed to convert request to subscription price model",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, 0, err
+	}
+
+	version, err := s.repo.AddSubscriptionPrice(ctx, userID, subscriptionID, price)
+	if err != nil {
+		log.Error("failed to add subscription price in repository",
+			logger.Error(err),
+			logger.String("user_id", userID),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, 0, repositoryError(err, ErrSubscriptionNotFound)
+	}
+
+	prices, err := s.repo.GetSubscriptionPrices(ctx, []int{subscriptionID})
+	if err != nil {
+		log.Error("failed to get subscription prices from repository",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, 0, repositoryError(err, nil)
+	}
+
+	log.Info("subscription price added successfully",
+		logger.String("user_id", userID),
+		logger.Int("subscription_id", subscriptionID),
+		logger.Int("price", price.Price))
+
+	return prices, version, nil
+}
+
+// GetSubscriptionPrices retrieves the price history of a subscription
+func (s *subscriptionService) GetSubscriptionPrices(ctx context.Context, userID string, subscriptionID int) ([]*repository.SubscriptionPrice, error) {
+	log := logger.FromContext(ctx)
+	// Validates the IDs and checks that the subscription belongs to the user
+	if _, err := s.GetSubscription(ctx, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repo.GetSubscriptionPrices(ctx, []int{subscriptionID})
+	if err != nil {
+		log.Error("failed to get subscription prices from repository",
+			logger.Error(err),
+			logger.Int("subscription_id", subscriptionID))
+		return nil, repositoryError(err, nil)
+	}
+
+	return prices, nil
+}
+
+// GetEndingTrials retrieves the user's subscriptions whose free trial ends within the given window from today,
+// so that they can be cancelled before turning into paid ones
+func (s *subscriptionService) GetEndingTrials(ctx context.Context, userID string, within string) ([]*repository.Subscription, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("getting subscriptions with ending trials",
+		logger.String("user_id", userID),
+		logger.String("within", within))
+
+	// Validate user ID
+	if err := s.validator.Var(userID, "required,uuid4"); err != nil {
+		log.Error("invalid user ID format",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, ErrInvalidUserID
+	}
+
+	days, err := ParseDayWindow(within)
+	if err != nil {
+		log.Error("invalid trial window",
+			logger.Error(err),
+			logger.String("within", within))
+		return nil, err
+	}
+
+	from := TruncateToDay(time.Now().UTC())
+	to := from.AddDate(0, 0, days)
+
+	subscriptions, err := s.repo.GetTrialsEndingBetween(ctx, userID, from, to)
+	if err != nil {
+		log.Error("failed to get subscriptions with ending trials from repository",
+			logger.Error(err),
+			logger.String("user_id", userID))
+		return nil, repositoryError(err, nil)
+	}
+
+	log.Debug("subscriptions with ending trials retrieved successfully",
 		logger.String("user_id", userID),
 		logger.Int("count", len(subscriptions)))
 
@@ -225,96 +569,302 @@ func (s *subscriptionService) GetUserSubscriptions(ctx context.Context, userID s
 
 // CalculateTotalCost calculates total cost of subscriptions for a period
 func (s *subscriptionService) CalculateTotalCost(ctx context.Context, req *GetCostRequest) (*CostResponse, error) {
-	s.log.Info("calculating total subscription cost",
+	log := logger.FromContext(ctx)
+	log.Info("calculating total subscription cost",
 		logger.String("user_id", req.UserID),
 		logger.String("start_date", req.StartDate),
 		logger.String("end_date", req.EndDate))
 
+	req.Currency = NormalizeCurrency(req.Currency)
+
 	// Validate request
 	if err := s.validator.Struct(req); err != nil {
-		s.log.Error("cost calculation validation failed",
+		log.Error("cost calculation validation failed",
 			logger.Error(err),
 			logger.String("user_id", req.UserID))
-		return nil, fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrValidation, validation.FromError(err))
 	}
 
 	// Parse dates
-	startDate, err := ParseMonthYear(req.StartDate)
+	startDate, err := ParseStartDate(req.StartDate)
 	if err != nil {
-		s.log.Error("failed to parse start date",
+		log.Error("failed to parse start date",
 			logger.Error(err),
 			logger.String("start_date", req.StartDate))
 		return nil, err
 	}
 
-	endDate, err := ParseMonthYear(req.EndDate)
+	// Inclusive: last day of the month for MM-YYYY
+	endDate, err := ParseEndDate(req.EndDate)
 	if err != nil {
-		s.log.Error("failed to parse end date",
+		log.Error("failed to parse end date",
 			logger.Error(err),
 			logger.String("end_date", req.EndDate))
 		return nil, err
 	}
 
-	// Set end date to last day of the month
-	endDate = GetLastDayOfMonth(endDate)
-
 	// Validate date range
 	if endDate.Before(startDate) {
-		s.log.Error("end date is before start date",
+		log.Error("end date is before start date",
 			logger.String("start_date", req.StartDate),
 			logger.String("end_date", req.EndDate))
 		return nil, ErrInvalidDateRange
 	}
 
+	groupBy, err := ParseGroupBy(req.GroupBy)
+	if err != nil {
+		log.Error("invalid cost grouping",
+			logger.String("group_by", req.GroupBy))
+		return nil, err
+	}
+
+	serviceNames, serviceIDs, err := s.resolveServiceFilter(ctx, req.ServiceNames)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get subscriptions for the period
-	subscriptions, err := s.repo.GetSubscriptionsByPeriod(ctx, req.UserID, req.ServiceNames, startDate, endDate)
+	subscriptions, err := s.repo.GetSubscriptionsByPeriod(ctx, req.UserID, serviceNames, serviceIDs, startDate, endDate)
 	if err != nil {
-		s.log.Error("failed to get subscriptions for period",
+		log.Error("failed to get subscriptions for period",
 			logger.Error(err),
 			logger.String("user_id", req.UserID))
-		return nil, ErrInternalServer
+		return nil, repositoryError(err, nil)
+	}
+
+	schedules, err := s.priceSchedules(ctx, subscriptions)
+	if err != nil {
+		log.Error("failed to get subscription prices for period",
+			logger.Error(err),
+			logger.String("user_id", req.UserID))
+		return nil, repositoryError(err, nil)
 	}
 
 	// Calculate costs
-	var totalCost int
+	targetCurrency := currencyOrDefault(req.Currency)
+	proration := prorationOrDefault(req.Proration)
+	rates := newRateCache(s.repo, targetCurrency)
+
+	var totalCost float64
 	breakdown := make([]SubscriptionCostBreakdown, 0, len(subscriptions))
+	grouped := make([]groupedCost, 0, len(subscriptions))
 
 	for _, sub := range subscriptions {
-		monthsCount := CalculateSubscriptionMonthsInPeriod(&sub.StartDate, sub.EndDate, startDate, endDate)
-		if monthsCount <= 0 {
+		cost := calculateSubscriptionCost(sub, schedules[sub.ID], startDate, endDate, proration)
+		if cost.MonthsCount <= 0 {
 			continue
 		}
 
-		subTotalCost := sub.Price * monthsCount
-		totalCost += subTotalCost
+		subCurrency := currencyOrDefault(sub.Currency)
+		rate, err := rates.rate(ctx, subCurrency)
+		if err != nil {
+			log.Error("failed to get exchange rate",
+				logger.Error(err),
+				logger.String("currency", subCurrency),
+				logger.String("target_currency", targetCurrency))
+			if errors.Is(err, ErrExchangeRateNotFound) {
+				return nil, err
+			}
+			return nil, repositoryError(err, nil)
+		}
+
+		convertedCost := RoundMoney(cost.Amount * rate)
+		totalCost += convertedCost
+
+		convertedMonthly := make(map[string]float64, len(cost.Monthly))
+		for month, amount := range cost.Monthly {
+			convertedMonthly[month] = amount * rate
+		}
+		grouped = append(grouped, groupedCost{
+			ServiceName: sub.ServiceName,
+			TotalCost:   convertedCost,
+			Monthly:     convertedMonthly,
+		})
+
+		var customIntervalMonths int
+		if sub.BillingCycle == BillingCycleCustom {
+			customIntervalMonths = billingIntervalMonths(sub.BillingCycle, sub.BillingIntervalMonths)
+		}
 
 		breakdown = append(breakdown, SubscriptionCostBreakdown{
-			SubscriptionID: sub.ID,
-			ServiceName:    sub.ServiceName,
-			MonthlyPrice:   sub.Price,
-			MonthsCount:    monthsCount,
-			TotalCost:      subTotalCost,
+			SubscriptionID:        sub.ID,
+			ServiceName:           sub.ServiceName,
+			Currency:              subCurrency,
+			BillingCycle:          billingCycleOrDefault(sub.BillingCycle),
+			BillingIntervalMonths: customIntervalMonths,
+			Price:                 sub.Price,
+			ChargesCount:          cost.ChargesCount,
+			MonthsCount:           cost.MonthsCount,
+			TrialEndDate:          formatOptionalDate(sub.TrialEndDate),
+			TrialChargesCount:     cost.TrialChargesCount,
+			TrialMonthsCount:      cost.TrialMonthsCount,
+			TotalCost:             cost.Amount,
+			ExchangeRate:          rate,
+			ConvertedCost:         convertedCost,
+			Segments:              toCostSegments(cost.Segments),
 		})
 
-		s.log.Debug("calculated cost for subscription",
+		log.Debug("calculated cost for subscription",
 			logger.Int("subscription_id", sub.ID),
 			logger.String("service_name", sub.ServiceName),
-			logger.Int("months_count", monthsCount),
-			logger.Int("total_cost", subTotalCost))
+			logger.String("billing_cycle", billingCycleOrDefault(sub.BillingCycle)),
+			logger.Int("charges_count", cost.ChargesCount),
+			logger.Any("total_cost", cost.Amount),
+			logger.Any("converted_cost", convertedCost))
 	}
 
 	response := &CostResponse{
 		UserID:    req.UserID,
 		StartDate: req.StartDate,
 		EndDate:   req.EndDate,
-		TotalCost: totalCost,
+		Currency:  targetCurrency,
+		Proration: proration,
+		TotalCost: RoundMoney(totalCost),
 		Breakdown: breakdown,
 	}
 
-	s.log.Info("total subscription cost calculated successfully",
+	if groupBy != "" {
+		months := reportMonths(startDate, endDate)
+		response.GroupBy = groupBy
+		response.Groups = groupCosts(groupBy, months, grouped)
+		response.TimeSeries = costTimeSeries(months, grouped)
+	}
+
+	log.Info("total subscription cost calculated successfully",
 		logger.String("user_id", req.UserID),
-		logger.Int("total_cost", totalCost),
+		logger.Any("total_cost", response.TotalCost),
+		logger.String("currency", targetCurrency),
 		logger.Int("subscriptions_count", len(breakdown)))
 
 	return response, nil
 }
+
+// linkService links a subscription to its catalog entry, either the one requested explicitly or the one
+// whose name or alias matches the subscription's service name, and switches to the canonical name.
+// Names that aren't in the catalog are kept as is.
+func (s *subscriptionService) linkService(ctx context.Context, subscription *repository.Subscription, serviceID int) error {
+	log := logger.FromContext(ctx)
+	var (
+		service *repository.Service
+		err     error
+	)
+	if serviceID > 0 {
+		service, err = s.catalog.GetService(ctx, serviceID)
+	} else {
+		service, err = s.catalog.ResolveService(ctx, NormalizeServiceName(subscription.ServiceName))
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrServiceNotFound) {
+			if serviceID > 0 {
+				log.Error("catalog service not found",
+					logger.Int("service_id", serviceID))
+				return ErrServiceNotFound
+			}
+			return nil
+		}
+		log.Error("failed to resolve catalog service",
+			logger.Error(err),
+			logger.String("service_name", subscription.ServiceName))
+		return repositoryError(err, nil)
+	}
+
+	subscription.ServiceID = &service.ID
+	subscription.ServiceName = service.Name
+	return nil
+}
+
+// resolveServiceFilter expands the service names of a cost filter through the catalog. Names of catalog
+// entries match by the entry's ID as well as by its canonical name and every alias, so that subscriptions
+// created before they were linked to the catalog are found too.
+func (s *subscriptionService) resolveServiceFilter(ctx context.Context, names []string) ([]string, []int, error) {
+	log := logger.FromContext(ctx)
+	if len(names) == 0 {
+		return nil, nil, nil
+	}
+
+	var (
+		serviceNames []string
+		serviceIDs   []int
+	)
+	seenNames := make(map[string]bool)
+	seenIDs := make(map[int]bool)
+	addName := func(name string) {
+		if !seenNames[name] {
+			seenNames[name] = true
+			serviceNames = append(serviceNames, name)
+		}
+	}
+
+	for _, name := range names {
+		key := NormalizeServiceName(name)
+		if key == "" {
+			continue
+		}
+
+		service, err := s.catalog.ResolveService(ctx, key)
+		if err != nil {
+			if errors.Is(err, repository.ErrServiceNotFound) {
+				addName(name)
+				continue
+			}
+			log.Error("failed to resolve catalog service",
+				logger.Error(err),
+				logger.String("service_name", name))
+			return nil, nil, repositoryError(err, nil)
+		}
+
+		if !seenIDs[service.ID] {
+			seenIDs[service.ID] = true
+			serviceIDs = append(serviceIDs, service.ID)
+		}
+		for _, serviceName := range catalogNames(service) {
+			addName(serviceName)
+		}
+	}
+
+	return serviceNames, serviceIDs, nil
+}
+
+// priceSchedules loads the price history of the given subscriptions keyed by subscription ID
+func (s *subscriptionService) priceSchedules(ctx context.Context, subscriptions []*repository.Subscription) (map[int]priceSchedule, error) {
+	schedules := make(map[int]priceSchedule, len(subscriptions))
+	if len(subscriptions) == 0 {
+		return schedules, nil
+	}
+
+	ids := make([]int, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		ids = append(ids, sub.ID)
+	}
+
+	prices, err := s.repo.GetSubscriptionPrices(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	pricesByID := make(map[int][]*repository.SubscriptionPrice, len(subscriptions))
+	for _, price := range prices {
+		pricesByID[price.SubscriptionID] = append(pricesByID[price.SubscriptionID], price)
+	}
+
+	for _, sub := range subscriptions {
+		schedules[sub.ID] = newPriceSchedule(sub, pricesByID[sub.ID])
+	}
+
+	return schedules, nil
+}
+
+func toCostSegments(segments []costSegment) []CostSegment {
+	result := make([]CostSegment, 0, len(segments))
+	for _, segment := range segments {
+		result = append(result, CostSegment{
+			Price:        segment.Price,
+			From:         segment.From.Format(isoDateLayout),
+			To:           segment.To.Format(isoDateLayout),
+			ChargesCount: segment.ChargesCount,
+			TotalCost:    segment.Amount,
+		})
+	}
+	return result
+}
@@ -10,10 +10,23 @@ import (
 type SubscriptionService interface {
 	// CRUD operations
 	CreateSubscription(ctx context.Context, req *CreateSubscriptionRequest) (*repository.Subscription, error)
+	// ImportSubscriptions creates a batch of subscriptions and reports the outcome of every row
+	ImportSubscriptions(ctx context.Context, req *ImportSubscriptionsRequest) (*ImportReport, error)
 	GetSubscription(ctx context.Context, userID string, subscriptionID int) (*repository.Subscription, error)
-	UpdateSubscription(ctx context.Context, userID string, subscriptionID int, req *UpdateSubscriptionRequest) (*repository.Subscription, error)
-	DeleteSubscription(ctx context.Context, userID string, subscriptionID int) error
+	// UpdateSubscription and DeleteSubscription fail with ErrPreconditionFailed unless the subscription
+	// has the expected version. An expected version of 0 matches any version.
+	UpdateSubscription(ctx context.Context, userID string, subscriptionID int, req *UpdateSubscriptionRequest, expectedVersion int) (*repository.Subscription, error)
+	DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error
+	// PatchSubscription changes only the fields present in the merge patch
+	PatchSubscription(ctx context.Context, userID string, subscriptionID int, req *PatchSubscriptionRequest, expectedVersion int) (*repository.Subscription, error)
 	GetUserSubscriptions(ctx context.Context, userID string) ([]*repository.Subscription, error)
+	ListUserSubscriptions(ctx context.Context, req *ListSubscriptionsRequest) (*SubscriptionsPage, error)
+	GetEndingTrials(ctx context.Context, userID string, within string) ([]*repository.Subscription, error)
+
+	// Price history
+	// AddSubscriptionPrice also returns the version of the subscription, which changes with its current price
+	AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, req *AddSubscriptionPriceRequest) ([]*repository.SubscriptionPrice, int, error)
+	GetSubscriptionPrices(ctx context.Context, userID string, subscriptionID int) ([]*repository.SubscriptionPrice, error)
 
 	// Cost calculation
 	CalculateTotalCost(ctx context.Context, req *GetCostRequest) (*CostResponse, error)
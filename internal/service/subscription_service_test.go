@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -26,6 +31,16 @@ func (m *MockSubscriptionsRepository) Create(ctx context.Context, subscription *
 	return args.Error(0)
 }
 
+func (m *MockSubscriptionsRepository) CreateSubscriptions(ctx context.Context, subscriptions []*repository.Subscription) error {
+	args := m.Called(ctx, subscriptions)
+	if args.Error(0) == nil {
+		for i, subscription := range subscriptions {
+			subscription.ID = i + 1
+		}
+	}
+	return args.Error(0)
+}
+
 func (m *MockSubscriptionsRepository) GetSubscription(ctx context.Context, userID string, subscriptionID int) (*repository.Subscription, error) {
 	args := m.Called(ctx, userID, subscriptionID)
 	if args.Get(0) == nil {
@@ -34,13 +49,18 @@ func (m *MockSubscriptionsRepository) GetSubscription(ctx context.Context, userI
 	return args.Get(0).(*repository.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *repository.Subscription, userID string, subscriptionID int) error {
-	args := m.Called(ctx, subscription, userID, subscriptionID)
+func (m *MockSubscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *repository.Subscription, userID string, subscriptionID int, expectedVersion int) error {
+	args := m.Called(ctx, subscription, userID, subscriptionID, expectedVersion)
 	return args.Error(0)
 }
 
-func (m *MockSubscriptionsRepository) DeleteSubscription(ctx context.Context, userID string, subscriptionID int) error {
-	args := m.Called(ctx, userID, subscriptionID)
+func (m *MockSubscriptionsRepository) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error {
+	args := m.Called(ctx, userID, subscriptionID, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionsRepository) PatchSubscription(ctx context.Context, subscription *repository.Subscription, columns []string, userID string, subscriptionID int, expectedVersion int) error {
+	args := m.Called(ctx, subscription, columns, userID, subscriptionID, expectedVersion)
 	return args.Error(0)
 }
 
@@ -52,14 +72,51 @@ func (m *MockSubscriptionsRepository) GetSubscriptionsByUserID(ctx context.Conte
 	return args.Get(0).([]*repository.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, startDate, endDate time.Time) ([]*repository.Subscription, error) {
-	args := m.Called(ctx, userID, serviceNames, startDate, endDate)
+func (m *MockSubscriptionsRepository) ListSubscriptions(ctx context.Context, filter repository.SubscriptionFilter) ([]*repository.Subscription, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*repository.Subscription), args.Int(1), args.Error(2)
+}
+
+func (m *MockSubscriptionsRepository) GetSubscriptionsByPeriod(ctx context.Context, userID string, serviceNames []string, serviceIDs []int, startDate, endDate time.Time) ([]*repository.Subscription, error) {
+	args := m.Called(ctx, userID, serviceNames, serviceIDs, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionsRepository) GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string) (*repository.ExchangeRate, error) {
+	args := m.Called(ctx, baseCurrency, quoteCurrency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ExchangeRate), args.Error(1)
+}
+
+func (m *MockSubscriptionsRepository) GetTrialsEndingBetween(ctx context.Context, userID string, from, to time.Time) ([]*repository.Subscription, error) {
+	args := m.Called(ctx, userID, from, to)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*repository.Subscription), args.Error(1)
 }
 
+func (m *MockSubscriptionsRepository) AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, price *repository.SubscriptionPrice) (int, error) {
+	args := m.Called(ctx, userID, subscriptionID, price)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSubscriptionsRepository) GetSubscriptionPrices(ctx context.Context, subscriptionIDs []int) ([]*repository.SubscriptionPrice, error) {
+	args := m.Called(ctx, subscriptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.SubscriptionPrice), args.Error(1)
+}
+
 func (m *MockSubscriptionsRepository) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -72,13 +129,15 @@ func (m *MockSubscriptionsRepository) RunMigrations(migrationsFilePath string) e
 
 type SubscriptionServiceTestSuite struct {
 	suite.Suite
-	mockRepo *MockSubscriptionsRepository
-	service  SubscriptionService
+	mockRepo    *MockSubscriptionsRepository
+	mockCatalog *MockServicesRepository
+	service     SubscriptionService
 }
 
 func (suite *SubscriptionServiceTestSuite) SetupTest() {
 	suite.mockRepo = new(MockSubscriptionsRepository)
-	suite.service = NewSubscriptionService(suite.mockRepo)
+	suite.mockCatalog = new(MockServicesRepository)
+	suite.service = NewSubscriptionService(suite.mockRepo, suite.mockCatalog)
 }
 
 func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_Success() {
@@ -91,6 +150,7 @@ func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_Success() {
 		EndDate:     "12-2025",
 	}
 
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
 	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
 
 	result, err := suite.service.CreateSubscription(ctx, req)
@@ -100,6 +160,9 @@ func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_Success() {
 	assert.Equal(suite.T(), 1, result.ID) // Mock sets ID to 1
 	assert.Equal(suite.T(), "Netflix", result.ServiceName)
 	assert.Equal(suite.T(), 599, result.Price)
+	assert.Equal(suite.T(), "RUB", result.Currency)
+	assert.Equal(suite.T(), BillingCycleMonthly, result.BillingCycle)
+	assert.Nil(suite.T(), result.BillingIntervalMonths)
 	assert.Equal(suite.T(), req.UserID, result.UserID)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
@@ -114,6 +177,7 @@ func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_WithoutEndDate
 		EndDate:     "",
 	}
 
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
 	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
 
 	result, err := suite.service.CreateSubscription(ctx, req)
@@ -135,12 +199,195 @@ func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_InvalidUserID(
 
 	result, err := suite.service.CreateSubscription(ctx, req)
 
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+	assert.Equal(suite.T(), []validation.FieldError{
+		{Field: "user_id", Code: "uuid4", Message: "must be a valid UUID"},
+	}, validation.Fields(err))
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create")
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_ISODates() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       599,
+		UserID:      "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:   "2025-01-20",
+		EndDate:     "2025-06-19",
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Equal(suite.T(), time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), result.StartDate)
+	assert.Equal(suite.T(), time.Date(2025, 6, 19, 23, 59, 59, 999999999, time.UTC), *result.EndDate)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_WithCurrency() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName: "ChatGPT Plus",
+		Price:       20,
+		Currency:    "usd",
+		UserID:      "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:   "01-2025",
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Equal(suite.T(), "USD", result.Currency)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_InvalidCurrency() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName: "ChatGPT Plus",
+		Price:       20,
+		Currency:    "DOLLARS",
+		UserID:      "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:   "01-2025",
+	}
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "validation failed")
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create")
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_CustomBillingCycle() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName:           "Domain renewal",
+		Price:                 1500,
+		BillingCycle:          BillingCycleCustom,
+		BillingIntervalMonths: 24,
+		UserID:                "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:             "01-2025",
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Equal(suite.T(), BillingCycleCustom, result.BillingCycle)
+	assert.Equal(suite.T(), 24, *result.BillingIntervalMonths)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_CustomBillingCycleWithoutInterval() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName:  "Domain renewal",
+		Price:        1500,
+		BillingCycle: BillingCycleCustom,
+		UserID:       "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:    "01-2025",
+	}
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
 	assert.Contains(suite.T(), err.Error(), "validation failed")
 	suite.mockRepo.AssertNotCalled(suite.T(), "Create")
 }
 
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_WithTrial() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName:  "Kinopoisk",
+		Price:        299,
+		UserID:       "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:    "2025-07-20",
+		TrialEndDate: "2025-08-19",
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result.TrialEndDate)
+	assert.Equal(suite.T(), "2025-08-19", result.TrialEndDate.Format("2006-01-02"))
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_TrialEndBeforeStart() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName:  "Kinopoisk",
+		Price:        299,
+		UserID:       "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:    "2025-07-20",
+		TrialEndDate: "2025-07-19",
+	}
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrTrialEndBeforeStart, err)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_ResolvesServiceAlias() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceName: "Яндекс Плюс",
+		Price:       399,
+		UserID:      "550e8400-e29b-41d4-a716-446655440000",
+		StartDate:   "07-2025",
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, "яндекс плюс").Return(&repository.Service{ID: 5, Name: "Yandex Plus"}, nil)
+	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil)
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Yandex Plus", result.ServiceName)
+	assert.Equal(suite.T(), 5, *result.ServiceID)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockCatalog.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_UnknownServiceID() {
+	ctx := context.Background()
+	req := &CreateSubscriptionRequest{
+		ServiceID: 42,
+		Price:     399,
+		UserID:    "550e8400-e29b-41d4-a716-446655440000",
+		StartDate: "07-2025",
+	}
+
+	suite.mockCatalog.On("GetService", ctx, 42).Return(nil, repository.ErrServiceNotFound)
+
+	result, err := suite.service.CreateSubscription(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrServiceNotFound, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
 func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_InvalidPrice() {
 	ctx := context.Background()
 	req := &CreateSubscriptionRequest{
@@ -202,6 +449,7 @@ func (suite *SubscriptionServiceTestSuite) TestCreateSubscription_RepositoryErro
 		StartDate:   "01-2025",
 	}
 
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
 	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(errors.New("database error"))
 
 	result, err := suite.service.CreateSubscription(ctx, req)
@@ -266,7 +514,7 @@ func (suite *SubscriptionServiceTestSuite) TestGetSubscription_NotFound() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 999
 
-	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(nil, errors.New("not found"))
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(nil, repository.ErrNotFound)
 
 	result, err := suite.service.GetSubscription(ctx, userID, subscriptionID)
 
@@ -276,6 +524,33 @@ func (suite *SubscriptionServiceTestSuite) TestGetSubscription_NotFound() {
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *SubscriptionServiceTestSuite) TestGetSubscription_DatabaseUnavailable() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(nil, fmt.Errorf("failed to get subscription: %w", repository.ErrUnavailable))
+
+	result, err := suite.service.GetSubscription(ctx, userID, subscriptionID)
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrUnavailable, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestDeleteSubscription_DatabaseTimeout() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+
+	suite.mockRepo.On("DeleteSubscription", ctx, userID, subscriptionID, 0).Return(fmt.Errorf("failed to delete subscription: %w", repository.ErrTimeout))
+
+	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID, 0)
+
+	assert.Equal(suite.T(), ErrTimeout, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
 func (suite *SubscriptionServiceTestSuite) TestUpdateSubscription_Success() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
@@ -288,9 +563,10 @@ func (suite *SubscriptionServiceTestSuite) TestUpdateSubscription_Success() {
 		EndDate:     "12-2025",
 	}
 
-	suite.mockRepo.On("UpdateSubscription", ctx, mock.AnythingOfType("*repository.Subscription"), userID, subscriptionID).Return(nil)
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("UpdateSubscription", ctx, mock.AnythingOfType("*repository.Subscription"), userID, subscriptionID, 0).Return(nil)
 
-	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req)
+	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req, 0)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
@@ -311,7 +587,7 @@ func (suite *SubscriptionServiceTestSuite) TestUpdateSubscription_InvalidUserID(
 		StartDate:   "01-2025",
 	}
 
-	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req)
+	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -329,7 +605,7 @@ func (suite *SubscriptionServiceTestSuite) TestUpdateSubscription_InvalidSubscri
 		StartDate:   "01-2025",
 	}
 
-	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req)
+	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -347,13 +623,140 @@ func (suite *SubscriptionServiceTestSuite) TestUpdateSubscription_RepositoryErro
 		StartDate:   "01-2025",
 	}
 
-	suite.mockRepo.On("UpdateSubscription", ctx, mock.AnythingOfType("*repository.Subscription"), userID, subscriptionID).Return(errors.New("update failed"))
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("UpdateSubscription", ctx, mock.AnythingOfType("*repository.Subscription"), userID, subscriptionID, 0).Return(errors.New("update failed"))
 
-	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req)
+	result, err := suite.service.UpdateSubscription(ctx, userID, subscriptionID, req, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
-	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
+	assert.Equal(suite.T(), ErrInternalServer, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestPatchSubscription_ClearsEndDate() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	endDate := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	current := &repository.Subscription{
+		ID:           subscriptionID,
+		ServiceName:  "Netflix",
+		Price:        599,
+		Currency:     "RUB",
+		BillingCycle: "monthly",
+		UserID:       userID,
+		StartDate:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:      &endDate,
+		Version:      2,
+	}
+
+	var req PatchSubscriptionRequest
+	require.NoError(suite.T(), json.Unmarshal([]byte(`{"price": 799, "end_date": null}`), &req))
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(current, nil)
+	suite.mockRepo.On("PatchSubscription", ctx, mock.AnythingOfType("*repository.Subscription"), []string{repository.ColumnPrice, repository.ColumnEndDate}, userID, subscriptionID, 2).Return(nil)
+
+	result, err := suite.service.PatchSubscription(ctx, userID, subscriptionID, &req, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 799, result.Price)
+	assert.Nil(suite.T(), result.EndDate)
+	assert.Equal(suite.T(), "Netflix", result.ServiceName)
+	suite.mockCatalog.AssertNotCalled(suite.T(), "ResolveService", mock.Anything, mock.Anything)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestPatchSubscription_ZeroPrice() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	current := &repository.Subscription{
+		ID:           subscriptionID,
+		ServiceName:  "Netflix",
+		Price:        599,
+		Currency:     "RUB",
+		BillingCycle: "monthly",
+		UserID:       userID,
+		StartDate:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Version:      1,
+	}
+
+	var req PatchSubscriptionRequest
+	require.NoError(suite.T(), json.Unmarshal([]byte(`{"price": 0}`), &req))
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(current, nil)
+	suite.mockRepo.On("PatchSubscription", ctx, mock.AnythingOfType("*repository.Subscription"), []string{repository.ColumnPrice}, userID, subscriptionID, 1).Return(nil)
+
+	result, err := suite.service.PatchSubscription(ctx, userID, subscriptionID, &req, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, result.Price)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestPatchSubscription_NullRequiredField() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	current := &repository.Subscription{
+		ID:          subscriptionID,
+		ServiceName: "Netflix",
+		Price:       599,
+		UserID:      userID,
+		StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Version:     1,
+	}
+
+	var req PatchSubscriptionRequest
+	require.NoError(suite.T(), json.Unmarshal([]byte(`{"start_date": null}`), &req))
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(current, nil)
+
+	result, err := suite.service.PatchSubscription(ctx, userID, subscriptionID, &req, 0)
+
+	assert.Nil(suite.T(), result)
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+	suite.mockRepo.AssertNotCalled(suite.T(), "PatchSubscription", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestPatchSubscription_VersionMismatch() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	current := &repository.Subscription{ID: subscriptionID, UserID: userID, Version: 3}
+
+	req := &PatchSubscriptionRequest{Price: Optional[int]{Set: true, Value: 799}}
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(current, nil)
+
+	result, err := suite.service.PatchSubscription(ctx, userID, subscriptionID, req, 2)
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrPreconditionFailed, err)
+	suite.mockRepo.AssertNumberOfCalls(suite.T(), "GetSubscription", 1)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestPatchSubscription_RetriesConcurrentChange() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	req := &PatchSubscriptionRequest{Price: Optional[int]{Set: true, Value: 799}}
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).
+		Return(&repository.Subscription{ID: subscriptionID, ServiceName: "Netflix", Price: 599, UserID: userID, StartDate: startDate, Version: 1}, nil).Once()
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).
+		Return(&repository.Subscription{ID: subscriptionID, ServiceName: "Netflix", Price: 699, UserID: userID, StartDate: startDate, Version: 2}, nil).Once()
+	suite.mockRepo.On("PatchSubscription", ctx, mock.Anything, []string{repository.ColumnPrice}, userID, subscriptionID, 1).
+		Return(fmt.Errorf("subscription %w", repository.ErrVersionMismatch))
+	suite.mockRepo.On("PatchSubscription", ctx, mock.Anything, []string{repository.ColumnPrice}, userID, subscriptionID, 2).Return(nil)
+
+	result, err := suite.service.PatchSubscription(ctx, userID, subscriptionID, req, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 799, result.Price)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
@@ -362,9 +765,9 @@ func (suite *SubscriptionServiceTestSuite) TestDeleteSubscription_Success() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 1
 
-	suite.mockRepo.On("DeleteSubscription", ctx, userID, subscriptionID).Return(nil)
+	suite.mockRepo.On("DeleteSubscription", ctx, userID, subscriptionID, 0).Return(nil)
 
-	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID)
+	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID, 0)
 
 	assert.NoError(suite.T(), err)
 	suite.mockRepo.AssertExpectations(suite.T())
@@ -375,7 +778,7 @@ func (suite *SubscriptionServiceTestSuite) TestDeleteSubscription_InvalidUserID(
 	userID := "invalid-uuid"
 	subscriptionID := 1
 
-	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID)
+	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), ErrInvalidUserID, err)
@@ -387,7 +790,7 @@ func (suite *SubscriptionServiceTestSuite) TestDeleteSubscription_InvalidSubscri
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 0
 
-	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID)
+	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), ErrInvalidSubscriptionID, err)
@@ -399,15 +802,28 @@ func (suite *SubscriptionServiceTestSuite) TestDeleteSubscription_NotFound() {
 	userID := "550e8400-e29b-41d4-a716-446655440000"
 	subscriptionID := 999
 
-	suite.mockRepo.On("DeleteSubscription", ctx, userID, subscriptionID).Return(errors.New("not found"))
+	suite.mockRepo.On("DeleteSubscription", ctx, userID, subscriptionID, 0).Return(repository.ErrNotFound)
 
-	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID)
+	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID, 0)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *SubscriptionServiceTestSuite) TestDeleteSubscription_VersionMismatch() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+
+	suite.mockRepo.On("DeleteSubscription", ctx, userID, subscriptionID, 2).Return(fmt.Errorf("subscription %w", repository.ErrVersionMismatch))
+
+	err := suite.service.DeleteSubscription(ctx, userID, subscriptionID, 2)
+
+	assert.Equal(suite.T(), ErrPreconditionFailed, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
 func (suite *SubscriptionServiceTestSuite) TestGetUserSubscriptions_Success() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
@@ -466,20 +882,107 @@ func (suite *SubscriptionServiceTestSuite) TestGetUserSubscriptions_RepositoryEr
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
-func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_Success() {
+func (suite *SubscriptionServiceTestSuite) TestListUserSubscriptions_Pagination() {
 	ctx := context.Background()
 	userID := "550e8400-e29b-41d4-a716-446655440000"
+	minPrice := 100
 
-	req := &GetCostRequest{
-		UserID:    userID,
-		StartDate: "01-2025",
-		EndDate:   "06-2025",
+	firstPage := []*repository.Subscription{
+		{ID: 3, ServiceName: "Netflix", Price: 599, UserID: userID, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 7, ServiceName: "Spotify", Price: 299, UserID: userID, StartDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 9, ServiceName: "YouTube", Price: 299, UserID: userID, StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
 	}
 
-	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
-	endDate := time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)
+	// One extra row is requested to detect the next page
+	suite.mockRepo.On("ListSubscriptions", ctx, repository.SubscriptionFilter{
+		UserID:     userID,
+		Status:     repository.StatusActive,
+		MinPrice:   &minPrice,
+		SortBy:     repository.SortByPrice,
+		Descending: true,
+		Limit:      3,
+	}).Return(firstPage, 5, nil).Once()
+
+	req := &ListSubscriptionsRequest{
+		UserID:   userID,
+		Limit:    2,
+		SortBy:   "price",
+		Status:   repository.StatusActive,
+		MinPrice: &minPrice,
+	}
+	page, err := suite.service.ListUserSubscriptions(ctx, req)
 
-	subscriptions := []*repository.Subscription{
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), page.Subscriptions, 2)
+	assert.Equal(suite.T(), 5, page.TotalCount)
+	assert.NotEmpty(suite.T(), page.NextCursor)
+
+	// The cursor continues after the last row of the page
+	suite.mockRepo.On("ListSubscriptions", ctx, repository.SubscriptionFilter{
+		UserID:     userID,
+		Status:     repository.StatusActive,
+		MinPrice:   &minPrice,
+		SortBy:     repository.SortByPrice,
+		Descending: true,
+		After:      &repository.SubscriptionCursor{Value: "299", ID: 7},
+		Limit:      3,
+	}).Return(firstPage[2:], 5, nil).Once()
+
+	req.Cursor = page.NextCursor
+	page, err = suite.service.ListUserSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), page.Subscriptions, 1)
+	assert.Empty(suite.T(), page.NextCursor)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestListUserSubscriptions_InvalidRequest() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	minPrice, maxPrice := 500, 100
+
+	sortedByPrice := encodeCursor(pageCursor{SortBy: repository.SortByPrice, Order: OrderDesc, Value: "299", ID: 7})
+
+	tests := []struct {
+		name string
+		req  *ListSubscriptionsRequest
+		err  error
+	}{
+		{"price range", &ListSubscriptionsRequest{UserID: userID, MinPrice: &minPrice, MaxPrice: &maxPrice}, ErrInvalidPriceRange},
+		{"date range", &ListSubscriptionsRequest{UserID: userID, ActiveFrom: "2025-06-01", ActiveTo: "2025-01-31"}, ErrInvalidDateRange},
+		{"malformed cursor", &ListSubscriptionsRequest{UserID: userID, Cursor: "not-a-cursor"}, ErrInvalidCursor},
+		{"cursor of another sort", &ListSubscriptionsRequest{UserID: userID, SortBy: "name", Cursor: sortedByPrice}, ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		result, err := suite.service.ListUserSubscriptions(ctx, tt.req)
+
+		assert.ErrorIs(suite.T(), err, tt.err, tt.name)
+		assert.Nil(suite.T(), result, tt.name)
+	}
+
+	result, err := suite.service.ListUserSubscriptions(ctx, &ListSubscriptionsRequest{UserID: userID, Limit: 500})
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "validation failed")
+	suite.mockRepo.AssertNotCalled(suite.T(), "ListSubscriptions")
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_Success() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := &GetCostRequest{
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   "06-2025",
+	}
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
 		{
 			ID:          1,
 			ServiceName: "Netflix",
@@ -498,7 +1001,8 @@ func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_Success() {
 		},
 	}
 
-	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
 
 	result, err := suite.service.CalculateTotalCost(ctx, req)
 
@@ -507,7 +1011,8 @@ func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_Success() {
 	assert.Equal(suite.T(), userID, result.UserID)
 	assert.Equal(suite.T(), "01-2025", result.StartDate)
 	assert.Equal(suite.T(), "06-2025", result.EndDate)
-	assert.Greater(suite.T(), result.TotalCost, 0)
+	assert.Equal(suite.T(), "RUB", result.Currency)
+	assert.Greater(suite.T(), result.TotalCost, 0.0)
 	assert.Len(suite.T(), result.Breakdown, 2)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
@@ -537,7 +1042,9 @@ func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_WithServiceFil
 		},
 	}
 
-	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string{"Netflix"}, startDate, endDate).Return(subscriptions, nil)
+	suite.mockCatalog.On("ResolveService", ctx, "netflix").Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string{"Netflix"}, []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
 
 	result, err := suite.service.CalculateTotalCost(ctx, req)
 
@@ -548,6 +1055,532 @@ func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_WithServiceFil
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_ServiceFilterResolvesAliases() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := &GetCostRequest{
+		UserID:       userID,
+		ServiceNames: []string{"yandex plus", "ЯНДЕКС ПЛЮС", "Some Local Gym"},
+		StartDate:    "01-2025",
+		EndDate:      "06-2025",
+	}
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)
+
+	catalogEntry := &repository.Service{
+		ID:   5,
+		Name: "Yandex Plus",
+		Aliases: []repository.ServiceAlias{
+			{ServiceID: 5, Alias: "Яндекс Плюс", AliasKey: "яндекс плюс"},
+		},
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, "yandex plus").Return(catalogEntry, nil)
+	suite.mockCatalog.On("ResolveService", ctx, "яндекс плюс").Return(catalogEntry, nil)
+	suite.mockCatalog.On("ResolveService", ctx, "some local gym").Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string{"Yandex Plus", "Яндекс Плюс", "Some Local Gym"}, []int{5}, startDate, endDate).
+		Return([]*repository.Subscription{}, nil)
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), result.Breakdown)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockCatalog.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_ConvertsCurrencies() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := &GetCostRequest{
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   "03-2025",
+		Currency:  "rub",
+	}
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 3, 31, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:          1,
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			Currency:    "RUB",
+			UserID:      userID,
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:          2,
+			ServiceName: "ChatGPT Plus",
+			Price:       20,
+			Currency:    "USD",
+			UserID:      userID,
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+	suite.mockRepo.On("GetExchangeRate", ctx, "USD", "RUB").Return(&repository.ExchangeRate{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "RUB",
+		Rate:          80.5,
+	}, nil).Once()
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Equal(suite.T(), "RUB", result.Currency)
+	assert.Len(suite.T(), result.Breakdown, 2)
+
+	assert.Equal(suite.T(), "RUB", result.Breakdown[0].Currency)
+	assert.Equal(suite.T(), 1200.0, result.Breakdown[0].TotalCost)
+	assert.Equal(suite.T(), 1.0, result.Breakdown[0].ExchangeRate)
+	assert.Equal(suite.T(), 1200.0, result.Breakdown[0].ConvertedCost)
+
+	assert.Equal(suite.T(), "USD", result.Breakdown[1].Currency)
+	assert.Equal(suite.T(), 60.0, result.Breakdown[1].TotalCost)
+	assert.Equal(suite.T(), 80.5, result.Breakdown[1].ExchangeRate)
+	assert.Equal(suite.T(), 4830.0, result.Breakdown[1].ConvertedCost)
+
+	assert.Equal(suite.T(), 6030.0, result.TotalCost)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_InverseExchangeRate() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := &GetCostRequest{
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   "03-2025",
+		Currency:  "USD",
+	}
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 3, 31, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:          1,
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			Currency:    "RUB",
+			UserID:      userID,
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// Only the rate of the opposite direction is stored
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+	suite.mockRepo.On("GetExchangeRate", ctx, "RUB", "USD").Return(nil, repository.ErrExchangeRateNotFound).Once()
+	suite.mockRepo.On("GetExchangeRate", ctx, "USD", "RUB").Return(&repository.ExchangeRate{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "RUB",
+		Rate:          80,
+	}, nil).Once()
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Breakdown, 1)
+	assert.Equal(suite.T(), 1200.0, result.Breakdown[0].TotalCost)
+	assert.Equal(suite.T(), 0.0125, result.Breakdown[0].ExchangeRate)
+	assert.Equal(suite.T(), 15.0, result.Breakdown[0].ConvertedCost)
+	assert.Equal(suite.T(), 15.0, result.TotalCost)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_BillingCycles() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := &GetCostRequest{
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   "12-2025",
+	}
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:           1,
+			ServiceName:  "Yandex Plus",
+			Price:        3990,
+			Currency:     "RUB",
+			BillingCycle: BillingCycleYearly,
+			UserID:       userID,
+			StartDate:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:           2,
+			ServiceName:  "Kinopoisk",
+			Price:        799,
+			Currency:     "RUB",
+			BillingCycle: BillingCycleQuarterly,
+			UserID:       userID,
+			StartDate:    time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Len(suite.T(), result.Breakdown, 2)
+
+	assert.Equal(suite.T(), BillingCycleYearly, result.Breakdown[0].BillingCycle)
+	assert.Equal(suite.T(), 1, result.Breakdown[0].ChargesCount)
+	assert.Equal(suite.T(), 12, result.Breakdown[0].MonthsCount)
+	assert.Equal(suite.T(), 3990.0, result.Breakdown[0].TotalCost)
+
+	assert.Equal(suite.T(), BillingCycleQuarterly, result.Breakdown[1].BillingCycle)
+	assert.Equal(suite.T(), 4, result.Breakdown[1].ChargesCount)
+	assert.Equal(suite.T(), 3196.0, result.Breakdown[1].TotalCost)
+
+	assert.Equal(suite.T(), 7186.0, result.TotalCost)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_ProrationModes() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:          1,
+			ServiceName: "Netflix",
+			Price:       620,
+			Currency:    "RUB",
+			UserID:      userID,
+			StartDate:   time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+
+	tests := []struct {
+		proration string
+		expected  float64
+	}{
+		{proration: "", expected: 620},
+		{proration: ProrationChargeDate, expected: 620},
+		{proration: ProrationWholeMonths, expected: 620},
+		{proration: ProrationDailyProrated, expected: 240}, // Jan 20-31 is 12 of the 31 days until Feb 19
+	}
+
+	for _, tt := range tests {
+		req := &GetCostRequest{
+			UserID:    userID,
+			StartDate: "2025-01-01",
+			EndDate:   "2025-01-31",
+			Proration: tt.proration,
+		}
+
+		result, err := suite.service.CalculateTotalCost(ctx, req)
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), prorationOrDefault(tt.proration), result.Proration)
+		assert.Equal(suite.T(), tt.expected, result.TotalCost, tt.proration)
+	}
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_PriceHistory() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:          1,
+			ServiceName: "Yandex Plus",
+			Price:       500,
+			Currency:    "RUB",
+			UserID:      userID,
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	prices := []*repository.SubscriptionPrice{
+		{SubscriptionID: 1, Price: 400, EffectiveFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{SubscriptionID: 1, Price: 500, EffectiveFrom: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, []int{1}).Return(prices, nil)
+
+	for _, proration := range []string{ProrationChargeDate, ProrationWholeMonths, ProrationDailyProrated} {
+		req := &GetCostRequest{
+			UserID:    userID,
+			StartDate: "01-2025",
+			EndDate:   "06-2025",
+			Proration: proration,
+		}
+
+		result, err := suite.service.CalculateTotalCost(ctx, req)
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 2700.0, result.TotalCost, proration)
+		assert.Len(suite.T(), result.Breakdown, 1)
+
+		segments := result.Breakdown[0].Segments
+		assert.Len(suite.T(), segments, 2)
+		assert.Equal(suite.T(), CostSegment{Price: 400, From: "2025-01-01", To: "2025-03-31", ChargesCount: 3, TotalCost: 1200}, segments[0], proration)
+		assert.Equal(suite.T(), CostSegment{Price: 500, From: "2025-04-01", To: "2025-06-30", ChargesCount: 3, TotalCost: 1500}, segments[1], proration)
+	}
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_TrialPeriod() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)
+	trialEndDate := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:           1,
+			ServiceName:  "Kinopoisk",
+			Price:        300,
+			Currency:     "RUB",
+			UserID:       userID,
+			StartDate:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			TrialEndDate: &trialEndDate,
+		},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+
+	for _, proration := range []string{ProrationChargeDate, ProrationWholeMonths, ProrationDailyProrated} {
+		req := &GetCostRequest{
+			UserID:    userID,
+			StartDate: "01-2025",
+			EndDate:   "06-2025",
+			Proration: proration,
+		}
+
+		result, err := suite.service.CalculateTotalCost(ctx, req)
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 1200.0, result.TotalCost, proration)
+
+		line := result.Breakdown[0]
+		assert.Equal(suite.T(), "2025-02-28", line.TrialEndDate)
+		assert.Equal(suite.T(), 4, line.ChargesCount)
+		assert.Equal(suite.T(), 2, line.TrialChargesCount)
+		assert.Equal(suite.T(), 6, line.MonthsCount)
+		assert.Equal(suite.T(), 2, line.TrialMonthsCount)
+	}
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_InvalidProration() {
+	ctx := context.Background()
+	req := &GetCostRequest{
+		UserID:    "550e8400-e29b-41d4-a716-446655440000",
+		StartDate: "01-2025",
+		EndDate:   "06-2025",
+		Proration: "hourly",
+	}
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "validation failed")
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetSubscriptionsByPeriod")
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_GroupBy() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 4, 30, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{ID: 1, ServiceName: "Netflix", Price: 500, UserID: userID, StartDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, ServiceName: "Spotify", Price: 300, UserID: userID, StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+
+	months := []string{"01-2025", "02-2025", "03-2025", "04-2025"}
+	monthTotals := []float64{0, 500, 800, 800}
+
+	tests := []struct {
+		groupBy string
+		want    string
+		check   func(result *CostResponse)
+	}{
+		{
+			groupBy: "service",
+			want:    GroupByService,
+			check: func(result *CostResponse) {
+				assert.Equal(suite.T(), []CostGroup{
+					{ServiceName: "Netflix", SubscriptionsCount: 1, TotalCost: 1500},
+					{ServiceName: "Spotify", SubscriptionsCount: 1, TotalCost: 600},
+				}, result.Groups)
+			},
+		},
+		{
+			groupBy: "month",
+			want:    GroupByMonth,
+			check: func(result *CostResponse) {
+				assert.Len(suite.T(), result.Groups, len(months))
+				for i, group := range result.Groups {
+					assert.Equal(suite.T(), months[i], group.Month)
+					assert.Empty(suite.T(), group.ServiceName)
+					assert.Equal(suite.T(), monthTotals[i], group.TotalCost)
+				}
+				assert.Equal(suite.T(), 0, result.Groups[0].SubscriptionsCount)
+				assert.Equal(suite.T(), 2, result.Groups[2].SubscriptionsCount)
+			},
+		},
+		{
+			groupBy: "service, month",
+			want:    GroupByMonthService,
+			check: func(result *CostResponse) {
+				// Every month is present for every service
+				assert.Len(suite.T(), result.Groups, len(months)*2)
+				assert.Equal(suite.T(), CostGroup{ServiceName: "Netflix", Month: "02-2025", SubscriptionsCount: 1, TotalCost: 500}, result.Groups[2])
+				assert.Equal(suite.T(), CostGroup{ServiceName: "Spotify", Month: "02-2025", TotalCost: 0}, result.Groups[3])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := suite.service.CalculateTotalCost(ctx, &GetCostRequest{
+			UserID:    userID,
+			StartDate: "01-2025",
+			EndDate:   "04-2025",
+			GroupBy:   tt.groupBy,
+		})
+
+		assert.NoError(suite.T(), err, tt.groupBy)
+		assert.Equal(suite.T(), tt.want, result.GroupBy, tt.groupBy)
+		assert.Equal(suite.T(), 2100.0, result.TotalCost, tt.groupBy)
+		assert.Len(suite.T(), result.TimeSeries, len(months), tt.groupBy)
+		for i, point := range result.TimeSeries {
+			assert.Equal(suite.T(), months[i], point.Month, tt.groupBy)
+			assert.Equal(suite.T(), monthTotals[i], point.TotalCost, tt.groupBy)
+		}
+		tt.check(result)
+	}
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_GroupByMonthDailyProrated() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 2, 28, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{ID: 1, ServiceName: "Netflix", Price: 300, UserID: userID, StartDate: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+
+	result, err := suite.service.CalculateTotalCost(ctx, &GetCostRequest{
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   "02-2025",
+		Proration: ProrationDailyProrated,
+		GroupBy:   GroupByMonth,
+	})
+
+	// The Jan 16 - Feb 15 billing period is split between January and February
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 439.29, result.TotalCost)
+	assert.Equal(suite.T(), []CostPoint{
+		{Month: "01-2025", TotalCost: 154.84},
+		{Month: "02-2025", TotalCost: 284.45},
+	}, result.TimeSeries)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_InvalidGroupBy() {
+	ctx := context.Background()
+	req := &GetCostRequest{
+		UserID:    "550e8400-e29b-41d4-a716-446655440000",
+		StartDate: "01-2025",
+		EndDate:   "06-2025",
+		GroupBy:   "week",
+	}
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.ErrorIs(suite.T(), err, ErrInvalidGroupBy)
+	assert.Nil(suite.T(), result)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetSubscriptionsByPeriod")
+}
+
+func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_MissingExchangeRate() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := &GetCostRequest{
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   "03-2025",
+		Currency:  "EUR",
+	}
+
+	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, 3, 31, 23, 59, 59, 999999999, time.UTC)
+
+	subscriptions := []*repository.Subscription{
+		{
+			ID:          1,
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			Currency:    "RUB",
+			UserID:      userID,
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(subscriptions, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, mock.Anything).Return([]*repository.SubscriptionPrice{}, nil)
+	suite.mockRepo.On("GetExchangeRate", ctx, "RUB", "EUR").Return(nil, repository.ErrExchangeRateNotFound)
+	suite.mockRepo.On("GetExchangeRate", ctx, "EUR", "RUB").Return(nil, repository.ErrExchangeRateNotFound)
+
+	result, err := suite.service.CalculateTotalCost(ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.ErrorIs(suite.T(), err, ErrExchangeRateNotFound)
+	assert.Contains(suite.T(), err.Error(), "RUB to EUR")
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
 func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_InvalidUserID() {
 	ctx := context.Background()
 	req := &GetCostRequest{
@@ -608,7 +1641,7 @@ func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_RepositoryErro
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 6, 30, 23, 59, 59, 999999999, time.UTC)
 
-	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), startDate, endDate).Return(nil, errors.New("database error"))
+	suite.mockRepo.On("GetSubscriptionsByPeriod", ctx, userID, []string(nil), []int(nil), startDate, endDate).Return(nil, errors.New("database error"))
 
 	result, err := suite.service.CalculateTotalCost(ctx, req)
 
@@ -618,6 +1651,281 @@ func (suite *SubscriptionServiceTestSuite) TestCalculateTotalCost_RepositoryErro
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *SubscriptionServiceTestSuite) TestGetEndingTrials_Success() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	trialEndDate := time.Now().UTC().AddDate(0, 0, 3)
+
+	expectedSubs := []*repository.Subscription{
+		{
+			ID:           1,
+			ServiceName:  "Kinopoisk",
+			Price:        299,
+			UserID:       userID,
+			StartDate:    time.Now().UTC().AddDate(0, -1, 0),
+			TrialEndDate: &trialEndDate,
+		},
+	}
+
+	suite.mockRepo.On("GetTrialsEndingBetween", ctx, userID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			from := args.Get(2).(time.Time)
+			to := args.Get(3).(time.Time)
+			assert.Equal(suite.T(), from.AddDate(0, 0, 14), to)
+		}).
+		Return(expectedSubs, nil)
+
+	result, err := suite.service.GetEndingTrials(ctx, userID, "2w")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestGetEndingTrials_InvalidWindow() {
+	ctx := context.Background()
+
+	result, err := suite.service.GetEndingTrials(ctx, "550e8400-e29b-41d4-a716-446655440000", "soon")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrInvalidDayWindow, err)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestAddSubscriptionPrice_Success() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 1
+
+	req := &AddSubscriptionPriceRequest{
+		Price:         499,
+		EffectiveFrom: "2025-09-01",
+	}
+
+	prices := []*repository.SubscriptionPrice{
+		{SubscriptionID: subscriptionID, Price: 399, EffectiveFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{SubscriptionID: subscriptionID, Price: 499, EffectiveFrom: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	suite.mockRepo.On("AddSubscriptionPrice", ctx, userID, subscriptionID, mock.MatchedBy(func(price *repository.SubscriptionPrice) bool {
+		return price.Price == 499 && price.EffectiveFrom.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+	})).Return(4, nil)
+	suite.mockRepo.On("GetSubscriptionPrices", ctx, []int{subscriptionID}).Return(prices, nil)
+
+	result, version, err := suite.service.AddSubscriptionPrice(ctx, userID, subscriptionID, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), 4, version)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestAddSubscriptionPrice_InvalidDateFormat() {
+	ctx := context.Background()
+	req := &AddSubscriptionPriceRequest{
+		Price:         499,
+		EffectiveFrom: "2025/09/01",
+	}
+
+	result, _, err := suite.service.AddSubscriptionPrice(ctx, "550e8400-e29b-41d4-a716-446655440000", 1, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrInvalidDateFormat, err)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestAddSubscriptionPrice_NotFound() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 999
+
+	req := &AddSubscriptionPriceRequest{
+		Price:         499,
+		EffectiveFrom: "2025-09-01",
+	}
+
+	suite.mockRepo.On("AddSubscriptionPrice", ctx, userID, subscriptionID, mock.AnythingOfType("*repository.SubscriptionPrice")).Return(0, repository.ErrNotFound)
+
+	result, _, err := suite.service.AddSubscriptionPrice(ctx, userID, subscriptionID, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestGetSubscriptionPrices_NotFound() {
+	ctx := context.Background()
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	subscriptionID := 999
+
+	suite.mockRepo.On("GetSubscription", ctx, userID, subscriptionID).Return(nil, repository.ErrNotFound)
+
+	result, err := suite.service.GetSubscriptionPrices(ctx, userID, subscriptionID)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), ErrSubscriptionNotFound, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_Atomic() {
+	ctx := context.Background()
+	req := &ImportSubscriptionsRequest{
+		Format: ImportFormatJSON,
+		Data: []byte(`[
+			{"service_name": "Netflix", "price": 599, "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"},
+			{"service_name": "Spotify", "price": 299, "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "2025-02-01"}
+		]`),
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("CreateSubscriptions", ctx, mock.AnythingOfType("[]*repository.Subscription")).Return(nil)
+
+	report, err := suite.service.ImportSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ImportModeAtomic, report.Mode)
+	assert.Equal(suite.T(), 2, report.Total)
+	assert.Equal(suite.T(), 2, report.Created)
+	assert.Equal(suite.T(), 0, report.Failed)
+	assert.Equal(suite.T(), []ImportRowResult{
+		{Row: 1, Status: ImportRowCreated, ID: 1},
+		{Row: 2, Status: ImportRowCreated, ID: 2},
+	}, report.Rows)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_AtomicWithInvalidRow() {
+	ctx := context.Background()
+	req := &ImportSubscriptionsRequest{
+		Format: ImportFormatJSON,
+		Data: []byte(`[
+			{"service_name": "Netflix", "price": 599, "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"},
+			{"service_name": "Spotify", "price": -1, "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"}
+		]`),
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+
+	report, err := suite.service.ImportSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, report.Created)
+	assert.Equal(suite.T(), 1, report.Failed)
+	assert.Equal(suite.T(), ImportRowValid, report.Rows[0].Status)
+	assert.Equal(suite.T(), ImportRowFailed, report.Rows[1].Status)
+	assert.Equal(suite.T(), CodeValidationFailed, report.Rows[1].Code)
+	assert.Equal(suite.T(), "price", report.Rows[1].Fields[0].Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateSubscriptions", mock.Anything, mock.Anything)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_BestEffort() {
+	ctx := context.Background()
+	req := &ImportSubscriptionsRequest{
+		Format: ImportFormatJSON,
+		Mode:   ImportModeBestEffort,
+		Data: []byte(`[
+			{"service_name": "Netflix", "price": 599, "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"},
+			{"service_name": "Spotify", "price": "free", "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"}
+		]`),
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+	suite.mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.Subscription")).Return(nil).Once()
+
+	report, err := suite.service.ImportSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, report.Created)
+	assert.Equal(suite.T(), 1, report.Failed)
+	assert.Equal(suite.T(), ImportRowResult{Row: 1, Status: ImportRowCreated, ID: 1}, report.Rows[0])
+	assert.Equal(suite.T(), ImportRowFailed, report.Rows[1].Status)
+	assert.Equal(suite.T(), "price", report.Rows[1].Fields[0].Field)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_DryRun() {
+	ctx := context.Background()
+	req := &ImportSubscriptionsRequest{
+		Format: ImportFormatJSON,
+		DryRun: true,
+		Data:   []byte(`[{"service_name": "Netflix", "price": 599, "user_id": "550e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"}]`),
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+
+	report, err := suite.service.ImportSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), report.DryRun)
+	assert.Equal(suite.T(), 0, report.Created)
+	assert.Equal(suite.T(), []ImportRowResult{{Row: 1, Status: ImportRowValid}}, report.Rows)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateSubscriptions", mock.Anything, mock.Anything)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_CSVWithMapping() {
+	ctx := context.Background()
+	req := &ImportSubscriptionsRequest{
+		Format:  ImportFormatCSV,
+		DryRun:  true,
+		Mapping: map[string]string{"Service": "service_name", "Cost": "price"},
+		Data: []byte("\ufeffService,Cost,user_id,start_date\n" +
+			"Netflix,599,550e8400-e29b-41d4-a716-446655440000,01-2025\n" +
+			"Spotify,free,550e8400-e29b-41d4-a716-446655440000,01-2025\n"),
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+
+	report, err := suite.service.ImportSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, report.Total)
+	assert.Equal(suite.T(), ImportRowValid, report.Rows[0].Status)
+	assert.Equal(suite.T(), ImportRowFailed, report.Rows[1].Status)
+	assert.Equal(suite.T(), "price", report.Rows[1].Fields[0].Field)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_ForeignUser() {
+	ctx := context.Background()
+	req := &ImportSubscriptionsRequest{
+		Format: ImportFormatJSON,
+		UserID: "550e8400-e29b-41d4-a716-446655440000",
+		Data:   []byte(`[{"service_name": "Netflix", "price": 599, "user_id": "660e8400-e29b-41d4-a716-446655440000", "start_date": "01-2025"}]`),
+	}
+
+	suite.mockCatalog.On("ResolveService", ctx, mock.Anything).Return(nil, repository.ErrServiceNotFound)
+
+	report, err := suite.service.ImportSubscriptions(ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ImportRowFailed, report.Rows[0].Status)
+	assert.Equal(suite.T(), CodeForbidden, report.Rows[0].Code)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateSubscriptions", mock.Anything, mock.Anything)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_UnknownColumn() {
+	req := &ImportSubscriptionsRequest{
+		Format: ImportFormatCSV,
+		Data:   []byte("name,price\nNetflix,599\n"),
+	}
+
+	report, err := suite.service.ImportSubscriptions(context.Background(), req)
+
+	assert.Nil(suite.T(), report)
+	assert.ErrorIs(suite.T(), err, ErrInvalidImport)
+}
+
+func (suite *SubscriptionServiceTestSuite) TestImportSubscriptions_TooLarge() {
+	data := "[" + strings.Repeat(`{},`, MaxImportRows) + "{}]"
+	req := &ImportSubscriptionsRequest{Format: ImportFormatJSON, Data: []byte(data)}
+
+	report, err := suite.service.ImportSubscriptions(context.Background(), req)
+
+	assert.Nil(suite.T(), report)
+	assert.ErrorIs(suite.T(), err, ErrImportTooLarge)
+}
+
 func TestSubscriptionServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(SubscriptionServiceTestSuite))
 }
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/repository"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type subscriptionService struct {
+	next service.SubscriptionService
+}
+
+// InstrumentSubscriptionService wraps the service to run each call in its own span
+func InstrumentSubscriptionService(next service.SubscriptionService) service.SubscriptionService {
+	return &subscriptionService{
+		next: next,
+	}
+}
+
+func (s *subscriptionService) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return Start(ctx, "SubscriptionService."+method)
+}
+
+func (s *subscriptionService) end(span trace.Span, err *error) {
+	End(span, *err)
+}
+
+func (s *subscriptionService) CreateSubscription(ctx context.Context, req *service.CreateSubscriptionRequest) (_ *repository.Subscription, err error) {
+	ctx, span := s.start(ctx, "CreateSubscription")
+	defer s.end(span, &err)
+	return s.next.CreateSubscription(ctx, req)
+}
+
+func (s *subscriptionService) ImportSubscriptions(ctx context.Context, req *service.ImportSubscriptionsRequest) (_ *service.ImportReport, err error) {
+	ctx, span := s.start(ctx, "ImportSubscriptions")
+	defer s.end(span, &err)
+	return s.next.ImportSubscriptions(ctx, req)
+}
+
+func (s *subscriptionService) GetSubscription(ctx context.Context, userID string, subscriptionID int) (_ *repository.Subscription, err error) {
+	ctx, span := s.start(ctx, "GetSubscription")
+	defer s.end(span, &err)
+	return s.next.GetSubscription(ctx, userID, subscriptionID)
+}
+
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID string, subscriptionID int, req *service.UpdateSubscriptionRequest, expectedVersion int) (_ *repository.Subscription, err error) {
+	ctx, span := s.start(ctx, "UpdateSubscription")
+	defer s.end(span, &err)
+	return s.next.UpdateSubscription(ctx, userID, subscriptionID, req, expectedVersion)
+}
+
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) (err error) {
+	ctx, span := s.start(ctx, "DeleteSubscription")
+	defer s.end(span, &err)
+	return s.next.DeleteSubscription(ctx, userID, subscriptionID, expectedVersion)
+}
+
+func (s *subscriptionService) PatchSubscription(ctx context.Context, userID string, subscriptionID int, req *service.PatchSubscriptionRequest, expectedVersion int) (_ *repository.Subscription, err error) {
+	ctx, span := s.start(ctx, "PatchSubscription")
+	defer s.end(span, &err)
+	return s.next.PatchSubscription(ctx, userID, subscriptionID, req, expectedVersion)
+}
+
+func (s *subscriptionService) GetUserSubscriptions(ctx context.Context, userID string) (_ []*repository.Subscription, err error) {
+	ctx, span := s.start(ctx, "GetUserSubscriptions")
+	defer s.end(span, &err)
+	return s.next.GetUserSubscriptions(ctx, userID)
+}
+
+func (s *subscriptionService) ListUserSubscriptions(ctx context.Context, req *service.ListSubscriptionsRequest) (_ *service.SubscriptionsPage, err error) {
+	ctx, span := s.start(ctx, "ListUserSubscriptions")
+	defer s.end(span, &err)
+	return s.next.ListUserSubscriptions(ctx, req)
+}
+
+func (s *subscriptionService) GetEndingTrials(ctx context.Context, userID string, within string) (_ []*repository.Subscription, err error) {
+	ctx, span := s.start(ctx, "GetEndingTrials")
+	defer s.end(span, &err)
+	return s.next.GetEndingTrials(ctx, userID, within)
+}
+
+func (s *subscriptionService) AddSubscriptionPrice(ctx context.Context, userID string, subscriptionID int, req *service.AddSubscriptionPriceRequest) (_ []*repository.SubscriptionPrice, _ int, err error) {
+	ctx, span := s.start(ctx, "AddSubscriptionPrice")
+	defer s.end(span, &err)
+	return s.next.AddSubscriptionPrice(ctx, userID, subscriptionID, req)
+}
+
+func (s *subscriptionService) GetSubscriptionPrices(ctx context.Context, userID string, subscriptionID int) (_ []*repository.SubscriptionPrice, err error) {
+	ctx, span := s.start(ctx, "GetSubscriptionPrices")
+	defer s.end(span, &err)
+	return s.next.GetSubscriptionPrices(ctx, userID, subscriptionID)
+}
+
+func (s *subscriptionService) CalculateTotalCost(ctx context.Context, req *service.GetCostRequest) (_ *service.CostResponse, err error) {
+	ctx, span := s.start(ctx, "CalculateTotalCost")
+	defer s.end(span, &err)
+	return s.next.CalculateTotalCost(ctx, req)
+}
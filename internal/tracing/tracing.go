@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/buildinfo"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this application
+const instrumentationName = "github.com/AtoyanMikhail/SubscribtionAggregation"
+
+// AttributeRequestID records the X-Request-ID of the request on its server span
+const AttributeRequestID = attribute.Key("http.request.id")
+
+// Exporters of the tracing configuration
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup installs the global tracer provider and the W3C trace context propagator. The returned
+// function flushes the pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		// Spans aren't recorded, but the trace context of incoming requests is still propagated
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := NewProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider describing this service. Tests pass
+// sdktrace.WithSyncer with an in-memory exporter.
+func NewProvider(cfg config.TracingConfig, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(buildinfo.Version),
+	)
+
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, options...)
+
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// Start starts a span with the global tracer provider, so that spans follow the provider
+// installed by Setup or by a test
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, options...)
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/config"
+	"github.com/AtoyanMikhail/SubscribtionAggregation/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeSubscriptionService implements only the methods used by the tests
+type fakeSubscriptionService struct {
+	service.SubscriptionService
+	err error
+}
+
+func (s *fakeSubscriptionService) DeleteSubscription(ctx context.Context, userID string, subscriptionID int, expectedVersion int) error {
+	// A span started here is a child of the service span
+	_, span := Start(ctx, "subscriptions.delete")
+	span.End()
+	return s.err
+}
+
+func setupExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(config.TracingConfig{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return exporter
+}
+
+func TestInstrumentSubscriptionService_NestsSpans(t *testing.T) {
+	exporter := setupExporter(t)
+	svc := InstrumentSubscriptionService(&fakeSubscriptionService{})
+
+	ctx, parent := Start(context.Background(), "GET /api/v1/subscriptions/:user_id/:subscription_id")
+	require.NoError(t, svc.DeleteSubscription(ctx, "user", 1, 0))
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	repositorySpan, serviceSpan, requestSpan := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "SubscriptionService.DeleteSubscription", serviceSpan.Name)
+	assert.Equal(t, requestSpan.SpanContext.SpanID(), serviceSpan.Parent.SpanID())
+	assert.Equal(t, serviceSpan.SpanContext.SpanID(), repositorySpan.Parent.SpanID())
+	assert.Equal(t, codes.Unset, serviceSpan.Status.Code)
+}
+
+func TestInstrumentSubscriptionService_RecordsError(t *testing.T) {
+	exporter := setupExporter(t)
+	svc := InstrumentSubscriptionService(&fakeSubscriptionService{err: errors.New("subscription not found")})
+
+	require.Error(t, svc.DeleteSubscription(context.Background(), "user", 1, 0))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "subscription not found", spans[1].Status.Description)
+	require.Len(t, spans[1].Events, 1)
+	assert.Equal(t, "exception", spans[1].Events[0].Name)
+}
+
+func TestSetup_None(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: ExporterNone})
+
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	Field   string // JSON name of the field, with indexes for list items, e.g. aliases[2]
+	Code    string // Failed rule, e.g. required, min or uuid4
+	Message string
+	Param   string // Parameter of the rule, e.g. 0 for min=0
+}
+
+// Error is a validation failure of one or more fields
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if f.Field == "" {
+			parts = append(parts, f.Message)
+			continue
+		}
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// New creates a validator that reports fields by their JSON names, or query parameter names for
+// fields without a JSON name
+func New() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return v
+}
+
+// FromError converts the errors of validator.Struct and of request binding into an *Error.
+// Errors of other kinds are returned unchanged.
+func FromError(err error) error {
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, fromFieldError(fe))
+		}
+		return &Error{Fields: fields}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &Error{Fields: []FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be " + describeType(typeErr.Type),
+			Param:   typeErr.Type.Kind().String(),
+		}}}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &Error{Fields: []FieldError{{
+			Code:    "malformed",
+			Message: "request body must be a valid JSON object",
+		}}}
+	}
+
+	return err
+}
+
+// Fields returns the field errors of err, or nil if it isn't a validation failure
+func Fields(err error) []FieldError {
+	var verr *Error
+	if errors.As(FromError(err), &verr) {
+		return verr.Fields
+	}
+	return nil
+}
+
+func fromFieldError(fe validator.FieldError) FieldError {
+	// The namespace starts with the name of the validated struct
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	// Parameters naming other fields report them by their JSON names as well
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required_with", "required_without", "nefield":
+		words := strings.Fields(param)
+		for i := range words {
+			words[i] = snakeCase(words[i])
+		}
+		param = strings.Join(words, " ")
+	case "required_if":
+		// Pairs of a field and its value
+		words := strings.Fields(param)
+		for i := 0; i < len(words); i += 2 {
+			words[i] = snakeCase(words[i])
+		}
+		param = strings.Join(words, " ")
+	}
+
+	return FieldError{
+		Field:   field,
+		Code:    fe.Tag(),
+		Message: message(fe, param),
+		Param:   param,
+	}
+}
+
+func message(fe validator.FieldError, param string) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not set", param)
+	case "required_with":
+		return fmt.Sprintf("is required when %s is set", param)
+	case "required_if":
+		if field, value, ok := strings.Cut(param, " "); ok {
+			return fmt.Sprintf("is required when %s is %s", field, value)
+		}
+		return "is required"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s%s", param, sizeUnit(fe.Kind()))
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s", param, sizeUnit(fe.Kind()))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s%s", param, sizeUnit(fe.Kind()))
+	case "lt":
+		return fmt.Sprintf("must be less than %s%s", param, sizeUnit(fe.Kind()))
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", param, sizeUnit(fe.Kind()))
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "nefield":
+		return "must differ from " + param
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "email":
+		return "must be a valid email address"
+	default:
+		return fmt.Sprintf("failed the %s check", fe.Tag())
+	}
+}
+
+// sizeUnit returns the unit of the size limits of a kind of value
+func sizeUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// snakeCase converts a Go field name such as ServiceID to its JSON name service_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at a lower-to-upper change and at the last capital of an acronym
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
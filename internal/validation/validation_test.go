@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	ServiceName  string   `json:"service_name" validate:"required_without=ServiceID"`
+	ServiceID    int      `json:"service_id,omitempty" validate:"omitempty,min=1"`
+	UserID       string   `json:"user_id" validate:"required,uuid4"`
+	BillingCycle string   `json:"billing_cycle,omitempty" validate:"omitempty,oneof=monthly custom"`
+	Interval     int      `json:"billing_interval_months,omitempty" validate:"required_if=BillingCycle custom"`
+	Aliases      []string `json:"aliases,omitempty" validate:"omitempty,dive,max=3"`
+	Limit        int      `form:"limit" validate:"omitempty,max=100"`
+}
+
+func TestFromError_ValidatorErrors(t *testing.T) {
+	err := New().Struct(&testRequest{
+		UserID:       "not-a-uuid",
+		BillingCycle: "custom",
+		Aliases:      []string{"ok", "too long"},
+		Limit:        500,
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, []FieldError{
+		{Field: "service_name", Code: "required_without", Message: "is required when service_id is not set", Param: "service_id"},
+		{Field: "user_id", Code: "uuid4", Message: "must be a valid UUID"},
+		{Field: "billing_interval_months", Code: "required_if", Message: "is required when billing_cycle is custom", Param: "billing_cycle custom"},
+		{Field: "aliases[1]", Code: "max", Message: "must be at most 3 characters long", Param: "3"},
+		{Field: "limit", Code: "max", Message: "must be at most 100", Param: "100"},
+	}, Fields(err))
+}
+
+func TestFromError_FieldComparison(t *testing.T) {
+	type pairRequest struct {
+		BaseCurrency  string `json:"base_currency"`
+		QuoteCurrency string `json:"quote_currency" validate:"nefield=BaseCurrency"`
+	}
+
+	err := New().Struct(&pairRequest{BaseCurrency: "USD", QuoteCurrency: "USD"})
+	require.Error(t, err)
+
+	assert.Equal(t, []FieldError{
+		{Field: "quote_currency", Code: "nefield", Message: "must differ from base_currency", Param: "base_currency"},
+	}, Fields(err))
+}
+
+func TestFromError_BindingErrors(t *testing.T) {
+	var req testRequest
+	err := json.Unmarshal([]byte(`{"service_id": "one"}`), &req)
+	assert.Equal(t, []FieldError{
+		{Field: "service_id", Code: "type", Message: "must be an integer", Param: "int"},
+	}, Fields(err))
+
+	err = json.Unmarshal([]byte(`{"service_id": `), &req)
+	assert.Equal(t, "malformed", Fields(err)[0].Code)
+}
+
+func TestFromError_OtherErrors(t *testing.T) {
+	err := errors.New("boom")
+	assert.Equal(t, err, FromError(err))
+	assert.Nil(t, Fields(err))
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "service_id", snakeCase("ServiceID"))
+	assert.Equal(t, "billing_interval_months", snakeCase("BillingIntervalMonths"))
+	assert.Equal(t, "url_path", snakeCase("URLPath"))
+}
@@ -0,0 +1,9 @@
+ALTER TABLE subscriptions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS chk_subscriptions_custom_interval,
+    DROP COLUMN IF EXISTS billing_interval_months,
+    DROP COLUMN IF EXISTS billing_cycle;
@@ -0,0 +1,9 @@
+ALTER TABLE subscriptions
+    ADD COLUMN billing_cycle TEXT NOT NULL DEFAULT 'monthly'
+        CHECK (billing_cycle IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    ADD COLUMN billing_interval_months INTEGER
+        CHECK (billing_interval_months > 0);
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_subscriptions_custom_interval
+        CHECK (billing_cycle <> 'custom' OR billing_interval_months IS NOT NULL);
@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS current_subscriptions;
+DROP TABLE IF EXISTS subscription_prices;
@@ -0,0 +1,36 @@
+CREATE TABLE subscription_prices (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (subscription_id, effective_from)
+);
+
+CREATE INDEX idx_subscription_prices_subscription_id ON subscription_prices(subscription_id);
+
+-- Existing subscriptions start with a single price in force since their start date
+INSERT INTO subscription_prices (subscription_id, price, effective_from)
+SELECT id, price, start_date FROM subscriptions;
+
+-- Subscriptions with the price of their schedule in force today, which is read instead of the
+-- price column, so that a scheduled price becomes current on its effective date without a write.
+-- The price column applies until the first entry of the schedule takes effect.
+CREATE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date
+FROM subscriptions s;
@@ -0,0 +1,26 @@
+DROP VIEW IF EXISTS current_subscriptions;
+
+DROP INDEX IF EXISTS idx_subscriptions_trial_end_date;
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS chk_subscriptions_trial_end_date,
+    DROP COLUMN IF EXISTS trial_end_date;
+
+CREATE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date
+FROM subscriptions s;
@@ -0,0 +1,29 @@
+ALTER TABLE subscriptions
+    ADD COLUMN trial_end_date DATE;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_subscriptions_trial_end_date
+        CHECK (trial_end_date IS NULL OR trial_end_date >= start_date);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_trial_end_date ON subscriptions(trial_end_date)
+    WHERE trial_end_date IS NOT NULL;
+
+CREATE OR REPLACE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date,
+    s.trial_end_date
+FROM subscriptions s;
@@ -0,0 +1,29 @@
+DROP VIEW IF EXISTS current_subscriptions;
+
+DROP INDEX IF EXISTS idx_subscriptions_service_id;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS service_id;
+
+CREATE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date,
+    s.trial_end_date
+FROM subscriptions s;
+
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
@@ -0,0 +1,46 @@
+CREATE TABLE services (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    name_key TEXT NOT NULL UNIQUE,
+    category TEXT NOT NULL DEFAULT '',
+    default_price INTEGER CHECK (default_price >= 0),
+    default_currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_services_category ON services(category);
+
+CREATE TABLE service_aliases (
+    service_id INTEGER NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    alias TEXT NOT NULL,
+    alias_key TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_service_aliases_service_id ON service_aliases(service_id);
+
+ALTER TABLE subscriptions
+    ADD COLUMN service_id INTEGER REFERENCES services(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
+
+CREATE OR REPLACE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date,
+    s.trial_end_date,
+    s.service_id
+FROM subscriptions s;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_subscriptions_user_end_date;
+DROP INDEX IF EXISTS idx_subscriptions_user_start_date;
@@ -0,0 +1,4 @@
+-- Keyset pagination of a user's subscriptions by the supported sort keys. The current price comes
+-- from the price schedule, so sorting by price can't use an index.
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_start_date ON subscriptions(user_id, start_date, id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_end_date ON subscriptions(user_id, (COALESCE(end_date, 'infinity'::date)), id);
@@ -0,0 +1,17 @@
+-- Audit trail of subscription mutations. Entries outlive the subscriptions they describe.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL,
+    user_id         TEXT NOT NULL,
+    actor           TEXT NOT NULL,
+    request_id      TEXT NOT NULL DEFAULT '',
+    operation       TEXT NOT NULL
+        CHECK (operation IN ('create', 'update', 'delete', 'price_change')),
+    old_data        JSONB,
+    new_data        JSONB,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_subscription ON audit_log(user_id, subscription_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
@@ -0,0 +1,18 @@
+-- Responses of requests made with an Idempotency-Key, replayed to retries until they expire.
+-- A row without a status code is a request still being processed. Every reservation of a key gets
+-- a new token, so that a request whose lease was taken over by a retry can't change the row.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope           TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    fingerprint     TEXT NOT NULL,
+    status_code     INTEGER NOT NULL DEFAULT 0,
+    content_type    TEXT NOT NULL DEFAULT '',
+    etag            TEXT NOT NULL DEFAULT '',
+    response_body   BYTEA,
+    reservation     UUID NOT NULL DEFAULT gen_random_uuid(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,25 @@
+DROP VIEW IF EXISTS current_subscriptions;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS version;
+
+CREATE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date,
+    s.trial_end_date,
+    s.service_id
+FROM subscriptions s;
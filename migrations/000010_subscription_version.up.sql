@@ -0,0 +1,31 @@
+-- Version of a subscription for optimistic concurrency, incremented by every update
+ALTER TABLE subscriptions
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- A scheduled price that takes effect after it was added changes the subscription without a write,
+-- so every such entry in force counts as a version too
+CREATE OR REPLACE VIEW current_subscriptions AS
+SELECT
+    s.id,
+    s.service_name,
+    COALESCE((
+        SELECT p.price
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from <= CURRENT_DATE
+        ORDER BY p.effective_from DESC
+        LIMIT 1
+    ), s.price) AS price,
+    s.currency,
+    s.billing_cycle,
+    s.billing_interval_months,
+    s.user_id,
+    s.start_date,
+    s.end_date,
+    s.trial_end_date,
+    s.service_id,
+    s.version + (
+        SELECT COUNT(*)
+        FROM subscription_prices p
+        WHERE p.subscription_id = s.id AND p.effective_from > p.created_at::date AND p.effective_from <= CURRENT_DATE
+    )::integer AS version
+FROM subscriptions s;